@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/config"
+	"joinly-manager/internal/storage"
+)
+
+// runKeys implements the keys subcommand: "keys rotate" replaces the key-encryption key and
+// re-wraps the data keys of every encrypted file. It returns the exit code.
+func runKeys(args []string) int {
+	flags := flag.NewFlagSet("keys", flag.ContinueOnError)
+	rewrap := flags.Bool("rewrap", true, "Re-wrap the data keys of existing files with the new key")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: server keys rotate [-rewrap=false]")
+		fmt.Fprintln(flags.Output(), "Stop the server first; files it writes during a rotation may be overwritten.")
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "rotate" {
+		flags.Usage()
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logrus.Errorf("Failed to load configuration: %v", err)
+		return 1
+	}
+
+	keys, err := storage.NewKeyProvider(cfg.Storage.Encryption, storage.ProviderSettings{KeyFile: cfg.Storage.KeyFile, KeyURI: cfg.Storage.KeyURI})
+	if err != nil {
+		logrus.Errorf("Failed to open encryption keys: %v", err)
+		return 1
+	}
+	if keys == nil {
+		logrus.Error("Encryption at rest is disabled; set ENCRYPTION_PROVIDER to rotate keys")
+		return 1
+	}
+	rotator, ok := keys.(storage.KeyRotator)
+	if !ok {
+		logrus.Errorf("The %q key provider does not support key rotation", cfg.Storage.Encryption)
+		return 1
+	}
+
+	keyID, err := rotator.RotateKey()
+	if err != nil {
+		logrus.Errorf("Failed to rotate encryption key: %v", err)
+		return 1
+	}
+	fmt.Printf("New encryption key: %s\n", keyID)
+
+	if !*rewrap {
+		return 0
+	}
+	vault := storage.NewVault(keys)
+	for _, dir := range dataDirs(cfg) {
+		count, err := vault.RewrapDir(dir)
+		if err != nil {
+			logrus.Errorf("Failed to re-wrap files in %s: %v", dir, err)
+			return 1
+		}
+		fmt.Printf("Re-wrapped %d files in %s\n", count, dir)
+	}
+	return 0
+}
+
+// dataDirs returns the directories the manager persists data in: the data directory and the
+// configured directories outside it
+func dataDirs(cfg *config.Config) []string {
+	root := filepath.Clean(cfg.Storage.DataDir)
+	dirs := []string{root}
+	for _, dir := range []string{cfg.Memory.Dir, cfg.Knowledge.Dir, cfg.Archive.Dir, cfg.Audit.Dir} {
+		dir = filepath.Clean(dir)
+		if rel, err := filepath.Rel(root, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
@@ -12,11 +12,21 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"joinly-manager/internal/api"
+	"joinly-manager/internal/client/llm"
 	"joinly-manager/internal/config"
 	"joinly-manager/internal/manager"
+	"joinly-manager/internal/tracing"
 )
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		os.Exit(runKeys(os.Args[2:]))
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -30,6 +40,15 @@ func main() {
 
 	logrus.Info("Starting Joinly Manager Backend v2")
 
+	// Setup tracing
+	shutdownTracing, err := tracing.Setup(cfg.Tracing)
+	if err != nil {
+		logrus.Fatalf("Failed to setup tracing: %v", err)
+	}
+
+	// Configure LLM endpoints, retries and circuit breakers
+	configureLLM(cfg)
+
 	// Create agent manager
 	agentManager := manager.NewAgentManager(cfg)
 
@@ -77,5 +96,39 @@ func main() {
 		logrus.Errorf("Server forced to shutdown: %v", err)
 	}
 
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.Errorf("Failed to flush traces: %v", err)
+	}
+
 	logrus.Info("Server exited")
 }
+
+// configureLLM applies the LLM endpoints, retries, circuit breakers and recording of the configuration
+func configureLLM(cfg *config.Config) {
+	providers := make(map[string]llm.ProviderSettings, len(cfg.LLM.Providers))
+	for name, provider := range cfg.LLM.Providers {
+		providers[name] = llm.ProviderSettings{BaseURL: provider.BaseURL, Timeout: provider.Timeout}
+	}
+	recording, err := llm.ParseRecordingMode(cfg.LLM.Record)
+	if err != nil {
+		logrus.Fatalf("Failed to configure LLM recording: %v", err)
+	}
+	switch recording {
+	case llm.RecordingRecord:
+		logrus.Infof("Recording LLM answers to %s", cfg.LLM.Cassette)
+	case llm.RecordingReplay:
+		logrus.Infof("Replaying LLM answers from %s", cfg.LLM.Cassette)
+	}
+	llm.Configure(llm.Options{
+		MaxAttempts:      cfg.LLM.MaxAttempts,
+		BaseDelay:        cfg.LLM.RetryBaseDelay,
+		MaxDelay:         cfg.LLM.RetryMaxDelay,
+		BreakerThreshold: cfg.LLM.BreakerThreshold,
+		BreakerCooldown:  cfg.LLM.BreakerCooldown,
+		Providers:        providers,
+		DebugCapture:     cfg.LLM.DebugCapture,
+		Recording:        recording,
+		Cassette:         cfg.LLM.Cassette,
+	})
+}
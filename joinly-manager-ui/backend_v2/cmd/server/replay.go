@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/config"
+	"joinly-manager/internal/manager"
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/replay"
+	"joinly-manager/internal/validation"
+)
+
+// runReplay implements the replay subcommand: it plays a recorded transcript into an agent
+// without a joinly server and prints the agent's replies and analysis. It returns the exit code.
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	agentFile := flags.String("agent", "", "JSON file with the agent configuration (required)")
+	transcriptFile := flags.String("transcript", "", "Recorded transcript: analysis JSON, VTT, SRT or JSONL segments (required)")
+	format := flags.String("format", "", "Transcript format: analysis, vtt, srt or jsonl (default: inferred from the file)")
+	speed := flags.Float64("speed", 1, "Replay speed, 1 is real time")
+	out := flags.String("out", "", "Write the analysis of analyst agents to this JSON file")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: server replay -agent agent.json -transcript meeting.vtt [-speed 10] [-format vtt] [-out analysis.json]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *agentFile == "" || *transcriptFile == "" {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logrus.Errorf("Failed to load configuration: %v", err)
+		return 1
+	}
+	if err := config.SetupLogging(&cfg.Logging); err != nil {
+		logrus.Errorf("Failed to setup logging: %v", err)
+		return 1
+	}
+	configureLLM(cfg)
+
+	agentConfig, err := loadReplayAgent(*agentFile)
+	if err != nil {
+		logrus.Error(err)
+		return 1
+	}
+
+	data, err := os.ReadFile(*transcriptFile)
+	if err != nil {
+		logrus.Errorf("Failed to read transcript: %v", err)
+		return 1
+	}
+	source := filepath.Base(*transcriptFile)
+	segments, err := replay.Parse(source, *format, data)
+	if err != nil {
+		logrus.Errorf("Failed to parse transcript: %v", err)
+		return 1
+	}
+
+	agentManager := manager.NewAgentManager(cfg)
+	if err := agentManager.Start(); err != nil {
+		logrus.Errorf("Failed to start agent manager: %v", err)
+		return 1
+	}
+	defer agentManager.Stop()
+
+	agent, err := agentManager.CreateAgent(agentConfig)
+	if err != nil {
+		logrus.Errorf("Failed to create agent: %v", err)
+		return 1
+	}
+	if _, err := agentManager.StartReplay(agent.ID, source, segments, *speed); err != nil {
+		logrus.Errorf("Failed to start replay: %v", err)
+		return 1
+	}
+
+	// Ctrl-C stops the agent, which ends the replay with what was processed so far
+	interrupted, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-interrupted.Done()
+		agentManager.StopAgent(agent.ID)
+	}()
+
+	status, err := agentManager.WaitReplay(context.Background(), agent.ID)
+	if err != nil {
+		logrus.Errorf("Failed to wait for replay: %v", err)
+		return 1
+	}
+
+	fmt.Printf("Replay %s: %d of %d segments replayed, %d agent segments skipped\n", status.State, status.Replayed, status.Segments, status.Skipped)
+	if status.Error != "" {
+		fmt.Printf("Error: %s\n", status.Error)
+	}
+	if len(status.Replies) > 0 {
+		fmt.Println("\nReplies:")
+		for i, reply := range status.Replies {
+			fmt.Printf("%d. %s\n", i+1, reply)
+		}
+	}
+
+	if analyst := agentManager.GetAnalystAgent(agent.ID); analyst != nil {
+		fmt.Println()
+		fmt.Println(analyst.GetFormattedAnalysis())
+
+		if *out != "" {
+			data, err := json.MarshalIndent(analyst.GetAnalysis(), "", "  ")
+			if err == nil {
+				err = os.WriteFile(*out, data, 0644)
+			}
+			if err != nil {
+				logrus.Errorf("Failed to write analysis: %v", err)
+				return 1
+			}
+			fmt.Printf("Analysis written to %s\n", *out)
+		}
+	}
+
+	if status.State == models.ReplayStateFailed {
+		return 1
+	}
+	return 0
+}
+
+// loadReplayAgent reads and validates the configuration of the agent to replay into
+func loadReplayAgent(path string) (models.AgentConfig, error) {
+	var agentConfig models.AgentConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return agentConfig, fmt.Errorf("failed to read agent configuration: %w", err)
+	}
+	if err := json.Unmarshal(data, &agentConfig); err != nil {
+		return agentConfig, fmt.Errorf("failed to parse agent configuration: %w", err)
+	}
+
+	if agentConfig.ConversationMode == "" {
+		agentConfig.ConversationMode = models.ConversationModeConversational
+	}
+	if fieldErrors := validation.ValidateAgentConfig(agentConfig); len(fieldErrors) > 0 {
+		return agentConfig, fmt.Errorf("invalid agent configuration: %s: %s", fieldErrors[0].Field, fieldErrors[0].Message)
+	}
+	return agentConfig, nil
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -8,8 +11,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"joinly-manager/internal/archive"
+	"joinly-manager/internal/audit"
+	"joinly-manager/internal/client/llm"
 	"joinly-manager/internal/manager"
 	"joinly-manager/internal/models"
+	"joinly-manager/internal/replay"
+	"joinly-manager/internal/search"
+	"joinly-manager/internal/transcript"
+	"joinly-manager/internal/validation"
 )
 
 // Handler holds the dependencies for HTTP handlers
@@ -46,13 +56,14 @@ func (h *Handler) CreateAgent(c *gin.Context) {
 		return
 	}
 
-	// Set default values if not provided
-	val := 1.0
-	config.UtteranceTailSeconds = &val
+	applyAgentDefaults(&config)
 
-	// Set default conversation mode if not provided
-	if config.ConversationMode == "" {
-		config.ConversationMode = models.ConversationModeConversational
+	if fieldErrors := validation.ValidateAgentConfig(config); len(fieldErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "invalid agent configuration",
+			"errors": fieldErrors,
+		})
+		return
 	}
 
 	agent, err := h.agentManager.CreateAgent(config)
@@ -77,6 +88,41 @@ func (h *Handler) CreateAgent(c *gin.Context) {
 	}
 }
 
+// ValidateAgent handles POST /agents/validate (dry run of agent creation)
+func (h *Handler) ValidateAgent(c *gin.Context) {
+	var config models.AgentConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyAgentDefaults(&config)
+
+	fieldErrors := validation.ValidateAgentConfig(config)
+	if fieldErrors == nil {
+		fieldErrors = []models.FieldError{}
+	}
+
+	c.JSON(http.StatusOK, models.ValidationResult{
+		Valid:  len(fieldErrors) == 0,
+		Errors: fieldErrors,
+	})
+}
+
+// applyAgentDefaults fills in server-side defaults before validation, on creation and update
+func applyAgentDefaults(config *models.AgentConfig) {
+	// Set default values if not provided
+	if config.UtteranceTailSeconds == nil {
+		val := 1.0
+		config.UtteranceTailSeconds = &val
+	}
+
+	// Set default conversation mode if not provided
+	if config.ConversationMode == "" {
+		config.ConversationMode = models.ConversationModeConversational
+	}
+}
+
 // GetAgent handles GET /agents/{agent_id}
 func (h *Handler) GetAgent(c *gin.Context) {
 	agentID := c.Param("agent_id")
@@ -90,6 +136,51 @@ func (h *Handler) GetAgent(c *gin.Context) {
 	c.JSON(http.StatusOK, agent)
 }
 
+// UpdateAgent handles PATCH /agents/{agent_id}
+func (h *Handler) UpdateAgent(c *gin.Context) {
+	agentID := c.Param("agent_id")
+
+	var patch map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agent, exists := h.agentManager.GetAgent(agentID)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		return
+	}
+
+	config, err := models.MergeAgentConfig(agent.Config, patch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyAgentDefaults(&config)
+
+	if fieldErrors := validation.ValidateAgentConfig(config); len(fieldErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "invalid agent configuration",
+			"errors": fieldErrors,
+		})
+		return
+	}
+
+	update, err := h.agentManager.UpdateAgentConfig(agentID, config)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "agent not found" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, update)
+}
+
 // DeleteAgent handles DELETE /agents/{agent_id}
 func (h *Handler) DeleteAgent(c *gin.Context) {
 	agentID := c.Param("agent_id")
@@ -110,6 +201,8 @@ func (h *Handler) StartAgent(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "agent not found" {
 			statusCode = http.StatusNotFound
+		} else if err.Error() == "no joinly backend available" {
+			statusCode = http.StatusServiceUnavailable
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
@@ -174,6 +267,26 @@ func (h *Handler) GetAgentLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"logs": logs})
 }
 
+// GetAgentLatency handles GET /agents/{agent_id}/latency
+func (h *Handler) GetAgentLatency(c *gin.Context) {
+	agentID := c.Param("agent_id")
+
+	recent := 20 // default
+	if recentStr := c.Query("recent"); recentStr != "" {
+		if parsedRecent, err := strconv.Atoi(recentStr); err == nil && parsedRecent >= 0 {
+			recent = parsedRecent
+		}
+	}
+
+	report, err := h.agentManager.GetAgentLatency(agentID, recent)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // WebSocketAgent handles WebSocket connections for agents
 func (h *Handler) WebSocketAgent(c *gin.Context) {
 	agentID := c.Param("agent_id")
@@ -200,6 +313,131 @@ func (h *Handler) ListMeetings(c *gin.Context) {
 	c.JSON(http.StatusOK, meetings)
 }
 
+// ListMeetingHistory handles GET /meetings/history?meeting_url=&agent_id=&participant=&from=&to=&offset=&limit=
+func (h *Handler) ListMeetingHistory(c *gin.Context) {
+	query := archive.Query{
+		MeetingURL:  c.Query("meeting_url"),
+		AgentID:     c.Query("agent_id"),
+		Participant: c.Query("participant"),
+	}
+
+	var err error
+	if query.From, err = parseTimeFilter(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+		return
+	}
+	if query.To, err = parseTimeFilter(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+		return
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset > 0 {
+			query.Offset = parsedOffset
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			query.Limit = parsedLimit
+		}
+	}
+
+	c.JSON(http.StatusOK, h.agentManager.ListMeetingHistory(query))
+}
+
+// GetMeetingRecord handles GET /meetings/history/{meeting_id}
+func (h *Handler) GetMeetingRecord(c *gin.Context) {
+	record, err := h.agentManager.GetMeetingRecord(c.Param("meeting_id"))
+	if err != nil {
+		c.JSON(archiveErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// DeleteMeetingRecord handles DELETE /meetings/history/{meeting_id}
+func (h *Handler) DeleteMeetingRecord(c *gin.Context) {
+	if err := h.agentManager.DeleteMeetingRecord(c.Param("meeting_id")); err != nil {
+		c.JSON(archiveErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Meeting record deleted"})
+}
+
+// archiveErrorStatus maps meeting archive errors to HTTP status codes
+func archiveErrorStatus(err error) int {
+	if err.Error() == "meeting record not found" {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// ListAudit handles GET /audit?action=&principal=&target=&outcome=&from=&to=&offset=&limit=
+func (h *Handler) ListAudit(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.agentManager.QueryAudit(query))
+}
+
+// ExportAudit handles GET /audit/export, streaming the matching entries as JSON lines, oldest
+// first. It takes the filters of ListAudit without offset and limit.
+func (h *Handler) ExportAudit(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit_%s.jsonl"`, time.Now().UTC().Format("20060102T150405Z")))
+	c.Status(http.StatusOK)
+	if _, err := h.agentManager.ExportAudit(query, c.Writer); err != nil {
+		logrus.Errorf("Failed to export audit log: %v", err)
+	}
+}
+
+// parseAuditQuery reads the audit filters of a request
+func parseAuditQuery(c *gin.Context) (audit.Query, error) {
+	query := audit.Query{
+		Action:    c.Query("action"),
+		Principal: c.Query("principal"),
+		Target:    c.Query("target"),
+		Outcome:   c.Query("outcome"),
+	}
+
+	switch query.Outcome {
+	case "", models.AuditSuccess, models.AuditFailure:
+	default:
+		return query, fmt.Errorf("outcome must be %q or %q", models.AuditSuccess, models.AuditFailure)
+	}
+
+	var err error
+	if query.From, err = parseTimeFilter(c.Query("from"), false); err != nil {
+		return query, fmt.Errorf("invalid from: %w", err)
+	}
+	if query.To, err = parseTimeFilter(c.Query("to"), true); err != nil {
+		return query, fmt.Errorf("invalid to: %w", err)
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset > 0 {
+			query.Offset = parsedOffset
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			query.Limit = parsedLimit
+		}
+	}
+	return query, nil
+}
+
 // GetUsageStats handles GET /usage (additional endpoint for usage statistics)
 func (h *Handler) GetUsageStats(c *gin.Context) {
 	stats := h.agentManager.GetUsageStats()
@@ -215,6 +453,14 @@ func (h *Handler) GetWebSocketStats(c *gin.Context) {
 	})
 }
 
+// GetLLMExchanges handles GET /debug/llm, returning the captured (redacted) provider exchanges
+func (h *Handler) GetLLMExchanges(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   llm.DebugCaptureEnabled(),
+		"exchanges": llm.Exchanges(),
+	})
+}
+
 // GetAgentAnalysis handles GET /agents/{agent_id}/analysis
 func (h *Handler) GetAgentAnalysis(c *gin.Context) {
 	agentID := c.Param("agent_id")
@@ -273,3 +519,454 @@ func (h *Handler) GetAgentAnalysisFormatted(c *gin.Context) {
 	c.Header("Content-Type", "text/plain; charset=utf-8")
 	c.String(http.StatusOK, formattedAnalysis)
 }
+
+// GetAgentTranscript handles GET /agents/{agent_id}/transcript?format=srt|vtt|jsonl|md|txt
+func (h *Handler) GetAgentTranscript(c *gin.Context) {
+	agentID := c.Param("agent_id")
+
+	format := c.DefaultQuery("format", transcript.FormatText)
+	contentType := transcript.ContentType(format)
+	if contentType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported transcript format"})
+		return
+	}
+
+	agent, exists := h.agentManager.GetAgent(agentID)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		return
+	}
+	segments, err := h.agentManager.GetAgentTranscript(agentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		return
+	}
+
+	content, err := transcript.Render(format, fmt.Sprintf("%s (%s)", agent.Config.Name, agent.Config.MeetingURL), segments)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s_transcript.%s"`, agentID, format))
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// StartReplay handles POST /agents/{agent_id}/replay. The transcript is either a multipart
+// "file" upload with optional "format" and "speed" fields, or a JSON body.
+func (h *Handler) StartReplay(c *gin.Context) {
+	var request struct {
+		Source     string  `json:"source"`
+		Format     string  `json:"format"`
+		Speed      float64 `json:"speed"`
+		Transcript string  `json:"transcript"`
+	}
+
+	if file, err := c.FormFile("file"); err == nil {
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		content, err := io.ReadAll(opened)
+		opened.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.Format = c.PostForm("format")
+		if speed := c.PostForm("speed"); speed != "" {
+			if request.Speed, err = strconv.ParseFloat(speed, 64); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid replay speed"})
+				return
+			}
+		}
+		request.Source = file.Filename
+		request.Transcript = string(content)
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Speed == 0 {
+		request.Speed = 1 // default: real time
+	}
+
+	segments, err := replay.Parse(request.Source, request.Format, []byte(request.Transcript))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, err := h.agentManager.StartReplay(c.Param("agent_id"), request.Source, segments, request.Speed)
+	if err != nil {
+		c.JSON(replayErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+// GetReplay handles GET /agents/{agent_id}/replay
+func (h *Handler) GetReplay(c *gin.Context) {
+	status, err := h.agentManager.GetReplay(c.Param("agent_id"))
+	if err != nil {
+		c.JSON(replayErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// replayErrorStatus maps replay errors to HTTP status codes
+func replayErrorStatus(err error) int {
+	switch err.Error() {
+	case "agent not found", "no replay found":
+		return http.StatusNotFound
+	case "agent is running", "replay already running":
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// SearchMeetings handles GET /search?q={query}&speaker=&meeting_url=&agent_id=&kind=&from=&to=&limit=
+func (h *Handler) SearchMeetings(c *gin.Context) {
+	query := search.Query{
+		Text:       c.Query("q"),
+		Speaker:    c.Query("speaker"),
+		MeetingURL: c.Query("meeting_url"),
+		AgentID:    c.Query("agent_id"),
+		Kind:       c.Query("kind"),
+	}
+	if query.Text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	switch query.Kind {
+	case "", search.KindTranscript, search.KindSummary, search.KindActionItem:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be transcript, summary or action_item"})
+		return
+	}
+
+	var err error
+	if query.From, err = parseTimeFilter(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+		return
+	}
+	if query.To, err = parseTimeFilter(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+		return
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			query.Limit = parsedLimit
+		}
+	}
+
+	results, total, err := h.agentManager.SearchMeetings(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "query has no searchable terms" {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": query.Text, "total": total, "results": results})
+}
+
+// parseTimeFilter parses an RFC 3339 time or a YYYY-MM-DD date. A date used as the end of a
+// range covers the whole day.
+func parseTimeFilter(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 time or YYYY-MM-DD date")
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// ListBackends handles GET /backends
+func (h *Handler) ListBackends(c *gin.Context) {
+	c.JSON(http.StatusOK, h.agentManager.ListBackends())
+}
+
+// RegisterBackend handles POST /backends
+func (h *Handler) RegisterBackend(c *gin.Context) {
+	var request struct {
+		URL string `json:"url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	backend, err := h.agentManager.RegisterBackend(request.URL)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "invalid backend url" {
+			statusCode = http.StatusBadRequest
+		} else if err.Error() == "backend already registered" {
+			statusCode = http.StatusConflict
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, backend)
+}
+
+// DeregisterBackend handles DELETE /backends/{backend_id}
+func (h *Handler) DeregisterBackend(c *gin.Context) {
+	backendID := c.Param("backend_id")
+
+	if err := h.agentManager.DeregisterBackend(backendID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "backend not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "backend in use" {
+			statusCode = http.StatusConflict
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Backend deregistered successfully"})
+}
+
+// ListMemoryIdentities handles GET /memory
+func (h *Handler) ListMemoryIdentities(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"identities": h.agentManager.ListMemoryIdentities()})
+}
+
+// GetMemory handles GET /memory/{identity}
+func (h *Handler) GetMemory(c *gin.Context) {
+	identity := c.Param("identity")
+
+	items, err := h.agentManager.GetMemory(identity, models.MemoryKind(c.Query("kind")), c.Query("series"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identity": identity, "items": items})
+}
+
+// AddMemoryItem handles POST /memory/{identity}/items
+func (h *Handler) AddMemoryItem(c *gin.Context) {
+	var item models.MemoryItem
+	if err := c.ShouldBindJSON(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stored, err := h.agentManager.AddMemoryItem(c.Param("identity"), item)
+	if err != nil {
+		c.JSON(memoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, stored)
+}
+
+// UpdateMemoryItem handles PATCH /memory/{identity}/items/{item_id}
+func (h *Handler) UpdateMemoryItem(c *gin.Context) {
+	var update models.MemoryItemUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.agentManager.UpdateMemoryItem(c.Param("identity"), c.Param("item_id"), update)
+	if err != nil {
+		c.JSON(memoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteMemoryItem handles DELETE /memory/{identity}/items/{item_id}
+func (h *Handler) DeleteMemoryItem(c *gin.Context) {
+	if err := h.agentManager.DeleteMemoryItem(c.Param("identity"), c.Param("item_id")); err != nil {
+		c.JSON(memoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Memory item deleted successfully"})
+}
+
+// PurgeMemory handles DELETE /memory/{identity}
+func (h *Handler) PurgeMemory(c *gin.Context) {
+	removed, err := h.agentManager.PurgeMemory(c.Param("identity"))
+	if err != nil {
+		c.JSON(memoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Memory purged successfully", "removed": removed})
+}
+
+// PurgeParticipantMemory handles DELETE /memory?participant={name}
+func (h *Handler) PurgeParticipantMemory(c *gin.Context) {
+	removed, err := h.agentManager.PurgeParticipantMemory(c.Query("participant"))
+	if err != nil {
+		c.JSON(memoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Participant purged from memory", "removed": removed})
+}
+
+// memoryErrorStatus maps memory store errors to HTTP status codes
+func memoryErrorStatus(err error) int {
+	switch err.Error() {
+	case "memory not found", "memory item not found":
+		return http.StatusNotFound
+	case "invalid identity", "invalid memory kind", "memory content is required", "memory content too long", "participant is required":
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListKnowledgeBases handles GET /knowledge
+func (h *Handler) ListKnowledgeBases(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"knowledge_bases": h.agentManager.ListKnowledgeBases()})
+}
+
+// ListKnowledgeDocuments handles GET /knowledge/{knowledge_base}
+func (h *Handler) ListKnowledgeDocuments(c *gin.Context) {
+	name := c.Param("knowledge_base")
+
+	documents, err := h.agentManager.ListKnowledgeDocuments(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"knowledge_base": name, "documents": documents})
+}
+
+// AddKnowledgeDocument handles POST /knowledge/{knowledge_base}/documents. The document is
+// either a JSON body or a multipart "file" upload with optional "title" and "format" fields.
+func (h *Handler) AddKnowledgeDocument(c *gin.Context) {
+	var request struct {
+		Title   string `json:"title"`
+		Source  string `json:"source"`
+		Format  string `json:"format"`
+		Content string `json:"content"`
+	}
+
+	if file, err := c.FormFile("file"); err == nil {
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		content, err := io.ReadAll(opened)
+		opened.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.Title = c.PostForm("title")
+		request.Format = c.PostForm("format")
+		request.Source = file.Filename
+		request.Content = string(content)
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document, err := h.agentManager.AddKnowledgeDocument(c.Request.Context(), c.Param("knowledge_base"), request.Title, request.Source, request.Format, request.Content)
+	if err != nil {
+		c.JSON(knowledgeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// GetKnowledgeDocument handles GET /knowledge/{knowledge_base}/documents/{document_id}
+func (h *Handler) GetKnowledgeDocument(c *gin.Context) {
+	document, chunks, err := h.agentManager.GetKnowledgeDocument(c.Param("knowledge_base"), c.Param("document_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"document": document, "chunks": chunks})
+}
+
+// DeleteKnowledgeDocument handles DELETE /knowledge/{knowledge_base}/documents/{document_id}
+func (h *Handler) DeleteKnowledgeDocument(c *gin.Context) {
+	if err := h.agentManager.DeleteKnowledgeDocument(c.Param("knowledge_base"), c.Param("document_id")); err != nil {
+		c.JSON(knowledgeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
+}
+
+// DeleteKnowledgeBase handles DELETE /knowledge/{knowledge_base}
+func (h *Handler) DeleteKnowledgeBase(c *gin.Context) {
+	if err := h.agentManager.DeleteKnowledgeBase(c.Param("knowledge_base")); err != nil {
+		c.JSON(knowledgeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Knowledge base deleted successfully"})
+}
+
+// SearchKnowledge handles GET /knowledge/{knowledge_base}/search?q={query}&k={passages}
+func (h *Handler) SearchKnowledge(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	k := 0 // default
+	if kStr := c.Query("k"); kStr != "" {
+		if parsedK, err := strconv.Atoi(kStr); err == nil && parsedK > 0 && parsedK <= 20 {
+			k = parsedK
+		}
+	}
+
+	passages, err := h.agentManager.SearchKnowledge(c.Request.Context(), c.Param("knowledge_base"), query, k)
+	if err != nil {
+		c.JSON(knowledgeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": query, "passages": passages})
+}
+
+// knowledgeErrorStatus maps knowledge store errors to HTTP status codes
+func knowledgeErrorStatus(err error) int {
+	switch err.Error() {
+	case "knowledge base not found", "document not found":
+		return http.StatusNotFound
+	case "invalid knowledge base name", "unsupported document format", "document content is required":
+		return http.StatusBadRequest
+	case "document too large":
+		return http.StatusRequestEntityTooLarge
+	case "knowledge base full":
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"joinly-manager/internal/metrics"
+	"joinly-manager/internal/models"
+)
+
+// metricsMiddleware records request counts and latency per route template
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Use the route template (e.g. /agents/:agent_id) to keep label cardinality bounded
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.ObserveHTTPRequest(route, c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// maxAuditResponse is how much of a response body the audit middleware inspects for the ID of
+// a created resource and the error message
+const maxAuditResponse = 4096
+
+// auditActions names the mutating routes in the audit trail; other mutating routes are recorded
+// as "<method> <route>", so that new routes are never left out
+var auditActions = map[string]string{
+	"POST /agents":                                             "agent.create",
+	"PATCH /agents/:agent_id":                                  "agent.update",
+	"DELETE /agents/:agent_id":                                 "agent.delete",
+	"POST /agents/:agent_id/start":                             "agent.start",
+	"POST /agents/:agent_id/stop":                              "agent.stop",
+	"POST /agents/:agent_id/join-meeting":                      "agent.join",
+	"POST /agents/:agent_id/replay":                            "agent.replay",
+	"DELETE /meetings/history/:meeting_id":                     "meeting_record.delete",
+	"POST /backends":                                           "backend.register",
+	"DELETE /backends/:backend_id":                             "backend.deregister",
+	"DELETE /memory":                                           "memory.purge_participant",
+	"DELETE /memory/:identity":                                 "memory.purge",
+	"POST /memory/:identity/items":                             "memory_item.create",
+	"PATCH /memory/:identity/items/:item_id":                   "memory_item.update",
+	"DELETE /memory/:identity/items/:item_id":                  "memory_item.delete",
+	"DELETE /knowledge/:knowledge_base":                        "knowledge_base.delete",
+	"POST /knowledge/:knowledge_base/documents":                "knowledge_document.create",
+	"DELETE /knowledge/:knowledge_base/documents/:document_id": "knowledge_document.delete",
+}
+
+// auditExempt lists the POST routes that change nothing
+var auditExempt = map[string]bool{
+	"POST /agents/validate": true,
+}
+
+// auditWriter keeps the start of the response body while passing it through
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write passes data through, keeping up to maxAuditResponse bytes
+func (w *auditWriter) Write(data []byte) (int, error) {
+	if room := maxAuditResponse - w.body.Len(); room > 0 {
+		w.body.Write(data[:min(len(data), room)])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString passes s through, keeping up to maxAuditResponse bytes
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// auditMiddleware records every mutating request in the audit trail once it has been handled:
+// the principal named by principalHeader, the source IP, a digest of the payload and the outcome
+func auditMiddleware(principalHeader string, record func(models.AuditEntry)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method, route := c.Request.Method, c.FullPath()
+		switch method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		// Unmatched routes change nothing
+		if route == "" || auditExempt[method+" "+route] {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		writer := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		entry := models.AuditEntry{
+			Action:       auditActions[method+" "+route],
+			Method:       method,
+			Path:         c.Request.URL.Path,
+			Principal:    strings.TrimSpace(c.GetHeader(principalHeader)),
+			SourceIP:     c.ClientIP(),
+			PayloadBytes: len(body),
+			Status:       writer.Status(),
+			Outcome:      models.AuditSuccess,
+		}
+		if entry.Action == "" {
+			entry.Action = strings.ToLower(method) + " " + route
+		}
+		if entry.Principal == "" {
+			entry.Principal = "anonymous"
+		}
+		if len(body) > 0 {
+			sum := sha256.Sum256(body)
+			entry.PayloadDigest = "sha256:" + hex.EncodeToString(sum[:])
+		}
+
+		// The most specific route parameter names the target, or the ID of a created resource
+		if len(c.Params) > 0 {
+			entry.Params = make(map[string]string, len(c.Params))
+			for _, param := range c.Params {
+				entry.Params[param.Key] = param.Value
+				entry.Target = param.Value
+			}
+		}
+		var response struct {
+			ID    string `json:"id"`
+			Error string `json:"error"`
+		}
+		json.Unmarshal(writer.body.Bytes(), &response)
+		if entry.Target == "" {
+			entry.Target = response.ID
+		}
+		if entry.Status >= http.StatusBadRequest {
+			entry.Outcome = models.AuditFailure
+			entry.Error = response.Error
+		}
+
+		record(entry)
+	}
+}
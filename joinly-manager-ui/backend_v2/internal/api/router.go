@@ -8,6 +8,7 @@ import (
 
 	"joinly-manager/internal/config"
 	"joinly-manager/internal/manager"
+	"joinly-manager/internal/metrics"
 )
 
 // SetupRouter sets up the Gin router with all routes
@@ -24,6 +25,8 @@ func SetupRouter(cfg *config.Config, agentManager *manager.AgentManager) *gin.En
 	// Add middleware
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(metricsMiddleware())
+	router.Use(auditMiddleware(cfg.Audit.PrincipalHeader, agentManager.RecordAudit))
 
 	// CORS middleware
 	router.Use(cors.New(cors.Config{
@@ -45,14 +48,20 @@ func SetupRouter(cfg *config.Config, agentManager *manager.AgentManager) *gin.En
 	{
 		agents.GET("", handler.ListAgents)
 		agents.POST("", handler.CreateAgent)
+		agents.POST("/validate", handler.ValidateAgent)
 		agents.GET("/:agent_id", handler.GetAgent)
+		agents.PATCH("/:agent_id", handler.UpdateAgent)
 		agents.DELETE("/:agent_id", handler.DeleteAgent)
 		agents.POST("/:agent_id/start", handler.StartAgent)
 		agents.POST("/:agent_id/stop", handler.StopAgent)
 		agents.POST("/:agent_id/join-meeting", handler.JoinMeeting)
 		agents.GET("/:agent_id/logs", handler.GetAgentLogs)
+		agents.GET("/:agent_id/latency", handler.GetAgentLatency)
 		agents.GET("/:agent_id/analysis", handler.GetAgentAnalysis)
 		agents.GET("/:agent_id/analysis/formatted", handler.GetAgentAnalysisFormatted)
+		agents.GET("/:agent_id/transcript", handler.GetAgentTranscript)
+		agents.POST("/:agent_id/replay", handler.StartReplay)
+		agents.GET("/:agent_id/replay", handler.GetReplay)
 	}
 
 	// WebSocket routes
@@ -61,10 +70,52 @@ func SetupRouter(cfg *config.Config, agentManager *manager.AgentManager) *gin.En
 
 	// Meeting routes
 	router.GET("/meetings", handler.ListMeetings)
+	router.GET("/meetings/history", handler.ListMeetingHistory)
+	router.GET("/meetings/history/:meeting_id", handler.GetMeetingRecord)
+	router.DELETE("/meetings/history/:meeting_id", handler.DeleteMeetingRecord)
+	router.GET("/search", handler.SearchMeetings)
+
+	// Joinly backend pool routes
+	backends := router.Group("/backends")
+	{
+		backends.GET("", handler.ListBackends)
+		backends.POST("", handler.RegisterBackend)
+		backends.DELETE("/:backend_id", handler.DeregisterBackend)
+	}
+
+	// Long-term memory of agent identities
+	memory := router.Group("/memory")
+	{
+		memory.GET("", handler.ListMemoryIdentities)
+		memory.DELETE("", handler.PurgeParticipantMemory)
+		memory.GET("/:identity", handler.GetMemory)
+		memory.DELETE("/:identity", handler.PurgeMemory)
+		memory.POST("/:identity/items", handler.AddMemoryItem)
+		memory.PATCH("/:identity/items/:item_id", handler.UpdateMemoryItem)
+		memory.DELETE("/:identity/items/:item_id", handler.DeleteMemoryItem)
+	}
+
+	// Knowledge bases conversational agents answer from
+	knowledge := router.Group("/knowledge")
+	{
+		knowledge.GET("", handler.ListKnowledgeBases)
+		knowledge.GET("/:knowledge_base", handler.ListKnowledgeDocuments)
+		knowledge.DELETE("/:knowledge_base", handler.DeleteKnowledgeBase)
+		knowledge.GET("/:knowledge_base/search", handler.SearchKnowledge)
+		knowledge.POST("/:knowledge_base/documents", handler.AddKnowledgeDocument)
+		knowledge.GET("/:knowledge_base/documents/:document_id", handler.GetKnowledgeDocument)
+		knowledge.DELETE("/:knowledge_base/documents/:document_id", handler.DeleteKnowledgeDocument)
+	}
+
+	// Audit trail of mutating actions
+	router.GET("/audit", handler.ListAudit)
+	router.GET("/audit/export", handler.ExportAudit)
 
 	// Additional utility routes
 	router.GET("/usage", handler.GetUsageStats)
 	router.GET("/ws/stats", handler.GetWebSocketStats)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/debug/llm", handler.GetLLMExchanges)
 
 	return router
 }
@@ -0,0 +1,302 @@
+// Package archive keeps a durable record of every meeting session: who took part, which agents
+// were involved, the transcript, analyses and LLM cost. Each record is stored as one JSON file
+// and outlives the agents that produced it until a retention policy deletes it.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/storage"
+)
+
+const (
+	// DefaultLimit is the page size of listings that set no limit
+	DefaultLimit = 20
+	// MaxLimit caps the page size of listings
+	MaxLimit = 100
+)
+
+// idPattern restricts record IDs to names that are safe as file names
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Query selects archived meetings. Zero fields do not filter.
+type Query struct {
+	MeetingURL  string
+	AgentID     string
+	Participant string
+	From, To    time.Time // Range of the session start; To is exclusive
+	Offset      int
+	Limit       int
+}
+
+// Store holds the archived meeting records in <dir>/<id>.json. Listings are served from memory;
+// transcripts and analyses are read from disk when a single record is requested.
+type Store struct {
+	dir     string
+	vault   *storage.Vault
+	records map[string]models.MeetingRecord // id -> record without transcript and analyses
+	mu      sync.RWMutex
+	now     func() time.Time
+}
+
+// NewStore creates a store backed by dir and loads the records already saved there. Records
+// are written through vault, which may be nil. The store is usable even when loading fails;
+// unreadable records are skipped.
+func NewStore(dir string, vault *storage.Vault) (*Store, error) {
+	s := &Store{
+		dir:     dir,
+		vault:   vault,
+		records: make(map[string]models.MeetingRecord),
+		now:     time.Now,
+	}
+
+	if err := vault.MkdirAll(dir); err != nil {
+		return s, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return s, fmt.Errorf("failed to list archive files: %w", err)
+	}
+
+	var loadErrs []string
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), ".json")
+		if !idPattern.MatchString(id) {
+			continue
+		}
+		record, err := s.readRecord(file)
+		if err != nil {
+			loadErrs = append(loadErrs, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		record.ID = id
+		s.records[id] = summarize(record)
+	}
+
+	if len(loadErrs) > 0 {
+		return s, fmt.Errorf("failed to load archive: %s", strings.Join(loadErrs, "; "))
+	}
+	return s, nil
+}
+
+// Save archives a meeting record, assigning it an ID when it has none, and returns its summary
+func (s *Store) Save(record models.MeetingRecord) (models.MeetingRecord, error) {
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("meeting_%s", uuid.New().String()[:8])
+	}
+	if !idPattern.MatchString(record.ID) {
+		return models.MeetingRecord{}, fmt.Errorf("invalid meeting record id")
+	}
+	record.Segments = len(record.Transcript)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return models.MeetingRecord{}, fmt.Errorf("failed to encode meeting record: %w", err)
+	}
+
+	if err := s.vault.WriteFile(s.path(record.ID), data); err != nil {
+		return models.MeetingRecord{}, fmt.Errorf("failed to write meeting record: %w", err)
+	}
+
+	summary := summarize(record)
+	s.records[record.ID] = summary
+	return summary, nil
+}
+
+// Get returns a full meeting record, including its transcript and analyses
+func (s *Store) Get(id string) (models.MeetingRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.records[id]; !exists {
+		return models.MeetingRecord{}, fmt.Errorf("meeting record not found")
+	}
+	record, err := s.readRecord(s.path(id))
+	if err != nil {
+		return models.MeetingRecord{}, fmt.Errorf("failed to read meeting record: %w", err)
+	}
+	record.ID = id
+	return record, nil
+}
+
+// List returns one page of the records matching a query, newest first
+func (s *Store) List(query Query) models.MeetingHistory {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	offset := max(query.Offset, 0)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []models.MeetingRecord
+	for _, record := range s.records {
+		if query.accepts(record) {
+			matches = append(matches, record)
+		}
+	}
+	sortNewestFirst(matches)
+
+	page := models.MeetingHistory{Meetings: []models.MeetingRecord{}, Total: len(matches), Offset: offset, Limit: limit}
+	for _, record := range matches[min(offset, len(matches)):min(offset+limit, len(matches))] {
+		page.Meetings = append(page.Meetings, copyRecord(record))
+	}
+	return page
+}
+
+// Delete removes a record together with the analysis files of its agents
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.records[id]; !exists {
+		return fmt.Errorf("meeting record not found")
+	}
+	return s.deleteUnsafe(id)
+}
+
+// Prune deletes the records of sessions that ended more than maxAge ago and, beyond maxRecords,
+// the oldest remaining ones. Zero disables either limit. It returns the number of records deleted.
+func (s *Store) Prune(maxAge time.Duration, maxRecords int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]models.MeetingRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	sortNewestFirst(records)
+
+	cutoff := s.now().Add(-maxAge)
+	deleted := 0
+	var errs []string
+	for i, record := range records {
+		expired := maxAge > 0 && record.EndedAt.Before(cutoff)
+		excess := maxRecords > 0 && i >= maxRecords
+		if !expired && !excess {
+			continue
+		}
+		if err := s.deleteUnsafe(record.ID); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		deleted++
+	}
+
+	if len(errs) > 0 {
+		return deleted, fmt.Errorf("failed to prune archive: %s", strings.Join(errs, "; "))
+	}
+	return deleted, nil
+}
+
+// deleteUnsafe removes a record's files and forgets it (caller must hold lock)
+func (s *Store) deleteUnsafe(id string) error {
+	for _, agent := range s.records[id].Agents {
+		if agent.AnalysisFile == "" {
+			continue
+		}
+		if err := os.Remove(agent.AnalysisFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete analysis file of %s: %w", id, err)
+		}
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete meeting record %s: %w", id, err)
+	}
+	delete(s.records, id)
+	return nil
+}
+
+// path returns the file of a record
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// accepts reports whether a record matches the filters of the query
+func (q Query) accepts(record models.MeetingRecord) bool {
+	if q.MeetingURL != "" && record.MeetingURL != q.MeetingURL {
+		return false
+	}
+	if !q.From.IsZero() && record.StartedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !record.StartedAt.Before(q.To) {
+		return false
+	}
+	if q.AgentID != "" && !containsFunc(record.Agents, func(agent models.MeetingRecordAgent) bool { return agent.ID == q.AgentID }) {
+		return false
+	}
+	if q.Participant != "" && !containsFunc(record.Participants, func(participant string) bool { return strings.EqualFold(participant, q.Participant) }) {
+		return false
+	}
+	return true
+}
+
+// containsFunc reports whether any element of items satisfies match
+func containsFunc[T any](items []T, match func(T) bool) bool {
+	for _, item := range items {
+		if match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// readRecord reads a record file
+func (s *Store) readRecord(path string) (models.MeetingRecord, error) {
+	var record models.MeetingRecord
+	data, err := s.vault.ReadFile(path)
+	if err != nil {
+		return record, err
+	}
+	err = json.Unmarshal(data, &record)
+	return record, err
+}
+
+// summarize returns a record without its transcript and analyses
+func summarize(record models.MeetingRecord) models.MeetingRecord {
+	record.Segments = len(record.Transcript)
+	record.Transcript = nil
+	agents := make([]models.MeetingRecordAgent, len(record.Agents))
+	for i, agent := range record.Agents {
+		agent.Analysis = nil
+		agents[i] = agent
+	}
+	record.Agents = agents
+	return record
+}
+
+// copyRecord returns a copy of a summary that shares no slices with the store
+func copyRecord(record models.MeetingRecord) models.MeetingRecord {
+	record.Participants = append([]string{}, record.Participants...)
+	record.Agents = append([]models.MeetingRecordAgent{}, record.Agents...)
+	return record
+}
+
+// sortNewestFirst orders records by start time, newest first
+func sortNewestFirst(records []models.MeetingRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].StartedAt.Equal(records[j].StartedAt) {
+			return records[i].StartedAt.After(records[j].StartedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+}
@@ -0,0 +1,148 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+)
+
+var day0 = time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// meetingOn returns a record of a meeting held days after day0
+func meetingOn(days int, url string, agentID string, participants ...string) models.MeetingRecord {
+	start := day0.AddDate(0, 0, days)
+	return models.MeetingRecord{
+		MeetingURL:   url,
+		StartedAt:    start,
+		EndedAt:      start.Add(time.Hour),
+		Participants: participants,
+		Agents:       []models.MeetingRecordAgent{{ID: agentID, Name: "Ada", Analysis: []byte(`{"summary": "Done."}`)}},
+		Transcript:   []models.MeetingSegment{{Speaker: "Bob", Text: "Hello."}},
+	}
+}
+
+func TestStoreListsAndReloads(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for i, record := range []models.MeetingRecord{
+		meetingOn(0, "https://meet.google.com/a", "agent_1", "Bob"),
+		meetingOn(1, "https://zoom.us/j/1", "agent_2", "Carol"),
+		meetingOn(2, "https://meet.google.com/a", "agent_3", "Bob", "Carol"),
+	} {
+		saved, err := store.Save(record)
+		if err != nil {
+			t.Fatalf("Save %d: %v", i, err)
+		}
+		if saved.Transcript != nil || saved.Segments != 1 || saved.Agents[0].Analysis != nil {
+			t.Errorf("Expected a summary, got %+v", saved)
+		}
+		ids = append(ids, saved.ID)
+	}
+
+	// Reloading from disk keeps every record
+	store, err = NewStore(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		query    Query
+		expected []string
+		total    int
+	}{
+		{Query{}, []string{ids[2], ids[1], ids[0]}, 3},
+		{Query{Limit: 2}, []string{ids[2], ids[1]}, 3},
+		{Query{Offset: 2, Limit: 2}, []string{ids[0]}, 3},
+		{Query{Offset: 5}, nil, 3},
+		{Query{MeetingURL: "https://meet.google.com/a"}, []string{ids[2], ids[0]}, 2},
+		{Query{AgentID: "agent_2"}, []string{ids[1]}, 1},
+		{Query{Participant: "carol"}, []string{ids[2], ids[1]}, 2},
+		{Query{From: day0.AddDate(0, 0, 1), To: day0.AddDate(0, 0, 2)}, []string{ids[1]}, 1},
+	}
+	for _, c := range cases {
+		page := store.List(c.query)
+		var got []string
+		for _, record := range page.Meetings {
+			got = append(got, record.ID)
+		}
+		if page.Total != c.total || len(got) != len(c.expected) {
+			t.Errorf("List(%+v) = %v (total %d), expected %v (total %d)", c.query, got, page.Total, c.expected, c.total)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.expected[i] {
+				t.Errorf("List(%+v) = %v, expected %v", c.query, got, c.expected)
+				break
+			}
+		}
+	}
+
+	record, err := store.Get(ids[0])
+	if err != nil || len(record.Transcript) != 1 || !strings.Contains(string(record.Agents[0].Analysis), `"Done."`) {
+		t.Errorf("Get returned %+v, %v", record, err)
+	}
+	if _, err := store.Get("meeting_missing"); err == nil || err.Error() != "meeting record not found" {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestStorePruneDeletesRecordsAndFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewStore(dir, nil)
+	store.now = func() time.Time { return day0.AddDate(0, 0, 30) }
+
+	var ids []string
+	var analysisFiles []string
+	for days := 0; days < 4; days++ {
+		record := meetingOn(days*10, "https://meet.google.com/a", "agent_1")
+		analysisFile := filepath.Join(dir, "analysis", record.StartedAt.Format("20060102")+".json")
+		os.MkdirAll(filepath.Dir(analysisFile), 0755)
+		os.WriteFile(analysisFile, []byte("{}"), 0644)
+		record.Agents[0].AnalysisFile = analysisFile
+
+		saved, err := store.Save(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, saved.ID)
+		analysisFiles = append(analysisFiles, analysisFile)
+	}
+
+	// Meetings from days 0 and 10 ended more than 15 days before day 30
+	deleted, err := store.Prune(15*24*time.Hour, 0)
+	if err != nil || deleted != 2 {
+		t.Fatalf("Prune deleted %d, %v", deleted, err)
+	}
+	for i, id := range ids {
+		_, recordErr := os.Stat(filepath.Join(dir, id+".json"))
+		_, analysisErr := os.Stat(analysisFiles[i])
+		kept := i >= 2
+		if kept != (recordErr == nil) || kept != (analysisErr == nil) {
+			t.Errorf("record %d: kept %t, but record error %v, analysis error %v", i, kept, recordErr, analysisErr)
+		}
+	}
+
+	// Only the newest record survives a limit of one
+	if deleted, err := store.Prune(0, 1); err != nil || deleted != 1 {
+		t.Fatalf("Prune deleted %d, %v", deleted, err)
+	}
+	if page := store.List(Query{}); page.Total != 1 || page.Meetings[0].ID != ids[3] {
+		t.Errorf("unexpected records after pruning: %+v", page)
+	}
+
+	if err := store.Delete(ids[3]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ids[3]); err == nil {
+		t.Error("Expected deleting a deleted record to fail")
+	}
+}
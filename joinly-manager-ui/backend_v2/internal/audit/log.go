@@ -0,0 +1,246 @@
+// Package audit keeps an append-only trail of the mutating actions taken through the API: who
+// did what to which resource, from where, with which payload and how it ended. Entries are
+// appended to a JSONL file and chained by hash, so that a removed or altered entry is detected
+// when the trail is loaded.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/storage"
+)
+
+const (
+	// DefaultLimit is the page size of queries that set no limit
+	DefaultLimit = 50
+	// MaxLimit caps the page size of queries
+	MaxLimit = 500
+)
+
+// fileName is the audit trail inside the audit directory
+const fileName = "audit.jsonl"
+
+// Query selects audit entries. Zero fields do not filter.
+type Query struct {
+	Action    string // Exact action, or a prefix ending in "." such as "agent."
+	Principal string
+	Target    string // Matches the target or any route parameter
+	Outcome   string
+	From, To  time.Time // To is exclusive
+	Offset    int
+	Limit     int
+}
+
+// accepts reports whether an entry matches the query
+func (q Query) accepts(entry models.AuditEntry) bool {
+	if q.Action != "" {
+		if strings.HasSuffix(q.Action, ".") {
+			if !strings.HasPrefix(entry.Action, q.Action) {
+				return false
+			}
+		} else if entry.Action != q.Action {
+			return false
+		}
+	}
+	if q.Principal != "" && !strings.EqualFold(entry.Principal, q.Principal) {
+		return false
+	}
+	if q.Outcome != "" && entry.Outcome != q.Outcome {
+		return false
+	}
+	if q.Target != "" && entry.Target != q.Target {
+		found := false
+		for _, value := range entry.Params {
+			found = found || value == q.Target
+		}
+		if !found {
+			return false
+		}
+	}
+	if !q.From.IsZero() && entry.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !entry.Time.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// Log is the audit trail in <dir>/audit.jsonl. Entries are kept in memory for queries; the file
+// is only ever appended to. With an encrypting vault every line is sealed on its own.
+type Log struct {
+	path  string
+	vault *storage.Vault
+
+	mu       sync.RWMutex
+	entries  []models.AuditEntry // Oldest first
+	lastHash string
+	now      func() time.Time
+}
+
+// Open loads the audit trail in dir, creating the directory when needed. The log is usable even
+// when loading fails or the hash chain is broken; the error says where.
+func Open(dir string, vault *storage.Vault) (*Log, error) {
+	l := &Log{path: filepath.Join(dir, fileName), vault: vault, now: time.Now}
+
+	if err := vault.MkdirAll(dir); err != nil {
+		return l, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var loadErr error
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		entry, err := l.decode(scanner.Bytes())
+		if err != nil {
+			if loadErr == nil {
+				loadErr = fmt.Errorf("audit log line %d: %w", line, err)
+			}
+			continue
+		}
+		if loadErr == nil && (entry.PrevHash != l.lastHash || entry.Hash != hashEntry(entry)) {
+			loadErr = fmt.Errorf("audit log line %d: hash chain broken at entry %d", line, entry.Seq)
+		}
+		l.entries = append(l.entries, entry)
+		l.lastHash = entry.Hash
+	}
+	if err := scanner.Err(); err != nil && loadErr == nil {
+		loadErr = fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return l, loadErr
+}
+
+// Append completes an entry with its sequence number, time and hash, and appends it to the trail
+func (l *Log) Append(entry models.AuditEntry) (models.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Seq = 1
+	if len(l.entries) > 0 {
+		entry.Seq = l.entries[len(l.entries)-1].Seq + 1
+	}
+	if entry.Time.IsZero() {
+		entry.Time = l.now()
+	}
+	entry.Time = entry.Time.UTC()
+	entry.PrevHash = l.lastHash
+	entry.Hash = hashEntry(entry)
+
+	line, err := l.encode(entry)
+	if err != nil {
+		return entry, err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return entry, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return entry, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	l.entries = append(l.entries, entry)
+	l.lastHash = entry.Hash
+	return entry, nil
+}
+
+// Query returns one page of the entries matching a query, newest first
+func (l *Log) Query(query Query) models.AuditPage {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	offset := max(query.Offset, 0)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	page := models.AuditPage{Entries: []models.AuditEntry{}, Offset: offset, Limit: limit}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		if !query.accepts(l.entries[i]) {
+			continue
+		}
+		if page.Total >= offset && len(page.Entries) < limit {
+			page.Entries = append(page.Entries, l.entries[i])
+		}
+		page.Total++
+	}
+	return page
+}
+
+// Export writes every entry matching a query as JSON lines, oldest first. Offset and limit are
+// ignored. It returns the number of entries written.
+func (l *Log) Export(query Query, w io.Writer) (int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	encoder := json.NewEncoder(w)
+	written := 0
+	for _, entry := range l.entries {
+		if !query.accepts(entry) {
+			continue
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// encode serialises an entry as one line; sealed lines are base64 encoded
+func (l *Log) encode(entry models.AuditEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line, err := l.vault.SealLine(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt audit entry: %w", err)
+	}
+	return line, nil
+}
+
+// decode parses a line written by encode
+func (l *Log) decode(line []byte) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	data, err := l.vault.OpenLine(line)
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(data, &entry)
+	return entry, err
+}
+
+// hashEntry returns the chain hash of an entry: the SHA-256 of the previous hash and the entry
+// without its own hash
+func hashEntry(entry models.AuditEntry) string {
+	entry.Hash = ""
+	data, _ := json.Marshal(entry)
+	sum := sha256.Sum256(append([]byte(entry.PrevHash+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
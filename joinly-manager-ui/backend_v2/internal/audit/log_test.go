@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/storage"
+)
+
+var t0 = time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// appendAll appends entries one minute apart starting at t0
+func appendAll(t *testing.T, l *Log, entries ...models.AuditEntry) {
+	t.Helper()
+	for i, entry := range entries {
+		entry.Time = t0.Add(time.Duration(i) * time.Minute)
+		if _, err := l.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLogQueriesAndReloads(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendAll(t, l,
+		models.AuditEntry{Action: "agent.create", Principal: "alice", Target: "agent_1", Outcome: models.AuditSuccess},
+		models.AuditEntry{Action: "agent.start", Principal: "bob", Target: "agent_1", Params: map[string]string{"agent_id": "agent_1"}, Outcome: models.AuditSuccess},
+		models.AuditEntry{Action: "memory_item.delete", Principal: "alice", Target: "item_9", Params: map[string]string{"identity": "ada", "item_id": "item_9"}, Outcome: models.AuditFailure},
+		models.AuditEntry{Action: "agent.delete", Principal: "Alice", Target: "agent_1", Params: map[string]string{"agent_id": "agent_1"}, Outcome: models.AuditSuccess},
+	)
+
+	// Reloading verifies the hash chain
+	l, err = Open(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		query    Query
+		expected []int64
+		total    int
+	}{
+		{Query{}, []int64{4, 3, 2, 1}, 4},
+		{Query{Limit: 2, Offset: 1}, []int64{3, 2}, 4},
+		{Query{Action: "agent."}, []int64{4, 2, 1}, 3},
+		{Query{Action: "agent.start"}, []int64{2}, 1},
+		{Query{Principal: "alice"}, []int64{4, 3, 1}, 3},
+		{Query{Target: "ada"}, []int64{3}, 1},
+		{Query{Outcome: models.AuditFailure}, []int64{3}, 1},
+		{Query{From: t0.Add(time.Minute), To: t0.Add(3 * time.Minute)}, []int64{3, 2}, 2},
+	}
+	for _, c := range cases {
+		page := l.Query(c.query)
+		var got []int64
+		for _, entry := range page.Entries {
+			got = append(got, entry.Seq)
+		}
+		if page.Total != c.total || len(got) != len(c.expected) {
+			t.Errorf("Query(%+v) = %v (total %d), expected %v (total %d)", c.query, got, page.Total, c.expected, c.total)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.expected[i] {
+				t.Errorf("Query(%+v) = %v, expected %v", c.query, got, c.expected)
+				break
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if n, err := l.Export(Query{Principal: "alice"}, &out); err != nil || n != 3 {
+		t.Fatalf("Export = %d, %v", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var first models.AuditEntry
+	if len(lines) != 3 || json.Unmarshal([]byte(lines[0]), &first) != nil || first.Seq != 1 {
+		t.Errorf("Expected three JSON lines, oldest first, got %q", out.String())
+	}
+}
+
+func TestLogDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := Open(dir, nil)
+	appendAll(t, l,
+		models.AuditEntry{Action: "agent.create", Principal: "alice"},
+		models.AuditEntry{Action: "agent.delete", Principal: "mallory"},
+	)
+
+	path := filepath.Join(dir, fileName)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte("mallory"), []byte("alice"), 1), 0600)
+
+	l, err := Open(dir, nil)
+	if err == nil || !strings.Contains(err.Error(), "hash chain broken at entry 2") {
+		t.Errorf("Expected a broken chain, got %v", err)
+	}
+	if page := l.Query(Query{}); page.Total != 2 {
+		t.Errorf("Expected the entries to stay queryable, got %d", page.Total)
+	}
+}
+
+func TestLogEncryptsEntries(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := storage.OpenLocalKeys(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := storage.NewVault(keys)
+
+	l, _ := Open(filepath.Join(dir, "audit"), vault)
+	appendAll(t, l, models.AuditEntry{Action: "agent.create", Principal: "alice"})
+
+	file, _ := os.Open(filepath.Join(dir, "audit", fileName))
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() || strings.Contains(scanner.Text(), "alice") {
+		t.Errorf("Expected an encrypted line, got %q", scanner.Text())
+	}
+
+	l, err = Open(filepath.Join(dir, "audit"), vault)
+	if err != nil || l.Query(Query{Principal: "alice"}).Total != 1 {
+		t.Errorf("Expected the encrypted entry to reload, got %v", err)
+	}
+}
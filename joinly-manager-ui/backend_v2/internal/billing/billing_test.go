@@ -0,0 +1,76 @@
+package billing
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/models"
+)
+
+func TestPriceTableLookup(t *testing.T) {
+	prices := DefaultPrices()
+
+	cases := map[string]float64{
+		"openai/gpt-4o":                   2.50,
+		"openai/gpt-4o-2024-08-06":        2.50,
+		"openai/gpt-4o-mini-2024-07-18":   0.15,
+		"anthropic/claude-3-5-haiku-late": 0.80,
+		"ollama/llama3.1:8b":              0,
+	}
+	for key, expected := range cases {
+		provider, model, _ := strings.Cut(key, "/")
+		price, ok := prices.Lookup(provider, model)
+		if !ok || price.InputPerMillion != expected {
+			t.Errorf("Lookup(%s) = %+v (%v), expected input price %v", key, price, ok, expected)
+		}
+	}
+
+	if _, ok := prices.Lookup("openai", "davinci-002"); ok {
+		t.Error("Expected unknown OpenAI model to be unpriced")
+	}
+}
+
+func TestLoadPricesOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	if err := os.WriteFile(path, []byte(`{"openai/gpt-4o": {"input_per_million": 1, "output_per_million": 2}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prices, err := LoadPrices(path)
+	if err != nil {
+		t.Fatalf("Failed to load prices: %v", err)
+	}
+	if price, _ := prices.Lookup("openai", "gpt-4o"); price.OutputPerMillion != 2 {
+		t.Errorf("Expected override to apply, got %+v", price)
+	}
+	if _, ok := prices.Lookup("google", "gemini-2.0-flash"); !ok {
+		t.Error("Expected defaults to be kept")
+	}
+}
+
+func TestLedgerAccumulatesBreakdowns(t *testing.T) {
+	ledger := NewLedger(DefaultPrices())
+
+	usage := models.LLMUsage{Provider: "openai", Model: "gpt-4o", PromptTokens: 1_000_000, CompletionTokens: 100_000}
+	ledger.Record("agent_1", "https://meet.google.com/a", "acme", usage)
+	totals := ledger.Record("agent_1", "https://meet.google.com/a", "acme", usage)
+	ledger.Record("agent_2", "https://meet.google.com/b", "", models.LLMUsage{Provider: "openai", Model: "unknown-model", PromptTokens: 10})
+
+	if totals.Calls != 2 || math.Abs(totals.CostUSD-7.0) > 1e-9 {
+		t.Errorf("Expected 2 calls costing $7, got %+v", totals)
+	}
+
+	report := ledger.Usage()
+	if report.Total.Calls != 3 || report.Total.UnpricedCalls != 1 {
+		t.Errorf("Unexpected total: %+v", report.Total)
+	}
+	if report.ByTenant["acme"].Calls != 2 || report.ByTenant[DefaultTenant].Calls != 1 {
+		t.Errorf("Unexpected tenant breakdown: %+v", report.ByTenant)
+	}
+	if report.ByMeeting["https://meet.google.com/b"].PromptTokens != 10 {
+		t.Errorf("Unexpected meeting breakdown: %+v", report.ByMeeting)
+	}
+}
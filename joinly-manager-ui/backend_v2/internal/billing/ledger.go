@@ -0,0 +1,124 @@
+package billing
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/models"
+)
+
+// DefaultTenant groups agents that were created without a tenant
+const DefaultTenant = "default"
+
+// Ledger accumulates priced LLM usage per agent, meeting, tenant and model
+type Ledger struct {
+	prices PriceTable
+
+	total     models.CostTotals
+	byAgent   map[string]models.CostTotals
+	byMeeting map[string]models.CostTotals
+	byTenant  map[string]models.CostTotals
+	byModel   map[string]models.CostTotals
+	unpriced  map[string]bool // Models already warned about
+	mu        sync.RWMutex
+}
+
+// NewLedger creates an empty ledger using the given price table
+func NewLedger(prices PriceTable) *Ledger {
+	return &Ledger{
+		prices:    prices,
+		byAgent:   make(map[string]models.CostTotals),
+		byMeeting: make(map[string]models.CostTotals),
+		byTenant:  make(map[string]models.CostTotals),
+		byModel:   make(map[string]models.CostTotals),
+		unpriced:  make(map[string]bool),
+	}
+}
+
+// Record prices one LLM call and adds it to every breakdown. It returns the agent's new totals.
+func (l *Ledger) Record(agentID, meetingURL, tenant string, usage models.LLMUsage) models.CostTotals {
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+	modelKey := string(usage.Provider) + "/" + usage.Model
+
+	call := models.CostTotals{
+		Calls:            1,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if price, ok := l.prices.Lookup(string(usage.Provider), usage.Model); ok {
+		call.CostUSD = price.Cost(usage.PromptTokens, usage.CompletionTokens)
+	} else {
+		call.UnpricedCalls = 1
+		if !l.unpriced[modelKey] {
+			l.unpriced[modelKey] = true
+			logrus.Warnf("No price configured for LLM model %s; its calls are counted without cost", modelKey)
+		}
+	}
+
+	l.total = Add(l.total, call)
+	l.byAgent[agentID] = Add(l.byAgent[agentID], call)
+	l.byMeeting[meetingURL] = Add(l.byMeeting[meetingURL], call)
+	l.byTenant[tenant] = Add(l.byTenant[tenant], call)
+	l.byModel[modelKey] = Add(l.byModel[modelKey], call)
+
+	return l.byAgent[agentID]
+}
+
+// AgentTotals returns the accumulated cost of one agent
+func (l *Ledger) AgentTotals(agentID string) models.CostTotals {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.byAgent[agentID]
+}
+
+// Usage returns a snapshot of every breakdown
+func (l *Ledger) Usage() models.CostUsage {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return models.CostUsage{
+		Total:     l.total,
+		ByAgent:   copyTotals(l.byAgent),
+		ByMeeting: copyTotals(l.byMeeting),
+		ByTenant:  copyTotals(l.byTenant),
+		ByModel:   copyTotals(l.byModel),
+	}
+}
+
+// Add sums two cost totals
+func Add(a, b models.CostTotals) models.CostTotals {
+	return models.CostTotals{
+		Calls:            a.Calls + b.Calls,
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		CostUSD:          a.CostUSD + b.CostUSD,
+		UnpricedCalls:    a.UnpricedCalls + b.UnpricedCalls,
+	}
+}
+
+// Subtract returns what was spent between two snapshots of cost totals
+func Subtract(after, before models.CostTotals) models.CostTotals {
+	return models.CostTotals{
+		Calls:            after.Calls - before.Calls,
+		PromptTokens:     after.PromptTokens - before.PromptTokens,
+		CompletionTokens: after.CompletionTokens - before.CompletionTokens,
+		CostUSD:          after.CostUSD - before.CostUSD,
+		UnpricedCalls:    after.UnpricedCalls - before.UnpricedCalls,
+	}
+}
+
+// copyTotals copies a breakdown map
+func copyTotals(totals map[string]models.CostTotals) map[string]models.CostTotals {
+	result := make(map[string]models.CostTotals, len(totals))
+	for key, value := range totals {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+// Package billing prices LLM token usage and accumulates cost per agent, meeting and tenant.
+package billing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million" yaml:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million" yaml:"output_per_million"`
+}
+
+// PriceTable maps "provider/model" keys to prices. A key also matches any model that
+// starts with it (dated snapshots such as gpt-4o-2024-08-06); the longest key wins.
+// A "provider/*" key prices every model of that provider.
+type PriceTable map[string]ModelPrice
+
+// DefaultPrices returns the built-in list prices (USD per million tokens)
+func DefaultPrices() PriceTable {
+	return PriceTable{
+		"openai/gpt-4o":                {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+		"openai/gpt-4o-mini":           {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"openai/gpt-4.1":               {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+		"openai/gpt-4.1-mini":          {InputPerMillion: 0.40, OutputPerMillion: 1.60},
+		"openai/gpt-4.1-nano":          {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		"openai/gpt-4-turbo":           {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+		"openai/gpt-3.5-turbo":         {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+		"openai/o1":                    {InputPerMillion: 15.00, OutputPerMillion: 60.00},
+		"openai/o1-mini":               {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+		"openai/o3":                    {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+		"openai/o3-mini":               {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+		"openai/o4-mini":               {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+		"anthropic/claude-3-haiku":     {InputPerMillion: 0.25, OutputPerMillion: 1.25},
+		"anthropic/claude-3-5-haiku":   {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+		"anthropic/claude-3-5-sonnet":  {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"anthropic/claude-3-7-sonnet":  {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"anthropic/claude-sonnet-4":    {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"anthropic/claude-3-opus":      {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+		"anthropic/claude-opus-4":      {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+		"google/gemini-1.5-flash":      {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+		"google/gemini-1.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+		"google/gemini-2.0-flash":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		"google/gemini-2.0-flash-lite": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+		"google/gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+		"google/gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+		"ollama/*":                     {}, // Local models are free
+		"openai_compatible/*":          {}, // Self-hosted; override in the price file for paid gateways
+		"mock/*":                       {}, // Scripted responses
+	}
+}
+
+// LoadPrices returns the default prices overridden by the JSON price file, if one is given.
+// The file holds an object of "provider/model" keys to ModelPrice values.
+func LoadPrices(path string) (PriceTable, error) {
+	prices := DefaultPrices()
+	if path == "" {
+		return prices, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file: %w", err)
+	}
+
+	var overrides PriceTable
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse price file: %w", err)
+	}
+
+	for key, price := range overrides {
+		prices[key] = price
+	}
+	return prices, nil
+}
+
+// Lookup finds the price of a provider's model
+func (t PriceTable) Lookup(provider, model string) (ModelPrice, bool) {
+	key := provider + "/" + model
+	if price, ok := t[key]; ok {
+		return price, true
+	}
+
+	bestKey := ""
+	for candidate := range t {
+		if strings.HasPrefix(key, candidate) && len(candidate) > len(bestKey) {
+			bestKey = candidate
+		}
+	}
+	if bestKey != "" {
+		return t[bestKey], true
+	}
+
+	price, ok := t[provider+"/*"]
+	return price, ok
+}
+
+// Cost returns the USD cost of the given token counts
+func (p ModelPrice) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1_000_000
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"joinly-manager/internal/client/llm"
+	"joinly-manager/internal/metrics"
+	"joinly-manager/internal/models"
+)
+
+// defaultFollowUpWindow is how long after a reply participants can follow up without the name
+const defaultFollowUpWindow = 20 * time.Second
+
+// addressingCheckTimeout bounds the optional LLM addressing check
+const addressingCheckTimeout = 5 * time.Second
+
+// Reasons of addressing decisions, also used as metric labels
+const (
+	addressedByName      = "name"
+	addressedByFuzzy     = "fuzzy"
+	addressedByPhonetic  = "phonetic"
+	addressedByFollowUp  = "follow_up"
+	addressedByLLM       = "llm"
+	addressedNoName      = "no_name"
+	notAddressed         = "none"
+	notAddressedByLLM    = "llm_rejected"
+	notAddressedSilenced = "silenced" // LLM check skipped while the budget silences the agent
+)
+
+// domainPattern matches bare domains and file names such as joinly.ai or joinly.py
+var domainPattern = regexp.MustCompile(`^[\w-]+(\.[\w-]+)+(/\S*)?$`)
+
+// addressingDecision represents whether an utterance is directed at the agent and why
+type addressingDecision struct {
+	Addressed bool
+	Reason    string
+	Match     string // The words that matched a name or alias
+}
+
+// classifyAddressing decides whether text is directed at an agent from its name and aliases,
+// tolerating STT misspellings, and from how recently the agent replied
+func classifyAddressing(config models.AgentConfig, text string, lastReply, now time.Time) addressingDecision {
+	if config.Name == "" {
+		return addressingDecision{Addressed: true, Reason: addressedNoName}
+	}
+
+	aliases := []string{config.Name}
+	if config.Addressing != nil {
+		aliases = append(aliases, config.Addressing.Aliases...)
+	}
+
+	words := addressingWords(text)
+	best := addressingDecision{Reason: notAddressed}
+	for _, alias := range aliases {
+		reason, match := matchAlias(alias, words)
+		if reason == "" {
+			continue
+		}
+		if reason == addressedByName {
+			return addressingDecision{Addressed: true, Reason: reason, Match: match}
+		}
+		if !best.Addressed || (reason == addressedByFuzzy && best.Reason == addressedByPhonetic) {
+			best = addressingDecision{Addressed: true, Reason: reason, Match: match}
+		}
+	}
+	if best.Addressed {
+		return best
+	}
+
+	window := defaultFollowUpWindow
+	if config.Addressing != nil && config.Addressing.FollowUpSeconds != 0 {
+		window = time.Duration(config.Addressing.FollowUpSeconds) * time.Second
+	}
+	if window > 0 && !lastReply.IsZero() && now.Sub(lastReply) <= window {
+		return addressingDecision{Addressed: true, Reason: addressedByFollowUp}
+	}
+
+	return best
+}
+
+// addressingWords returns the lowercase words of text, leaving out URLs, e-mail addresses and
+// domains so that a name inside them does not count as addressing the agent
+func addressingWords(text string) []string {
+	var words []string
+	for _, token := range strings.Fields(strings.ToLower(text)) {
+		trimmed := strings.TrimFunc(token, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if strings.Contains(token, "://") || strings.HasPrefix(token, "www.") ||
+			strings.Contains(token, "@") || domainPattern.MatchString(trimmed) {
+			continue
+		}
+		words = append(words, strings.FieldsFunc(token, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })...)
+	}
+	return words
+}
+
+// matchAlias looks for an alias among the words, also across one extra word so that names
+// split by STT ("join lee") are found. It returns the reason of the best match, if any.
+func matchAlias(alias string, words []string) (string, string) {
+	aliasWords := addressingWords(alias)
+	if len(aliasWords) == 0 {
+		return "", ""
+	}
+	target := strings.Join(aliasWords, "")
+
+	reason, match := "", ""
+	for size := len(aliasWords); size <= len(aliasWords)+1; size++ {
+		for start := 0; start+size <= len(words); start++ {
+			window := words[start : start+size]
+			candidate := strings.Join(window, "")
+			distance := levenshtein(candidate, target)
+
+			switch {
+			case distance == 0 && size == len(aliasWords):
+				return addressedByName, strings.Join(window, " ")
+			case distance <= allowedEdits(target) && candidate[0] == target[0]:
+				if reason != addressedByFuzzy {
+					reason, match = addressedByFuzzy, strings.Join(window, " ")
+				}
+			case reason == "" && len(target) >= 4 && soundsLike(candidate, target):
+				reason, match = addressedByPhonetic, strings.Join(window, " ")
+			}
+		}
+	}
+	return reason, match
+}
+
+// allowedEdits is the edit distance tolerated for a name of the given length; short names
+// must match exactly since one edit turns them into common words
+func allowedEdits(name string) int {
+	switch n := len([]rune(name)); {
+	case n < 5:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// soundsLike reports whether two words are spelled differently but sound alike, e.g. "joinlee"
+// and "joinly": same consonants, and at most one edit apart once common spellings of a sound
+// are normalised
+func soundsLike(a, b string) bool {
+	a, b = phoneticSpelling(a), phoneticSpelling(b)
+	return a[0] == b[0] && consonants(a) == consonants(b) && levenshtein(a, b) <= 1
+}
+
+// phoneticReplacer maps spellings of the same sound to one spelling
+var phoneticReplacer = strings.NewReplacer("ph", "f", "ck", "k", "gh", "g", "kn", "n", "wr", "r", "qu", "kw",
+	"ce", "se", "ci", "si", "cy", "sy", "c", "k", "q", "k", "x", "ks", "z", "s", "dg", "j", "ee", "i", "ea", "i", "oo", "u")
+
+// phoneticSpelling normalises the spelling of a word, including a final "y", "ie" or "ey"
+func phoneticSpelling(word string) string {
+	for _, ending := range []string{"ey", "ie", "y"} {
+		if len(word) > len(ending) && strings.HasSuffix(word, ending) {
+			word = strings.TrimSuffix(word, ending) + "i"
+			break
+		}
+	}
+	return phoneticReplacer.Replace(word)
+}
+
+// consonants returns the consonant skeleton of a word, keeping its first letter
+func consonants(word string) string {
+	var key []rune
+	for i, r := range word {
+		if i > 0 && strings.ContainsRune("aeiouyhw", r) {
+			continue
+		}
+		if len(key) > 0 && key[len(key)-1] == r {
+			continue
+		}
+		key = append(key, r)
+	}
+	return string(key)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// llmAddressingCheck reports whether unnamed utterances are checked with the LLM
+func llmAddressingCheck(config models.AgentConfig) bool {
+	return config.Addressing != nil && config.Addressing.LLMCheck
+}
+
+// noteAddressingDecision records an addressing decision for tuning
+func (c *JoinlyClient) noteAddressingDecision(decision addressingDecision, text string) {
+	metrics.IncAddressingDecisions(decision.Reason)
+
+	level := "debug"
+	if decision.Addressed {
+		level = "info"
+	}
+	detail := ""
+	if decision.Match != "" {
+		detail = fmt.Sprintf(", matched %q", decision.Match)
+	}
+	c.log(level, fmt.Sprintf("🎯 Addressing: addressed=%t (%s%s): %s", decision.Addressed, decision.Reason, detail, text))
+}
+
+// checkAddressedWithLLM asks the LLM whether an utterance is directed at the agent, using the
+// cheaper check model when one is configured
+func (c *JoinlyClient) checkAddressedWithLLM(ctx context.Context, text string) (addressingDecision, error) {
+	config := c.currentConfig()
+
+	model := ""
+	if config.Addressing != nil {
+		model = config.Addressing.LLMCheckModel
+	}
+	provider, err := newCheckChain(config, model, c.redactor)
+	if err != nil {
+		return addressingDecision{}, err
+	}
+
+	names := config.Name
+	if config.Addressing != nil && len(config.Addressing.Aliases) > 0 {
+		names += " (also called " + strings.Join(config.Addressing.Aliases, ", ") + ")"
+	}
+	prompt := fmt.Sprintf(`An AI assistant named %s listens to a meeting and should only speak when someone talks to it.
+The speech-to-text may misspell its name. Is the following utterance directed at the assistant,
+as opposed to the other participants?
+
+Utterance: %s`, names, text)
+
+	ctx, cancel := context.WithTimeout(ctx, addressingCheckTimeout)
+	defer cancel()
+
+	response, err := provider.CallWithSchema(ctx, prompt, addressingSchema())
+	c.recordLLMCall(response, err)
+	if err != nil {
+		return addressingDecision{}, fmt.Errorf("failed to check addressing: %w", err)
+	}
+
+	var parsed struct {
+		Addressed bool   `json:"addressed"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(response.Text), &parsed); err != nil {
+		return addressingDecision{}, fmt.Errorf("failed to parse addressing check: %w", err)
+	}
+
+	decision := addressingDecision{Addressed: parsed.Addressed, Reason: notAddressedByLLM, Match: parsed.Reason}
+	if parsed.Addressed {
+		decision.Reason = addressedByLLM
+	}
+	return decision, nil
+}
+
+// addressingSchema returns the schema of the LLM addressing check response
+func addressingSchema() *llm.ResponseSchema {
+	return &llm.ResponseSchema{
+		Name: "addressing",
+		Type: "OBJECT",
+		Properties: map[string]interface{}{
+			"addressed": map[string]interface{}{
+				"type":        "BOOLEAN",
+				"description": "Whether the utterance is directed at the assistant",
+			},
+			"reason": map[string]interface{}{
+				"type":        "STRING",
+				"description": "A few words explaining the decision",
+			},
+		},
+		Required: []string{"addressed"},
+	}
+}
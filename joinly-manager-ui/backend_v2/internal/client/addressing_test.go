@@ -0,0 +1,89 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+)
+
+func TestClassifyAddressing(t *testing.T) {
+	config := models.AgentConfig{Name: "Joinly", Addressing: &models.AddressingConfig{Aliases: []string{"Meeting Bot"}}}
+	now := time.Now()
+
+	tests := []struct {
+		text   string
+		reason string
+	}{
+		{"Joinly, what did we decide?", addressedByName},
+		{"hey joinly's notes are late", addressedByName},
+		{"join lee can you summarise that", addressedByPhonetic},
+		{"Joinlyy, any updates?", addressedByFuzzy},
+		{"ok meeting bot, take a note", addressedByName},
+		{"The docs are at https://joinly.ai/docs", notAddressed},
+		{"Check joinly.ai for the docs", notAddressed},
+		{"Let's join later", notAddressed},
+	}
+	for _, tt := range tests {
+		decision := classifyAddressing(config, tt.text, time.Time{}, now)
+		if decision.Reason != tt.reason || decision.Addressed != (tt.reason != notAddressed) {
+			t.Errorf("classifyAddressing(%q) = %+v, want %s", tt.text, decision, tt.reason)
+		}
+	}
+}
+
+func TestClassifyAddressingAvoidsSoundAlikes(t *testing.T) {
+	now := time.Now()
+	if decision := classifyAddressing(models.AgentConfig{Name: "Claude"}, "It runs in the cloud", time.Time{}, now); decision.Addressed {
+		t.Errorf("cloud matched Claude: %+v", decision)
+	}
+	if decision := classifyAddressing(models.AgentConfig{Name: "Peter"}, "Another meter reading", time.Time{}, now); decision.Addressed {
+		t.Errorf("meter matched Peter: %+v", decision)
+	}
+}
+
+func TestClassifyAddressingFollowUp(t *testing.T) {
+	config := models.AgentConfig{Name: "Ada"}
+	now := time.Now()
+
+	if decision := classifyAddressing(config, "And what about QA?", now.Add(-10*time.Second), now); decision.Reason != addressedByFollowUp {
+		t.Errorf("expected follow-up, got %+v", decision)
+	}
+	if decision := classifyAddressing(config, "And what about QA?", now.Add(-time.Minute), now); decision.Addressed {
+		t.Errorf("follow-up window should have passed: %+v", decision)
+	}
+
+	config.Addressing = &models.AddressingConfig{FollowUpSeconds: -1}
+	if decision := classifyAddressing(config, "And what about QA?", now.Add(-time.Second), now); decision.Addressed {
+		t.Errorf("follow-ups should be disabled: %+v", decision)
+	}
+}
+
+func TestCheckPendingAddressingSkipsLLMWhileSilenced(t *testing.T) {
+	config := models.AgentConfig{
+		Name:        "Ada",
+		LLMProvider: models.LLMProviderMock,
+		LLMModel:    "mock",
+		MockLLM:     &models.MockLLMConfig{Default: `{"addressed": true, "reason": "question"}`},
+		Addressing:  &models.AddressingConfig{LLMCheck: true},
+	}
+	c := NewJoinlyClient("agent", config, "")
+	var decisions []string
+	c.SetLogCallback(func(level, message string) {
+		if strings.Contains(message, "Addressing:") {
+			decisions = append(decisions, message)
+		}
+	})
+	c.SetSilenceCheck(func() bool { return true })
+	c.pendingSegments = []map[string]interface{}{{"text": "What do you think about the plan?"}}
+	c.pendingNeedsCheck = true
+
+	c.checkPendingAddressing(0)
+	if len(decisions) != 1 || !strings.Contains(decisions[0], "addressed=false (silenced)") {
+		t.Errorf("Expected a silenced agent to skip the LLM check, got %q", decisions)
+	}
+	if c.pendingAddressed || c.pendingNeedsCheck {
+		t.Errorf("Expected the utterance to stay unanswered, got addressed=%v needs check=%v", c.pendingAddressed, c.pendingNeedsCheck)
+	}
+}
@@ -78,6 +78,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -90,6 +91,7 @@ import (
 
 	"joinly-manager/internal/client/llm"
 	"joinly-manager/internal/models"
+	"joinly-manager/internal/storage"
 )
 
 // AnalysisData represents the comprehensive analysis data for a meeting
@@ -139,6 +141,32 @@ type TopicDiscussion struct {
 	Participants []string  `json:"participants"`
 }
 
+// DefaultAnalysisDir is where analyst agents save their meeting analyses unless configured otherwise
+const DefaultAnalysisDir = "data/analysis"
+
+// Storage of meeting analyses, set by ConfigureAnalysisStorage
+var (
+	analysisStorageMu sync.RWMutex
+	analysisDir       = DefaultAnalysisDir
+	analysisVault     *storage.Vault
+)
+
+// ConfigureAnalysisStorage sets the directory analyst agents save their meeting analyses in and
+// the vault the files are written through (nil for plaintext). It applies to analysts created
+// afterwards.
+func ConfigureAnalysisStorage(dir string, vault *storage.Vault) {
+	analysisStorageMu.Lock()
+	defer analysisStorageMu.Unlock()
+	analysisDir, analysisVault = dir, vault
+}
+
+// AnalysisDir returns the directory analyst agents save their meeting analyses in
+func AnalysisDir() string {
+	analysisStorageMu.RLock()
+	defer analysisStorageMu.RUnlock()
+	return analysisDir
+}
+
 // AnalystAgent handles meeting analysis and maintains comprehensive meeting notes
 type AnalystAgent struct {
 	agentID       string
@@ -146,34 +174,43 @@ type AnalystAgent struct {
 	data          *AnalysisData
 	dataMutex     sync.RWMutex
 	filePath      string
+	vault         *storage.Vault
 	llmClient     *JoinlyClient
 	llmProvider   llm.LLMProvider
-	lastAnalysis  time.Time
+	lastAnalysis  time.Time // Guarded by dataMutex
 	analysisMutex sync.Mutex
+	pendingConfig *models.AgentConfig // Configuration update applied before the next analysis pass
+	configMutex   sync.Mutex
+	discarded     bool // Set by Discard; guarded by both analysisMutex and dataMutex
 }
 
 // NewAnalystAgent creates a new analyst agent
 func NewAnalystAgent(agentID string, config models.AgentConfig, llmClient *JoinlyClient) *AnalystAgent {
+	analysisStorageMu.RLock()
+	dataDir, vault := analysisDir, analysisVault
+	analysisStorageMu.RUnlock()
+
 	// Create data directory if it doesn't exist
-	dataDir := "data/analysis"
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
+	if err := vault.MkdirAll(dataDir); err != nil {
 		logrus.Errorf("Failed to create analysis data directory: %v", err)
 	}
 
 	fileName := fmt.Sprintf("meeting_analysis_%s_%d.json", agentID, time.Now().Unix())
 	filePath := filepath.Join(dataDir, fileName)
 
-	// Get LLM provider for structured responses
-	llmProvider, err := llm.GetProvider(string(config.LLMProvider), config.LLMModel)
-	if err != nil {
+	// Get LLM provider chain for structured responses
+	var llmProvider llm.LLMProvider
+	if chain, err := newLLMChain(config, llmClient.Redactor()); err != nil {
 		logrus.Errorf("Failed to get LLM provider for analyst %s: %v", agentID, err)
-		llmProvider = nil
+	} else {
+		llmProvider = chain
 	}
 
 	analyst := &AnalystAgent{
 		agentID:     agentID,
 		config:      config,
 		filePath:    filePath,
+		vault:       vault,
 		llmClient:   llmClient,
 		llmProvider: llmProvider,
 		data: &AnalysisData{
@@ -199,6 +236,18 @@ func NewAnalystAgent(agentID string, config models.AgentConfig, llmClient *Joinl
 
 // ProcessUtterance processes a new utterance and updates the analysis
 func (a *AnalystAgent) ProcessUtterance(segments []map[string]interface{}) {
+	a.addUtterance(segments, true)
+}
+
+// RecordUtterance adds a new utterance to the transcript without starting an analysis pass,
+// e.g. while the agent's LLM budget is spent
+func (a *AnalystAgent) RecordUtterance(segments []map[string]interface{}) {
+	a.addUtterance(segments, false)
+}
+
+// addUtterance adds an utterance to the transcript and, when analyze is set, starts an analysis
+// pass once enough has been said
+func (a *AnalystAgent) addUtterance(segments []map[string]interface{}, analyze bool) {
 	if len(segments) == 0 {
 		return
 	}
@@ -206,6 +255,10 @@ func (a *AnalystAgent) ProcessUtterance(segments []map[string]interface{}) {
 	a.dataMutex.Lock()
 	defer a.dataMutex.Unlock()
 
+	if a.discarded {
+		return
+	}
+
 	// Extract transcript text and speaker
 	var fullText strings.Builder
 	speaker := "Participant"
@@ -231,6 +284,9 @@ func (a *AnalystAgent) ProcessUtterance(segments []map[string]interface{}) {
 		return
 	}
 
+	// Speakers' names are personal data too
+	a.llmClient.Redactor().AddName(speaker)
+
 	// Add to transcript
 	entry := TranscriptEntry{
 		Timestamp: timestamp,
@@ -254,11 +310,45 @@ func (a *AnalystAgent) ProcessUtterance(segments []map[string]interface{}) {
 	}
 
 	// Trigger analysis update if enough time has passed (every 5 minutes or significant new content)
-	if time.Since(a.lastAnalysis) > 5*time.Minute || len(a.data.Transcript)%10 == 0 {
+	if analyze && (time.Since(a.lastAnalysis) > 5*time.Minute || len(a.data.Transcript)%10 == 0) {
 		go a.updateAnalysis()
 	}
 }
 
+// UpdateConfig schedules a configuration change (prompts, LLM provider/model). It takes
+// effect at the start of the next analysis pass so that a running pass is not disturbed.
+func (a *AnalystAgent) UpdateConfig(config models.AgentConfig) {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
+	a.pendingConfig = &config
+}
+
+// applyPendingConfig swaps in a scheduled configuration (caller must hold analysisMutex)
+func (a *AnalystAgent) applyPendingConfig() {
+	a.configMutex.Lock()
+	pending := a.pendingConfig
+	a.pendingConfig = nil
+	a.configMutex.Unlock()
+
+	if pending == nil {
+		return
+	}
+
+	if llmConfigChanged(a.config, *pending) {
+		var llmProvider llm.LLMProvider
+		if chain, err := newLLMChain(*pending, a.llmClient.Redactor()); err != nil {
+			logrus.Errorf("Failed to get LLM provider for analyst %s: %v", a.agentID, err)
+		} else {
+			llmProvider = chain
+		}
+		a.llmProvider = llmProvider
+	}
+
+	a.config = *pending
+	logrus.Infof("Applied configuration update for analyst %s", a.agentID)
+}
+
 // updateParticipants adds a speaker to the participants list if not already present
 func (a *AnalystAgent) updateParticipants(speaker string) {
 	for _, p := range a.data.Participants {
@@ -269,14 +359,24 @@ func (a *AnalystAgent) updateParticipants(speaker string) {
 	a.data.Participants = append(a.data.Participants, speaker)
 }
 
+// AnalyzeNow runs an analysis pass over the whole transcript and waits for it, e.g. when a
+// meeting ends before the next periodic pass
+func (a *AnalystAgent) AnalyzeNow() {
+	a.updateAnalysis()
+}
+
 // updateAnalysis performs comprehensive analysis using LLM
 func (a *AnalystAgent) updateAnalysis() {
 	a.analysisMutex.Lock()
 	defer a.analysisMutex.Unlock()
 
-	a.lastAnalysis = time.Now()
+	a.applyPendingConfig()
 
-	if len(a.data.Transcript) == 0 {
+	a.dataMutex.Lock()
+	a.lastAnalysis = time.Now()
+	empty := a.discarded || len(a.data.Transcript) == 0
+	a.dataMutex.Unlock()
+	if empty {
 		return
 	}
 
@@ -308,10 +408,12 @@ func (a *AnalystAgent) updateAnalysis() {
 	}
 
 	// Save the updated analysis
+	a.dataMutex.Lock()
 	a.data.LastUpdated = time.Now()
 	if err := a.saveAnalysis(); err != nil {
 		logrus.Errorf("Failed to save updated analysis for agent %s: %v", a.agentID, err)
 	}
+	a.dataMutex.Unlock()
 
 	logrus.Infof("Analysis updated for agent %s", a.agentID)
 }
@@ -353,14 +455,16 @@ Provide a clear, concise summary and identify the main themes discussed.`,
 			Summary   string   `json:"summary"`
 			KeyThemes []string `json:"key_themes"`
 		}
+		summary := response
 		if err := json.Unmarshal([]byte(response), &result); err == nil {
-			a.data.Summary = result.Summary
+			summary = result.Summary
 			// Could store key themes separately if needed
 			_ = result.KeyThemes
-		} else {
-			// Fallback to using response as-is
-			a.data.Summary = response
 		}
+		// Otherwise fall back to using response as-is
+		a.dataMutex.Lock()
+		a.data.Summary = summary
+		a.dataMutex.Unlock()
 	}
 	return nil
 }
@@ -401,12 +505,12 @@ Transcript:
 		var result struct {
 			KeyPoints []string `json:"key_points"`
 		}
+		var keyPoints []string
 		if err := json.Unmarshal([]byte(response), &result); err == nil {
-			a.data.KeyPoints = result.KeyPoints
+			keyPoints = result.KeyPoints
 		} else {
 			// Fallback to parsing bullet points from text response
 			lines := strings.Split(response, "\n")
-			var keyPoints []string
 			for _, line := range lines {
 				line = strings.TrimSpace(line)
 				if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "• ") {
@@ -415,8 +519,10 @@ Transcript:
 					keyPoints = append(keyPoints, line)
 				}
 			}
-			a.data.KeyPoints = keyPoints
 		}
+		a.dataMutex.Lock()
+		a.data.KeyPoints = keyPoints
+		a.dataMutex.Unlock()
 	}
 	return nil
 }
@@ -469,6 +575,8 @@ Transcript:
 		var result struct {
 			ActionItems []ActionItem `json:"action_items"`
 		}
+		a.dataMutex.Lock()
+		defer a.dataMutex.Unlock()
 		if err := json.Unmarshal([]byte(response), &result); err == nil && len(result.ActionItems) > 0 {
 			// Validate and add structured action items
 			for _, item := range result.ActionItems {
@@ -540,12 +648,12 @@ Transcript:
 			Topics []TopicDiscussion `json:"topics"`
 		}
 		if err := json.Unmarshal([]byte(response), &result); err == nil {
-			a.data.Topics = result.Topics
+			a.setTopics(result.Topics)
 		} else {
 			// Fallback to old parsing
 			var topics []TopicDiscussion
 			if err := json.Unmarshal([]byte(response), &topics); err == nil {
-				a.data.Topics = topics
+				a.setTopics(topics)
 			} else {
 				logrus.Warnf("Failed to parse topics response: %v", err)
 			}
@@ -589,26 +697,40 @@ Transcript:
 			Confidence float64  `json:"confidence"`
 		}
 		if err := json.Unmarshal([]byte(response), &analysis); err == nil {
-			a.data.Sentiment = analysis.Sentiment
-			a.data.Keywords = analysis.Keywords
+			a.setSentiment(analysis.Sentiment, analysis.Keywords)
 		} else {
 			// Fallback to old parsing
 			if err := json.Unmarshal([]byte(response), &analysis); err != nil {
 				logrus.Warnf("Failed to parse sentiment analysis: %v", err)
 			} else {
-				a.data.Sentiment = analysis.Sentiment
-				a.data.Keywords = analysis.Keywords
+				a.setSentiment(analysis.Sentiment, analysis.Keywords)
 			}
 		}
 	}
 	return nil
 }
 
+// setTopics replaces the discussion topics of the analysis
+func (a *AnalystAgent) setTopics(topics []TopicDiscussion) {
+	a.dataMutex.Lock()
+	defer a.dataMutex.Unlock()
+	a.data.Topics = topics
+}
+
+// setSentiment replaces the sentiment and keywords of the analysis
+func (a *AnalystAgent) setSentiment(sentiment string, keywords []string) {
+	a.dataMutex.Lock()
+	defer a.dataMutex.Unlock()
+	a.data.Sentiment = sentiment
+	a.data.Keywords = keywords
+}
+
 // Schema creation methods
 
 // getSummarySchema returns the schema for meeting summary generation
 func (a *AnalystAgent) getSummarySchema() *llm.ResponseSchema {
 	return &llm.ResponseSchema{
+		Name: "summary",
 		Type: "OBJECT",
 		Properties: map[string]interface{}{
 			"summary": map[string]interface{}{
@@ -628,6 +750,7 @@ func (a *AnalystAgent) getSummarySchema() *llm.ResponseSchema {
 // getKeyPointsSchema returns the schema for key points extraction
 func (a *AnalystAgent) getKeyPointsSchema() *llm.ResponseSchema {
 	return &llm.ResponseSchema{
+		Name: "key_points",
 		Type: "OBJECT",
 		Properties: map[string]interface{}{
 			"key_points": map[string]interface{}{
@@ -643,6 +766,7 @@ func (a *AnalystAgent) getKeyPointsSchema() *llm.ResponseSchema {
 // getActionItemsSchema returns the schema for action items identification
 func (a *AnalystAgent) getActionItemsSchema() *llm.ResponseSchema {
 	return &llm.ResponseSchema{
+		Name: "action_items",
 		Type: "OBJECT",
 		Properties: map[string]interface{}{
 			"action_items": map[string]interface{}{
@@ -679,6 +803,7 @@ func (a *AnalystAgent) getActionItemsSchema() *llm.ResponseSchema {
 // getTopicsSchema returns the schema for topic extraction
 func (a *AnalystAgent) getTopicsSchema() *llm.ResponseSchema {
 	return &llm.ResponseSchema{
+		Name: "topics",
 		Type: "OBJECT",
 		Properties: map[string]interface{}{
 			"topics": map[string]interface{}{
@@ -719,6 +844,7 @@ func (a *AnalystAgent) getTopicsSchema() *llm.ResponseSchema {
 // getSentimentSchema returns the schema for sentiment and keyword analysis
 func (a *AnalystAgent) getSentimentSchema() *llm.ResponseSchema {
 	return &llm.ResponseSchema{
+		Name: "sentiment",
 		Type: "OBJECT",
 		Properties: map[string]interface{}{
 			"sentiment": map[string]interface{}{
@@ -752,22 +878,34 @@ func (a *AnalystAgent) callLLMWithSchema(prompt string, schema *llm.ResponseSche
 		return "", fmt.Errorf("LLM provider not available")
 	}
 
-	return a.llmProvider.CallWithSchema(prompt, schema)
+	response, err := a.llmProvider.CallWithSchema(a.callContext(), prompt, schema)
+	a.llmClient.recordLLMCall(response, err)
+
+	return response.Text, err
 }
 
-// getRecentTranscript returns the last N transcript entries
-func (a *AnalystAgent) getRecentTranscript(count int) []TranscriptEntry {
-	total := len(a.data.Transcript)
-	if total == 0 {
-		return []TranscriptEntry{}
+// callContext returns the context LLM calls run under: the client's, so that stopping
+// the agent abandons in-flight analysis calls
+func (a *AnalystAgent) callContext() context.Context {
+	if a.llmClient != nil && a.llmClient.ctx != nil {
+		return a.llmClient.ctx
 	}
+	return context.Background()
+}
+
+// getRecentTranscript returns a copy of the last N transcript entries
+func (a *AnalystAgent) getRecentTranscript(count int) []TranscriptEntry {
+	a.dataMutex.RLock()
+	defer a.dataMutex.RUnlock()
 
-	start := total - count
+	start := len(a.data.Transcript) - count
 	if start < 0 {
 		start = 0
 	}
 
-	return a.data.Transcript[start:]
+	recent := make([]TranscriptEntry, len(a.data.Transcript)-start)
+	copy(recent, a.data.Transcript[start:])
+	return recent
 }
 
 // formatTranscriptForLLM formats transcript entries for LLM consumption
@@ -782,7 +920,8 @@ func (a *AnalystAgent) formatTranscriptForLLM(entries []TranscriptEntry) string
 	return result.String()
 }
 
-// actionItemExists checks if an action item with similar description already exists
+// actionItemExists checks if an action item with similar description already exists (caller
+// must hold dataMutex)
 func (a *AnalystAgent) actionItemExists(description string) bool {
 	for _, item := range a.data.ActionItems {
 		if strings.Contains(strings.ToLower(item.Description), strings.ToLower(description)) ||
@@ -1142,13 +1281,14 @@ Generate specific instructions for how this agent should approach %s in meetings
 Keep the response focused and professional, as these instructions will be used directly in LLM prompts.`, personality, taskDescription)
 
 	// Use the same LLM provider as configured for the agent
-	response, err := a.llmProvider.Call(prompt)
+	response, err := a.llmProvider.Call(a.callContext(), prompt)
+	a.llmClient.recordLLMCall(response, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate task prompt: %w", err)
 	}
 
 	// Clean up the response
-	taskPrompt := strings.TrimSpace(response)
+	taskPrompt := strings.TrimSpace(response.Text)
 	if taskPrompt == "" {
 		return "", fmt.Errorf("empty task prompt generated")
 	}
@@ -1306,14 +1446,14 @@ func (a *AnalystAgent) isValidActionItem(item ActionItem) bool {
 
 // File operations
 
-// saveAnalysis saves the analysis data to file
+// saveAnalysis saves the analysis data to file, redacted unless the agent's policy persists raw text
 func (a *AnalystAgent) saveAnalysis() error {
-	data, err := json.MarshalIndent(a.data, "", "  ")
+	data, err := json.MarshalIndent(a.storedAnalysisUnsafe(), "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal analysis data: %w", err)
 	}
 
-	return os.WriteFile(a.filePath, data, 0644)
+	return a.vault.WriteFile(a.filePath, data)
 }
 
 // loadAnalysis loads analysis data from file
@@ -1322,7 +1462,7 @@ func (a *AnalystAgent) loadAnalysis() error {
 		return nil // File doesn't exist, will create new
 	}
 
-	data, err := os.ReadFile(a.filePath)
+	data, err := a.vault.ReadFile(a.filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read analysis file: %w", err)
 	}
@@ -1330,12 +1470,92 @@ func (a *AnalystAgent) loadAnalysis() error {
 	return json.Unmarshal(data, a.data)
 }
 
+// Discard stops the analysis and deletes it, in memory and on disk, e.g. when a participant
+// objected to the meeting being analysed. It waits for a running analysis pass.
+func (a *AnalystAgent) Discard() {
+	a.analysisMutex.Lock()
+	defer a.analysisMutex.Unlock()
+	a.dataMutex.Lock()
+	defer a.dataMutex.Unlock()
+
+	a.discarded = true
+	a.data = &AnalysisData{
+		MeetingID:    a.data.MeetingID,
+		MeetingURL:   a.data.MeetingURL,
+		StartTime:    a.data.StartTime,
+		LastUpdated:  time.Now(),
+		Transcript:   []TranscriptEntry{},
+		KeyPoints:    []string{},
+		ActionItems:  []ActionItem{},
+		Topics:       []TopicDiscussion{},
+		Participants: []string{},
+	}
+	if err := os.Remove(a.filePath); err != nil && !os.IsNotExist(err) {
+		logrus.Errorf("Failed to delete analysis of agent %s: %v", a.agentID, err)
+	}
+}
+
+// FilePath returns the file the analysis is saved to
+func (a *AnalystAgent) FilePath() string {
+	return a.filePath
+}
+
 // GetAnalysis returns a copy of the current analysis data
 func (a *AnalystAgent) GetAnalysis() *AnalysisData {
 	a.dataMutex.RLock()
 	defer a.dataMutex.RUnlock()
 
-	// Create a deep copy
+	return a.copyAnalysisUnsafe()
+}
+
+// StoredAnalysis returns the analysis as it is written to disk: with personal data redacted,
+// unless the agent's policy persists raw text
+func (a *AnalystAgent) StoredAnalysis() *AnalysisData {
+	a.dataMutex.RLock()
+	defer a.dataMutex.RUnlock()
+
+	return a.storedAnalysisUnsafe()
+}
+
+// storedAnalysisUnsafe returns a copy of the analysis with every text passed through the
+// agent's storage redaction (caller must hold lock)
+func (a *AnalystAgent) storedAnalysisUnsafe() *AnalysisData {
+	redactor := a.llmClient.Redactor()
+	data := a.copyAnalysisUnsafe()
+	if redactor.PersistsRaw() {
+		return data
+	}
+
+	redactAll := func(texts []string) []string {
+		redacted := make([]string, len(texts))
+		for i, text := range texts {
+			redacted[i] = redactor.ForStorage(text)
+		}
+		return redacted
+	}
+
+	for i := range data.Transcript {
+		data.Transcript[i].Speaker = redactor.ForStorage(data.Transcript[i].Speaker)
+		data.Transcript[i].Text = redactor.ForStorage(data.Transcript[i].Text)
+	}
+	data.Summary = redactor.ForStorage(data.Summary)
+	data.KeyPoints = redactAll(data.KeyPoints)
+	for i := range data.ActionItems {
+		data.ActionItems[i].Description = redactor.ForStorage(data.ActionItems[i].Description)
+		data.ActionItems[i].Assignee = redactor.ForStorage(data.ActionItems[i].Assignee)
+	}
+	for i := range data.Topics {
+		data.Topics[i].Topic = redactor.ForStorage(data.Topics[i].Topic)
+		data.Topics[i].Summary = redactor.ForStorage(data.Topics[i].Summary)
+		data.Topics[i].Participants = redactAll(data.Topics[i].Participants)
+	}
+	data.Participants = redactAll(data.Participants)
+	data.Keywords = redactAll(data.Keywords)
+	return data
+}
+
+// copyAnalysisUnsafe returns a deep copy of the analysis (caller must hold lock)
+func (a *AnalystAgent) copyAnalysisUnsafe() *AnalysisData {
 	dataCopy := *a.data
 	dataCopy.Transcript = make([]TranscriptEntry, len(a.data.Transcript))
 	copy(dataCopy.Transcript, a.data.Transcript)
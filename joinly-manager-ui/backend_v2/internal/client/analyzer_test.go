@@ -8,6 +8,13 @@ import (
 	"joinly-manager/internal/models"
 )
 
+// useTempAnalysisDir makes analysts created by the test save their analyses in a temporary directory
+func useTempAnalysisDir(t *testing.T) {
+	previous := AnalysisDir()
+	ConfigureAnalysisStorage(t.TempDir(), nil)
+	t.Cleanup(func() { ConfigureAnalysisStorage(previous, nil) })
+}
+
 func TestAnalystAgent_BasicFunctionality(t *testing.T) {
 	// Create a mock client (we'll use nil for now since we're testing the analyzer logic)
 	var mockClient *JoinlyClient = nil
@@ -20,6 +27,7 @@ func TestAnalystAgent_BasicFunctionality(t *testing.T) {
 		LLMModel:         "gpt-4",
 	}
 
+	useTempAnalysisDir(t)
 	agent := NewAnalystAgent("test-agent", config, mockClient)
 
 	if agent == nil {
@@ -81,6 +89,7 @@ func TestStructuredResponseSchemas(t *testing.T) {
 		LLMModel:         "gpt-4",
 	}
 
+	useTempAnalysisDir(t)
 	agent := NewAnalystAgent("test-agent", config, mockClient)
 	if agent == nil {
 		t.Fatal("Failed to create analyst agent")
@@ -133,6 +142,7 @@ func TestAnalystAgent_MultipleUtterances(t *testing.T) {
 		LLMModel:         "gpt-4",
 	}
 
+	useTempAnalysisDir(t)
 	agent := NewAnalystAgent("test-agent", config, mockClient)
 
 	// Process multiple utterances
@@ -1,11 +1,19 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"joinly-manager/internal/client/llm"
+	"joinly-manager/internal/metrics"
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/redact"
+	"joinly-manager/internal/tracing"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -13,113 +21,142 @@ import (
 // GenerateResponse creates a response using the configured LLM model (public method for manager)
 func (c *JoinlyClient) GenerateResponse(speaker, text string) string {
 	// No cooldown - respond immediately like Python client
-	return c.GenerateResponseWithContext(speaker, text, "")
+	return c.GenerateResponseWithContext(context.Background(), speaker, text, models.ConversationWindow{})
 }
 
-// GenerateResponseWithContext creates a context-aware response using conversation history
-func (c *JoinlyClient) GenerateResponseWithContext(speaker, text, context string) string {
-	return c.generateResponseWithContext(speaker, text, context)
+// GenerateResponseWithContext creates a context-aware response using conversation history.
+// ctx carries the utterance trace the LLM call is recorded under.
+func (c *JoinlyClient) GenerateResponseWithContext(ctx context.Context, speaker, text string, window models.ConversationWindow) string {
+	return c.generateResponseWithContext(ctx, speaker, text, window)
 }
 
 // generateResponseWithContext creates a context-aware response using the configured LLM model (internal method)
-func (c *JoinlyClient) generateResponseWithContext(speaker, text, context string) string {
-	// Check if we have the necessary configuration for LLM calls
-	if c.config.LLMProvider == "" || c.config.LLMModel == "" {
-		c.log("warn", "No LLM provider/model configured, using fallback response")
-		return c.getFallbackResponse(speaker, text)
-	}
-
-	// Get the LLM provider
-	provider, err := llm.GetProvider(string(c.config.LLMProvider), c.config.LLMModel)
+func (c *JoinlyClient) generateResponseWithContext(ctx context.Context, speaker, text string, window models.ConversationWindow) string {
+	// Get the provider chain: the configured provider followed by its fallbacks
+	config, provider, err := c.currentChain()
 	if err != nil {
 		c.log("error", fmt.Sprintf("Failed to get LLM provider: %v", err))
-		return c.getFallbackResponse(speaker, text)
+		return c.cannedResponse(config, speaker, text)
 	}
 
-	// Check if API keys are available for the selected provider
+	// Check if API keys are available for any provider of the chain
 	if !provider.IsAvailable() {
-		c.log("error", fmt.Sprintf("No valid API key found for provider '%s', using fallback response", c.config.LLMProvider))
-		return c.getFallbackResponse(speaker, text)
+		c.log("error", fmt.Sprintf("No valid API key found for provider '%s' or its fallbacks", config.LLMProvider))
+		return c.cannedResponse(config, speaker, text)
 	}
 
 	// Generate response using the configured LLM
-	response, err := c.callLLMWithContext(speaker, text, context, provider)
+	c.setUtteranceState(ctx, "sent_to_llm")
+	_, endStage := tracing.StartStage(ctx, tracing.StageLLM,
+		attribute.String("llm.provider", string(config.LLMProvider)),
+		attribute.String("llm.model", config.LLMModel),
+	)
+	response, err := c.callLLMWithContext(ctx, config, speaker, text, window, provider)
+	endStage(err)
+	c.setUtteranceState(ctx, "llm_done")
+	if ctx.Err() != nil {
+		// A newer utterance superseded this one; its reply would be stale
+		c.log("debug", "LLM call cancelled")
+		return ""
+	}
 	if err != nil {
-		c.log("error", fmt.Sprintf("Failed to generate LLM response: %v, using fallback", err))
-		return c.getFallbackResponse(speaker, text)
+		c.log("error", fmt.Sprintf("Failed to generate LLM response: %v", err))
+		return c.cannedResponse(config, speaker, text)
 	}
 
 	return response
 }
 
-// callLLMWithContext makes an actual API call to the configured LLM with conversation context
-func (c *JoinlyClient) callLLMWithContext(speaker, text, context string, provider llm.LLMProvider) (string, error) {
-	var prompt string
-
-	// Use custom prompt if provided, otherwise use default behavior
-	if c.config.CustomPrompt != nil && *c.config.CustomPrompt != "" {
-		// Custom prompt template - replace placeholders
-		prompt = *c.config.CustomPrompt
-		prompt = strings.ReplaceAll(prompt, "{agent_name}", c.config.Name)
-		prompt = strings.ReplaceAll(prompt, "{speaker}", speaker)
-		prompt = strings.ReplaceAll(prompt, "{text}", text)
-		if context != "" && context != "No previous context." {
-			prompt = strings.ReplaceAll(prompt, "{context}", context)
-		} else {
-			prompt = strings.ReplaceAll(prompt, "{context}", "No previous context.")
-		}
-	} else if context != "" && context != "No previous context." {
-		// Default prompt with conversation context
-		prompt = fmt.Sprintf(`You are a helpful AI assistant named %s participating in a meeting.
-
-Conversation history:
-%s
-
-Current: A participant named %s just said: "%s"
-
-Please respond naturally and helpfully, considering the conversation history. Keep your response concise and conversational.
-
-You must respond ONLY with valid JSON in the following format:
-{
-  "assistant_reply": "<Your actual response to speak to the user>",
-  "metadata": {
-    "topic": "<Optional: topic of the response>",
-    "confidence": <Optional: confidence score as a float>
-  }
-}`,
-			c.config.Name, context, speaker, text)
-	} else {
-		// Default prompt without context
-		prompt = fmt.Sprintf(`You are a helpful AI assistant named %s participating in a meeting.
+// newLLMChain builds an agent's LLM chain: the configured provider/model followed by its
+// fallbacks. Prompts pass through the agent's redactor, if it has one.
+func newLLMChain(config models.AgentConfig, redactor *redact.Redactor) (*llm.Chain, error) {
+	targets := []llm.Target{newLLMTarget(config.LLMProvider, config.LLMModel, config.OpenAICompatible, config.MockLLM)}
+	for _, fallback := range config.LLMFallbacks {
+		targets = append(targets, newLLMTarget(fallback.Provider, fallback.Model, fallback.OpenAICompatible, config.MockLLM))
+	}
+	chain, err := llm.NewChain(targets)
+	if err != nil {
+		return nil, err
+	}
+	return redactChain(chain, redactor), nil
+}
 
-A participant named %s just said: "%s"
+// llmConfigChanged reports whether an update changes the settings the LLM chain is built from
+func llmConfigChanged(old, updated models.AgentConfig) bool {
+	return old.LLMProvider != updated.LLMProvider ||
+		old.LLMModel != updated.LLMModel ||
+		!reflect.DeepEqual(old.LLMFallbacks, updated.LLMFallbacks) ||
+		!reflect.DeepEqual(old.OpenAICompatible, updated.OpenAICompatible) ||
+		!reflect.DeepEqual(old.MockLLM, updated.MockLLM)
+}
 
-Please respond naturally and helpfully. Keep your response concise and conversational.
+// newCheckChain builds the chain used for quick yes/no checks: the agent's provider with the
+// given cheaper model, or its own model when none is set
+func newCheckChain(config models.AgentConfig, model string, redactor *redact.Redactor) (*llm.Chain, error) {
+	if model == "" {
+		model = config.LLMModel
+	}
+	chain, err := llm.NewChain([]llm.Target{newLLMTarget(config.LLMProvider, model, config.OpenAICompatible, config.MockLLM)})
+	if err != nil {
+		return nil, err
+	}
+	return redactChain(chain, redactor), nil
+}
+
+// redactChain attaches an agent's redactor to a chain. A nil redactor is not attached, so that
+// the chain does not hold a nil pointer in its interface.
+func redactChain(chain *llm.Chain, redactor *redact.Redactor) *llm.Chain {
+	if redactor != nil {
+		chain.SetRedactor(redactor)
+	}
+	return chain
+}
 
-You must respond ONLY with valid JSON in the following format:
-{
-  "assistant_reply": "<Your actual response to speak to the user>",
-  "metadata": {
-    "topic": "<Optional: topic of the response>",
-    "confidence": <Optional: confidence score as a float>
-  }
-}`,
-			c.config.Name, speaker, text)
+// newLLMTarget converts a configured provider/model pair into a chain target. Mock targets
+// answer from the agent's scripted responses.
+func newLLMTarget(provider models.LLMProvider, model string, compatible *models.OpenAICompatibleConfig, mock *models.MockLLMConfig) llm.Target {
+	target := llm.Target{Provider: string(provider), Model: model}
+	if provider == models.LLMProviderMock && mock != nil {
+		settings := llm.MockSettings{Default: mock.Default}
+		for _, response := range mock.Responses {
+			settings.Rules = append(settings.Rules, llm.MockRule{Pattern: response.Pattern, Schema: response.Schema, Response: response.Response})
+		}
+		target.Mock = &settings
+	}
+	if compatible != nil {
+		target.Compatible = &llm.CompatibleSettings{
+			BaseURL:   compatible.BaseURL,
+			APIKeyEnv: compatible.APIKeyEnv,
+			Headers:   compatible.Headers,
+			Capabilities: llm.Capabilities{
+				JSONSchema: compatible.SupportsJSONSchema,
+				Tools:      compatible.SupportsTools,
+				Streaming:  compatible.SupportsStreaming,
+			},
+		}
 	}
+	return target
+}
+
+// callLLMWithContext makes an actual API call to the configured LLM with the conversation as
+// chat messages. The call is abandoned when ctx is cancelled.
+func (c *JoinlyClient) callLLMWithContext(ctx context.Context, config models.AgentConfig, speaker, text string, window models.ConversationWindow, provider llm.LLMProvider) (string, error) {
+	messages := buildReplyMessages(config, speaker, text, window)
 
-	response, err := provider.Call(prompt)
+	response, err := provider.Chat(ctx, messages, nil)
+	c.recordLLMCall(response, err)
 	if err != nil {
 		return "", err
 	}
 
-	c.log("info", fmt.Sprintf("LLM response: %s", response))
+	c.log("info", fmt.Sprintf("LLM response: %s", response.Text))
 
 	// Parse JSON response to extract assistant_reply
-	assistantReply, parseErr := c.parseJSONResponse(response)
+	assistantReply, parseErr := c.parseJSONResponse(response.Text)
 	if parseErr != nil {
 		c.log("info", fmt.Sprintf("Failed to parse JSON response, using raw response: %v", parseErr))
 		// Fallback to raw response if JSON parsing fails
-		return response, nil
+		return response.Text, nil
 	}
 
 	return assistantReply, nil
@@ -138,14 +175,8 @@ func (c *JoinlyClient) parseJSONResponse(response string) (string, error) {
 
 // generateSummaryResponse generates a response for analysis purposes (no speaking)
 func (c *JoinlyClient) generateSummaryResponse(prompt string) string {
-	// Check if we have the necessary configuration for LLM calls
-	if c.config.LLMProvider == "" || c.config.LLMModel == "" {
-		c.log("warn", "No LLM provider/model configured for analysis")
-		return ""
-	}
-
-	// Get the LLM provider
-	provider, err := llm.GetProvider(string(c.config.LLMProvider), c.config.LLMModel)
+	// Get the provider chain: the configured provider followed by its fallbacks
+	config, provider, err := c.currentChain()
 	if err != nil {
 		c.log("error", fmt.Sprintf("Failed to get LLM provider for analysis: %v", err))
 		return ""
@@ -153,11 +184,12 @@ func (c *JoinlyClient) generateSummaryResponse(prompt string) string {
 
 	// Check if API keys are available for the selected provider
 	if !provider.IsAvailable() {
-		c.log("error", fmt.Sprintf("No valid API key found for provider '%s' for analysis", c.config.LLMProvider))
+		c.log("error", fmt.Sprintf("No valid API key found for provider '%s' for analysis", config.LLMProvider))
 		return ""
 	}
 
-	response, err := provider.Call(prompt)
+	response, err := provider.Call(c.ctx, prompt)
+	c.recordLLMCall(response, err)
 	if err != nil {
 		c.log("error", fmt.Sprintf("Failed to generate analysis response: %v", err))
 		return ""
@@ -165,7 +197,37 @@ func (c *JoinlyClient) generateSummaryResponse(prompt string) string {
 
 	c.log("info", fmt.Sprintf("Analysis LLM response generated successfully"))
 
-	return strings.TrimSpace(response)
+	return strings.TrimSpace(response.Text)
+}
+
+// recordLLMCall reports the tokens of a successful LLM call made for this agent, attributed
+// to the provider and model of the chain that answered. Call latency and errors are
+// recorded per attempt by the chain itself.
+func (c *JoinlyClient) recordLLMCall(response llm.Response, err error) {
+	if err != nil {
+		return
+	}
+	metrics.AddLLMTokens(response.Provider, response.Model, response.Usage.PromptTokens, response.Usage.CompletionTokens)
+
+	if c == nil || c.onUsage == nil {
+		return
+	}
+	c.onUsage(models.LLMUsage{
+		Provider:         models.LLMProvider(response.Provider),
+		Model:            response.Model,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+	})
+}
+
+// cannedResponse returns a canned reply when the agent opted into them, otherwise
+// an empty reply so the agent stays silent rather than saying something generic
+func (c *JoinlyClient) cannedResponse(config models.AgentConfig, speaker, text string) string {
+	if !config.CannedFallback {
+		return ""
+	}
+	c.log("warn", "Using canned fallback response")
+	return c.getFallbackResponse(speaker, text)
 }
 
 // getFallbackResponse provides a simple response when LLM is not available
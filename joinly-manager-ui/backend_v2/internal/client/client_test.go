@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+
+	"joinly-manager/internal/models"
+)
+
+func TestUpdateConfigRebuildsChainOnlyForLLMChanges(t *testing.T) {
+	config := models.AgentConfig{Name: "Ada", LLMProvider: models.LLMProviderOpenAI, LLMModel: "gpt-4o-mini"}
+	c := NewJoinlyClient("agent", config, "")
+	_, chain, err := c.currentChain()
+	if err != nil {
+		t.Fatalf("Failed to build chain: %v", err)
+	}
+
+	prompt := "Be brief."
+	config.CustomPrompt = &prompt
+	c.UpdateConfig(config)
+	if _, kept, _ := c.currentChain(); kept != chain {
+		t.Error("Expected the chain to be kept when only the prompt changes")
+	}
+
+	config.LLMModel = "gpt-4o"
+	c.UpdateConfig(config)
+	if _, rebuilt, _ := c.currentChain(); rebuilt == chain {
+		t.Error("Expected the chain to be rebuilt for a new model")
+	}
+}
+
+func TestRecordSegmentIgnoresDroppedSegments(t *testing.T) {
+	c := NewJoinlyClient("agent", models.AgentConfig{Name: "Ada"}, "")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := 0; i <= maxMeetingSegments; i++ {
+		c.recordSegmentUnsafe(map[string]interface{}{"text": "Hello", "speaker": "Bob"}, float64(i), false)
+	}
+	if len(c.meetingTranscript) != maxMeetingSegments || c.meetingTranscript[0].Start != 1 {
+		t.Fatalf("Expected the oldest segment dropped, got %d segments from %v", len(c.meetingTranscript), c.meetingTranscript[0].Start)
+	}
+
+	// The next poll reports the dropped segment again
+	c.recordSegmentUnsafe(map[string]interface{}{"text": "Hello", "speaker": "Bob"}, 0, false)
+	if len(c.meetingTranscript) != maxMeetingSegments || c.meetingTranscript[0].Start != 1 {
+		t.Errorf("Expected the dropped segment not to be recorded again, first segment starts at %v", c.meetingTranscript[0].Start)
+	}
+}
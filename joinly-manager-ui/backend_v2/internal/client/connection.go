@@ -8,7 +8,9 @@ import (
 	"sync"
 	"time"
 
+	"joinly-manager/internal/client/llm"
 	"joinly-manager/internal/models"
+	"joinly-manager/internal/redact"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
@@ -38,23 +40,62 @@ type JoinlyClient struct {
 	lastSegmentStart   float64
 
 	// Utterance callback system (like Python client)
-	utteranceCallbacks []func([]map[string]interface{})
+	// The context carries the utterance trace (see tracing.UtteranceFromContext)
+	utteranceCallbacks []func(context.Context, []map[string]interface{})
 
 	// Enhanced utterance processing for seamless speech handling
 	pendingSegments   []map[string]interface{}
+	pendingSince      time.Time // Arrival of the oldest pending segment
 	lastUtteranceTime time.Time
-	utteranceDebounce time.Duration
 	debounceTimer     *time.Timer
 
+	// Addressing of name-triggered agents: whether the pending segments are directed at the
+	// agent or await the LLM check, and when the agent last finished a reply
+	pendingAddressed  bool
+	pendingNeedsCheck bool
+	lastReplyAt       time.Time
+
+	// Barge-in: speakCancel stops the reply being spoken, bargedIn records that it was used and
+	// speechMuted is closed once the agent has been muted to cut the current chunk short
+	speakCancel context.CancelFunc
+	bargedIn    bool
+	speechMuted chan struct{}
+
 	// Deduplication tracking for assistant segments
 	processedSegments map[string]bool
 
+	// Every segment of the meeting, participant and agent, kept for transcript export. Segments
+	// starting at or before droppedThrough were dropped for size and are not recorded again.
+	meetingTranscript []models.MeetingSegment
+	recordedSegments  map[string]bool
+	droppedThrough    float64
+	droppedAny        bool
+
 	// Utterance lifecycle tracking: hash -> state (received|sent_to_llm|llm_done|delivered)
 	utteranceStates map[string]string
 
+	// Consent workflow, started by Disclose: utterances are held until the objection window after
+	// the disclosure has passed; once a participant objected the meeting is no longer processed
+	consentActive bool
+	consentHold   bool
+	consentTimer  *time.Timer
+	objected      bool
+
+	// Redaction of personal data in LLM prompts and stored files (nil when the agent has no policy)
+	redactor *redact.Redactor
+
+	// LLM chain of the configured provider, model and fallbacks, rebuilt by UpdateConfig when
+	// they change
+	llmChain    *llm.Chain
+	llmChainErr error
+
 	// Callbacks for events
 	onStatusChange func(status models.AgentStatus)
 	onLogEntry     func(level, message string)
+	onLatency      func(latency models.UtteranceLatency)
+	onUsage        func(usage models.LLMUsage)
+	isSilenced     func() bool
+	onObjection    func(objection models.ConsentObjection)
 }
 
 // NewJoinlyClient creates a new Joinly MCP client
@@ -70,14 +111,24 @@ func NewJoinlyClient(id string, config models.AgentConfig, serverURL string) *Jo
 		lastUtteranceStart: 0.0,
 		lastSegmentStart:   0.0,
 		pendingSegments:    make([]map[string]interface{}, 0),
-		utteranceDebounce:  2 * time.Second, // Wait 3 seconds for utterance completion
 		processedSegments:  make(map[string]bool),
+		recordedSegments:   make(map[string]bool),
 		utteranceStates:    make(map[string]string),
+		redactor:           redact.New(config.Redaction, config.Name),
 	}
+	client.llmChain, client.llmChainErr = newLLMChain(config, client.redactor)
 
 	return client
 }
 
+// Redactor returns the agent's redactor, or nil when its text is not redacted
+func (c *JoinlyClient) Redactor() *redact.Redactor {
+	if c == nil {
+		return nil
+	}
+	return c.redactor
+}
+
 // SetStatusChangeCallback sets the callback for status changes
 func (c *JoinlyClient) SetStatusChangeCallback(callback func(models.AgentStatus)) {
 	c.onStatusChange = callback
@@ -88,8 +139,76 @@ func (c *JoinlyClient) SetLogCallback(callback func(string, string)) {
 	c.onLogEntry = callback
 }
 
+// UpdateConfig applies hot-reloadable settings to a running client. Settings sent to the
+// joinly server in the joinly-settings header (name, language, STT/TTS/VAD) are fixed for
+// the lifetime of the connection and only change after a restart.
+func (c *JoinlyClient) UpdateConfig(config models.AgentConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if llmConfigChanged(c.config, config) {
+		c.llmChain, c.llmChainErr = newLLMChain(config, c.redactor)
+	}
+
+	c.config.CustomPrompt = config.CustomPrompt
+	c.config.PersonalityPrompt = config.PersonalityPrompt
+	c.config.LLMProvider = config.LLMProvider
+	c.config.LLMModel = config.LLMModel
+	c.config.NameTrigger = config.NameTrigger
+	c.config.ConversationMode = config.ConversationMode
+	c.config.LLMFallbacks = config.LLMFallbacks
+	c.config.CannedFallback = config.CannedFallback
+	c.config.OpenAICompatible = config.OpenAICompatible
+	c.config.MockLLM = config.MockLLM
+	c.config.Tenant = config.Tenant
+	c.config.Budget = config.Budget
+	c.config.SystemPrompt = config.SystemPrompt
+	c.config.ContextTokenBudget = config.ContextTokenBudget
+	c.config.Identity = config.Identity
+	c.config.MeetingSeries = config.MeetingSeries
+	c.config.KnowledgeBases = config.KnowledgeBases
+	c.config.TurnTaking = config.TurnTaking
+	c.config.Addressing = config.Addressing
+	c.config.Interjection = config.Interjection
+}
+
+// currentConfig returns a snapshot of the client configuration
+func (c *JoinlyClient) currentConfig() models.AgentConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
+
+// currentChain returns a snapshot of the client configuration together with its LLM chain
+func (c *JoinlyClient) currentChain() (models.AgentConfig, *llm.Chain, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config, c.llmChain, c.llmChainErr
+}
+
+// SetLatencyCallback sets the callback receiving the latency breakdown of finished utterances
+func (c *JoinlyClient) SetLatencyCallback(callback func(models.UtteranceLatency)) {
+	c.onLatency = callback
+}
+
+// SetUsageCallback sets the callback receiving the token usage of every successful LLM call
+func (c *JoinlyClient) SetUsageCallback(callback func(models.LLMUsage)) {
+	c.onUsage = callback
+}
+
+// SetSilenceCheck sets the check reporting whether the agent's spent budget forbids further
+// LLM calls; the client then skips its own paid checks
+func (c *JoinlyClient) SetSilenceCheck(check func() bool) {
+	c.isSilenced = check
+}
+
+// silenced reports whether the agent may not make LLM calls
+func (c *JoinlyClient) silenced() bool {
+	return c.isSilenced != nil && c.isSilenced()
+}
+
 // AddUtteranceCallback adds a callback for utterance events (like Python client)
-func (c *JoinlyClient) AddUtteranceCallback(callback func([]map[string]interface{})) {
+func (c *JoinlyClient) AddUtteranceCallback(callback func(context.Context, []map[string]interface{})) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.utteranceCallbacks = append(c.utteranceCallbacks, callback)
@@ -290,15 +409,12 @@ func (c *JoinlyClient) Stop() error {
 	// Clear pending segments
 	c.pendingSegments = make([]map[string]interface{}, 0)
 
-	// Leave meeting if joined (non-blocking)
-	if c.isJoined {
-		go func() {
-			if err := c.leaveMeetingUnsafe(); err != nil {
-				c.log("warn", fmt.Sprintf("Failed to leave meeting during stop: %v", err))
-			}
-		}()
-		c.isJoined = false
+	// Leave meeting if joined (non-blocking); the MCP client is handed over and closed after leaving
+	if c.isJoined && c.client != nil {
+		go c.leaveAndClose(c.client)
+		c.client = nil
 	}
+	c.isJoined = false
 
 	// Cancel context to stop all operations (including resource handler)
 	c.cancel()
@@ -323,6 +439,26 @@ func (c *JoinlyClient) Stop() error {
 	return nil
 }
 
+// leaveAndClose leaves the meeting through an MCP client the client no longer owns and closes it
+func (c *JoinlyClient) leaveAndClose(mcpClient *client.Client) {
+	defer mcpClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := mcpClient.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "leave_meeting",
+			Arguments: map[string]string{},
+		},
+	})
+	if err == nil && result.IsError {
+		err = fmt.Errorf("leave meeting tool returned an error")
+	}
+	if err != nil {
+		c.log("warn", fmt.Sprintf("Failed to leave meeting during stop: %v", err))
+	}
+}
+
 // GetStatus returns the current client status
 func (c *JoinlyClient) GetStatus() models.AgentStatus {
 	c.mu.RLock()
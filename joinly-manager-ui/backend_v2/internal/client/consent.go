@@ -0,0 +1,321 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"joinly-manager/internal/models"
+)
+
+// DefaultObjectionPhrases are what participants say or type to object to an agent listening
+var DefaultObjectionPhrases = []string{"stop recording", "do not record", "don't record"}
+
+// ChatPollInterval is how often the meeting chat is read for objections
+const ChatPollInterval = 2 * time.Second
+
+// Sources of an objection
+const (
+	ObjectionSourceSpeech = "speech"
+	ObjectionSourceChat   = "chat"
+)
+
+// chatMessage is a meeting chat message as joinly reports it
+type chatMessage struct {
+	Text   string `json:"text"`
+	Sender string `json:"sender"`
+}
+
+// ObjectionPhrases returns the phrases participants object with under a consent policy
+func ObjectionPhrases(config *models.ConsentConfig) []string {
+	if config == nil || len(config.ObjectionPhrases) == 0 {
+		return DefaultObjectionPhrases
+	}
+	return config.ObjectionPhrases
+}
+
+// DisclosureMessage returns the disclosure an agent announces after joining
+func DisclosureMessage(config models.AgentConfig) string {
+	message := ""
+	if config.Consent != nil {
+		message = config.Consent.Message
+	}
+	if strings.TrimSpace(message) == "" {
+		message = fmt.Sprintf("Hi, I'm {name}, an AI assistant. I transcribe and analyse this meeting. "+
+			"If you object, say or type %q and I will delete what I captured and leave.", ObjectionPhrases(config.Consent)[0])
+	}
+	return strings.ReplaceAll(message, "{name}", config.Name)
+}
+
+// matchObjection returns the objection phrase contained in text, if any. Phrases match whole
+// words, ignoring case and punctuation.
+func matchObjection(phrases []string, text string) (string, bool) {
+	words := " " + strings.Join(addressingWords(text), " ") + " "
+	for _, phrase := range phrases {
+		phraseWords := strings.Join(addressingWords(phrase), " ")
+		if phraseWords != "" && strings.Contains(words, " "+phraseWords+" ") {
+			return phrase, true
+		}
+	}
+	return "", false
+}
+
+// SetObjectionCallback sets the callback receiving a participant's objection. It is called
+// once per meeting, after the client stopped processing the transcript.
+func (c *JoinlyClient) SetObjectionCallback(callback func(models.ConsentObjection)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onObjection = callback
+}
+
+// resetConsentUnsafe clears the consent workflow of a previous meeting when joining (caller
+// must hold lock)
+func (c *JoinlyClient) resetConsentUnsafe() {
+	c.consentActive = false
+	c.consentHold = false
+	c.objected = false
+	if c.consentTimer != nil {
+		c.consentTimer.Stop()
+		c.consentTimer = nil
+	}
+}
+
+// Disclose starts the consent workflow of the joined meeting: participants' speech and chat are
+// watched for objections, the disclosure is announced as configured and utterances are held
+// until the objection window after it has passed. A spoken disclosure a participant cut off
+// does not count; unless it was posted already, it is posted to the chat instead. Disclose fails
+// only when the disclosure could not be delivered at all.
+func (c *JoinlyClient) Disclose() error {
+	c.mu.Lock()
+	config := c.config
+	if config.Consent == nil || !c.isJoined {
+		c.mu.Unlock()
+		return nil
+	}
+	c.consentActive = true
+	c.consentHold = config.Consent.ObjectionWindowSeconds > 0
+	if c.consentHold && c.debounceTimer != nil {
+		c.debounceTimer.Stop()
+		c.debounceTimer = nil
+	}
+	c.mu.Unlock()
+	go c.watchChat()
+
+	message := DisclosureMessage(config)
+	delivery := config.Consent.Delivery
+	if delivery == "" {
+		delivery = models.ConsentDeliveryBoth
+	}
+
+	var errs []string
+	delivered, posted := false, false
+	post := func() {
+		if err := c.SendChatMessage(message); err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			delivered, posted = true, true
+		}
+	}
+	if delivery != models.ConsentDeliverySpeak {
+		post()
+	}
+	if delivery != models.ConsentDeliveryChat {
+		result, err := c.SpeakText(c.ctx, message)
+		switch {
+		case err != nil:
+			errs = append(errs, err.Error())
+		case result.Interrupted:
+			errs = append(errs, "spoken disclosure was interrupted")
+			c.log("warn", "Spoken disclosure was interrupted")
+			if !posted {
+				post()
+			}
+		default:
+			delivered = true
+		}
+	}
+	if !delivered {
+		return fmt.Errorf("failed to deliver disclosure: %s", strings.Join(errs, "; "))
+	}
+	c.log("info", "📣 Disclosure delivered")
+
+	window := time.Duration(config.Consent.ObjectionWindowSeconds) * time.Second
+	if window <= 0 {
+		return nil
+	}
+	c.log("info", fmt.Sprintf("Waiting %s for objections before listening", window))
+	c.mu.Lock()
+	if c.consentTimer != nil {
+		c.consentTimer.Stop()
+	}
+	c.consentTimer = time.AfterFunc(window, c.releaseConsentHold)
+	c.mu.Unlock()
+	return nil
+}
+
+// releaseConsentHold ends the objection window and processes the utterances held during it
+func (c *JoinlyClient) releaseConsentHold() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.consentHold || c.objected || !c.isJoined {
+		return
+	}
+	c.consentHold = false
+	c.consentTimer = nil
+	c.log("info", "No objection received, listening")
+
+	if len(c.pendingSegments) == 0 || (c.config.NameTrigger && !c.pendingAddressed && !c.pendingNeedsCheck) {
+		return
+	}
+	if c.debounceTimer != nil {
+		c.debounceTimer.Stop()
+	}
+	latestStart := c.lastSegmentStart
+	c.debounceTimer = time.AfterFunc(0, func() {
+		c.processConsolidatedUtterance(latestStart)
+	})
+}
+
+// checkObjectionUnsafe looks for an objection in a participant's text and, when it finds one,
+// stops processing the meeting and reports it (caller must hold lock). It reports whether the
+// text was an objection.
+func (c *JoinlyClient) checkObjectionUnsafe(participant, text, source string) bool {
+	if !c.consentActive || c.objected {
+		return false
+	}
+	phrase, objected := matchObjection(ObjectionPhrases(c.config.Consent), text)
+	if !objected {
+		return false
+	}
+
+	c.objected = true
+	c.consentHold = false
+	if c.consentTimer != nil {
+		c.consentTimer.Stop()
+		c.consentTimer = nil
+	}
+	if c.debounceTimer != nil {
+		c.debounceTimer.Stop()
+		c.debounceTimer = nil
+	}
+	c.pendingSegments = make([]map[string]interface{}, 0)
+	c.pendingAddressed = false
+	c.pendingNeedsCheck = false
+
+	if participant == "" {
+		participant = "Participant"
+	}
+	objection := models.ConsentObjection{Participant: participant, Source: source, Phrase: phrase, Time: time.Now()}
+	c.log("warn", fmt.Sprintf("🛑 %s objected via %s (%q)", participant, source, phrase))
+	if c.onObjection != nil {
+		go c.onObjection(objection)
+	}
+	return true
+}
+
+// PurgeTranscript forgets everything captured in the meeting so far: the meeting transcript and
+// the utterances not yet processed
+func (c *JoinlyClient) PurgeTranscript() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.debounceTimer != nil {
+		c.debounceTimer.Stop()
+		c.debounceTimer = nil
+	}
+	c.pendingSegments = make([]map[string]interface{}, 0)
+	c.meetingTranscript = nil
+	c.recordedSegments = make(map[string]bool)
+	c.processedSegments = make(map[string]bool)
+	c.utteranceStates = make(map[string]string)
+}
+
+// watchChat polls the meeting chat for objections until the client leaves the meeting or a
+// participant objected. Messages sent before the watch started are ignored.
+func (c *JoinlyClient) watchChat() {
+	ticker := time.NewTicker(ChatPollInterval)
+	defer ticker.Stop()
+
+	seen := -1
+	for {
+		c.mu.RLock()
+		active := c.isJoined && !c.objected
+		c.mu.RUnlock()
+		if !active {
+			return
+		}
+
+		messages, err := c.chatMessages()
+		if err != nil {
+			c.log("debug", fmt.Sprintf("Reading chat failed: %v", err))
+		} else {
+			if seen < 0 || seen > len(messages) {
+				seen = len(messages)
+			}
+			c.checkChatObjections(messages[seen:])
+			seen = len(messages)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkChatObjections looks for an objection among new chat messages of participants
+func (c *JoinlyClient) checkChatObjections(messages []chatMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The disclosure names an objection phrase; the agent's own messages are not objections
+	disclosure := DisclosureMessage(c.config)
+	for _, message := range messages {
+		if strings.EqualFold(strings.TrimSpace(message.Sender), c.config.Name) || strings.TrimSpace(message.Text) == disclosure {
+			continue
+		}
+		if c.checkObjectionUnsafe(message.Sender, message.Text, ObjectionSourceChat) {
+			return
+		}
+	}
+}
+
+// chatMessages returns the meeting chat history
+func (c *JoinlyClient) chatMessages() ([]chatMessage, error) {
+	c.mu.RLock()
+	mcpClient := c.client
+	c.mu.RUnlock()
+	if mcpClient == nil {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	result, err := mcpClient.CallTool(c.ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "get_chat_history",
+			Arguments: map[string]string{},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat history: %w", err)
+	}
+	if result.IsError || len(result.Content) == 0 {
+		return nil, fmt.Errorf("get chat history returned no messages")
+	}
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	if !ok {
+		return nil, fmt.Errorf("unexpected chat history content")
+	}
+
+	var history struct {
+		Messages []chatMessage `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(textContent.Text), &history); err != nil {
+		return nil, fmt.Errorf("failed to parse chat history: %w", err)
+	}
+	return history.Messages, nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/models"
+)
+
+func TestMatchObjection(t *testing.T) {
+	tests := []struct {
+		text   string
+		phrase string
+	}{
+		{"Please STOP recording!", "stop recording"},
+		{"Sorry, don't record this part.", "don't record"},
+		{"I object to this estimate, it is too low", ""},
+		{"We should stop recordings of the demo", ""},
+		{"Let's keep recording the decisions", ""},
+	}
+	for _, tt := range tests {
+		phrase, objected := matchObjection(DefaultObjectionPhrases, tt.text)
+		if phrase != tt.phrase || objected != (tt.phrase != "") {
+			t.Errorf("matchObjection(%q) = %q, %t, want %q", tt.text, phrase, objected, tt.phrase)
+		}
+	}
+}
+
+func TestDisclosureMessage(t *testing.T) {
+	config := models.AgentConfig{Name: "Ada", Consent: &models.ConsentConfig{ObjectionPhrases: []string{"no bots"}}}
+	if message := DisclosureMessage(config); !strings.Contains(message, "I'm Ada") || !strings.Contains(message, `"no bots"`) {
+		t.Errorf("unexpected default disclosure %q", message)
+	}
+
+	config.Consent.Message = "{name} transcribes this call."
+	if message := DisclosureMessage(config); message != "Ada transcribes this call." {
+		t.Errorf("unexpected disclosure %q", message)
+	}
+}
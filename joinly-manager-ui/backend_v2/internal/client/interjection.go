@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"joinly-manager/internal/client/llm"
+	"joinly-manager/internal/knowledge"
+	"joinly-manager/internal/models"
+)
+
+// factCheckTimeout bounds the factual check of the interjection policy
+const factCheckTimeout = 8 * time.Second
+
+// recentTurnsForCheck is how many recent turns the factual check sees
+const recentTurnsForCheck = 6
+
+// CheckFactualError asks the LLM whether an utterance states something clearly wrong, given the
+// conversation and knowledge passages. It returns the correction, or "" if there is nothing to
+// correct.
+func (c *JoinlyClient) CheckFactualError(ctx context.Context, speaker, text string, window models.ConversationWindow) (string, error) {
+	config := c.currentConfig()
+
+	model := ""
+	if config.Interjection != nil {
+		model = config.Interjection.CheckModel
+	}
+	provider, err := newCheckChain(config, model, c.redactor)
+	if err != nil {
+		return "", err
+	}
+
+	var background strings.Builder
+	if window.Summary != "" {
+		fmt.Fprintf(&background, "Summary of the earlier conversation: %s\n", window.Summary)
+	}
+	turns := window.Turns
+	if len(turns) > recentTurnsForCheck {
+		turns = turns[len(turns)-recentTurnsForCheck:]
+	}
+	for _, turn := range turns {
+		fmt.Fprintf(&background, "%s: %s\n", turn.Speaker, turn.Message)
+	}
+	if len(window.Passages) > 0 {
+		fmt.Fprintf(&background, "\nReference passages:\n%s\n", knowledge.FormatPassages(window.Passages))
+	}
+	if background.Len() == 0 {
+		background.WriteString("(none)")
+	}
+
+	prompt := fmt.Sprintf(`You help an AI assistant decide whether to interrupt a meeting to correct a mistake.
+Does the latest statement contain a clear factual error? Only flag errors you are certain about from
+the reference passages, the conversation or well-established general knowledge. Opinions, plans,
+guesses and questions are never errors.
+
+Context:
+%s
+Latest statement by %s: %s`, background.String(), speaker, text)
+
+	ctx, cancel := context.WithTimeout(ctx, factCheckTimeout)
+	defer cancel()
+
+	response, err := provider.CallWithSchema(ctx, prompt, factCheckSchema())
+	c.recordLLMCall(response, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for factual errors: %w", err)
+	}
+
+	var parsed struct {
+		HasError   bool   `json:"has_error"`
+		Correction string `json:"correction"`
+	}
+	if err := json.Unmarshal([]byte(response.Text), &parsed); err != nil {
+		return "", fmt.Errorf("failed to parse factual check: %w", err)
+	}
+	if !parsed.HasError {
+		return "", nil
+	}
+	return strings.TrimSpace(parsed.Correction), nil
+}
+
+// factCheckSchema returns the schema of the factual check response
+func factCheckSchema() *llm.ResponseSchema {
+	return &llm.ResponseSchema{
+		Name: "fact_check",
+		Type: "OBJECT",
+		Properties: map[string]interface{}{
+			"has_error": map[string]interface{}{
+				"type":        "BOOLEAN",
+				"description": "Whether the statement contains a clear factual error",
+			},
+			"correction": map[string]interface{}{
+				"type":        "STRING",
+				"description": "The correct fact in one sentence, if there is an error",
+			},
+		},
+		Required: []string{"has_error"},
+	}
+}
@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState is the state of a circuit breaker
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // Calls flow normally
+	breakerOpen                         // Calls are skipped until the cooldown elapses
+	breakerHalfOpen                     // One trial call decides whether to close again
+)
+
+// circuitBreaker stops calling a provider after repeated failures
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	trialRun bool
+}
+
+// breakers holds one circuit breaker per provider, shared by every agent
+var (
+	breakers   = make(map[string]*circuitBreaker)
+	breakersMu sync.Mutex
+)
+
+// breakerFor returns the circuit breaker of a provider
+func breakerFor(provider string) *circuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	breaker, exists := breakers[provider]
+	if !exists {
+		settings := currentOptions()
+		breaker = &circuitBreaker{threshold: settings.BreakerThreshold, cooldown: settings.BreakerCooldown}
+		breakers[provider] = breaker
+	}
+	return breaker
+}
+
+// Allow reports whether a call may be made now
+func (b *circuitBreaker) Allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.trialRun = true
+		return true
+	case breakerHalfOpen:
+		// Only the trial call is let through until it reports back
+		if b.trialRun {
+			return false
+		}
+		b.trialRun = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success records a successful call and closes the breaker
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+	b.trialRun = false
+}
+
+// Failure records a failed call, opening the breaker at the threshold or after a failed trial
+func (b *circuitBreaker) Failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialRun = false
+	if b.state == breakerHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = breakerOpen
+		b.openedAt = now
+	}
+}
+
+// Release gives back a trial call that was abandoned without a result
+func (b *circuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialRun = false
+}
+
+// Open reports whether the breaker is currently rejecting calls
+func (b *circuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == breakerOpen
+}
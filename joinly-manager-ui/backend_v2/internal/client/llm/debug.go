@@ -0,0 +1,168 @@
+package llm
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxCapturedExchanges is the number of exchanges kept in memory
+	maxCapturedExchanges = 50
+	// maxCapturedBody truncates captured request and response bodies
+	maxCapturedBody = 8192
+	// redacted replaces secrets in captured exchanges
+	redacted = "[REDACTED]"
+)
+
+// Exchange is a captured provider request and response with secrets redacted
+type Exchange struct {
+	Time           time.Time         `json:"time"`
+	Provider       string            `json:"provider"`
+	URL            string            `json:"url"`
+	RequestHeaders map[string]string `json:"request_headers"`
+	RequestBody    string            `json:"request_body"`
+	StatusCode     int               `json:"status_code,omitempty"`
+	ResponseBody   string            `json:"response_body,omitempty"`
+	DurationMs     float64           `json:"duration_ms"`
+	Error          string            `json:"error,omitempty"`
+}
+
+// sensitiveHeaders and sensitiveParams carry credentials and are never captured
+var (
+	sensitiveHeaders = map[string]bool{
+		"authorization":       true,
+		"proxy-authorization": true,
+		"x-api-key":           true,
+		"api-key":             true,
+		"x-goog-api-key":      true,
+	}
+	sensitiveParams = []string{"key", "api_key", "api-key", "access_token", "token"}
+
+	// secretPatterns match credentials that may appear in bodies or error messages
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`sk-[A-Za-z0-9_\-]{8,}`),
+		regexp.MustCompile(`AIza[0-9A-Za-z_\-]{20,}`),
+		regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]+`),
+	}
+)
+
+var (
+	exchanges   []Exchange
+	exchangesMu sync.Mutex
+
+	// secrets holds the API keys in use so that they are scrubbed even without a known pattern
+	secrets   = make(map[string]bool)
+	secretsMu sync.RWMutex
+)
+
+// registerSecret marks a credential value for redaction in captured exchanges
+func registerSecret(value string) {
+	if len(value) < 6 {
+		return
+	}
+	secretsMu.Lock()
+	secrets[value] = true
+	secretsMu.Unlock()
+}
+
+// Exchanges returns the captured exchanges, newest last (empty unless debug capture is enabled)
+func Exchanges() []Exchange {
+	exchangesMu.Lock()
+	defer exchangesMu.Unlock()
+
+	captured := make([]Exchange, len(exchanges))
+	copy(captured, exchanges)
+	return captured
+}
+
+// DebugCaptureEnabled reports whether provider exchanges are being captured
+func DebugCaptureEnabled() bool {
+	return currentOptions().DebugCapture
+}
+
+// captureExchange records a provider request/response when debug capture is enabled
+func captureExchange(provider string, req *http.Request, requestBody []byte, status int, responseBody []byte, duration time.Duration, err error) {
+	if !DebugCaptureEnabled() {
+		return
+	}
+
+	exchange := Exchange{
+		Time:           time.Now(),
+		Provider:       provider,
+		URL:            redactURL(req.URL),
+		RequestHeaders: make(map[string]string, len(req.Header)),
+		RequestBody:    redact(truncate(string(requestBody))),
+		StatusCode:     status,
+		ResponseBody:   redact(truncate(string(responseBody))),
+		DurationMs:     float64(duration) / float64(time.Millisecond),
+	}
+	for name := range req.Header {
+		if sensitiveHeaders[strings.ToLower(name)] {
+			exchange.RequestHeaders[name] = redacted
+		} else {
+			exchange.RequestHeaders[name] = redact(req.Header.Get(name))
+		}
+	}
+	if err != nil {
+		exchange.Error = redact(err.Error())
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"provider": provider,
+		"url":      exchange.URL,
+		"status":   status,
+		"request":  exchange.RequestBody,
+		"response": exchange.ResponseBody,
+	}).Debug("LLM exchange")
+
+	exchangesMu.Lock()
+	exchanges = append(exchanges, exchange)
+	if len(exchanges) > maxCapturedExchanges {
+		exchanges = exchanges[len(exchanges)-maxCapturedExchanges:]
+	}
+	exchangesMu.Unlock()
+}
+
+// redactURL returns the URL with credential query parameters and user info removed
+func redactURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+
+	query := clean.Query()
+	for _, param := range sensitiveParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	clean.RawQuery = query.Encode()
+
+	return redact(clean.String())
+}
+
+// redact removes known API keys and credential-looking tokens from text
+func redact(text string) string {
+	secretsMu.RLock()
+	for secret := range secrets {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	secretsMu.RUnlock()
+
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, redacted)
+	}
+	return text
+}
+
+// truncate shortens captured bodies to maxCapturedBody bytes
+func truncate(text string) string {
+	if len(text) <= maxCapturedBody {
+		return text
+	}
+	return text[:maxCapturedBody] + "…(truncated)"
+}
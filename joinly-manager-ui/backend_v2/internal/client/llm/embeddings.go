@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// OllamaEmbed returns the embedding of text from an Ollama embedding model
+func OllamaEmbed(ctx context.Context, model, text string) ([]float64, error) {
+	baseURL := providerSettings("ollama").BaseURL
+	if baseURL == "" {
+		baseURL = ollamaURLFromEnv()
+	}
+
+	body, err := postJSON(ctx, "ollama", joinURL(baseURL, "/api/embeddings"), map[string]interface{}{
+		"model":  model,
+		"prompt": text,
+	}, map[string]string{
+		"Content-Type": "application/json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama embedding: %w", err)
+	}
+	if len(parsed.Embedding) == 0 {
+		return nil, fmt.Errorf("no embedding in Ollama response")
+	}
+	return parsed.Embedding, nil
+}
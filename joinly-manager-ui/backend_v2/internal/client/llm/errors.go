@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError is returned when a provider answers with a non-200 status
+type APIError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration // Delay requested by the provider via Retry-After (0 if absent)
+	Body       string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether the request may succeed if repeated (rate limits and server errors)
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newAPIError builds an APIError from a failed HTTP response
+func newAPIError(provider string, resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Body:       string(body),
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// isRetryable reports whether an error from a provider call is worth retrying.
+// Transport errors (timeouts, resets) are retried; other non-API errors are not.
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	var transportErr *transportError
+	return errors.As(err, &transportErr)
+}
+
+// transportError marks a request that failed before a response was received
+type transportError struct {
+	err error
+}
+
+// Error implements the error interface
+func (e *transportError) Error() string {
+	return fmt.Sprintf("failed to make request: %v", e.err)
+}
+
+// Unwrap returns the underlying error
+func (e *transportError) Unwrap() error {
+	return e.err
+}
@@ -1,25 +1,26 @@
 package llm
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"sync/atomic"
-	"time"
 )
 
 // GoogleProvider implements the LLMProvider interface for Google AI
 type GoogleProvider struct {
 	model    string
+	apiKey   string
+	baseURL  string
 	apiCalls int64 // Counter for API calls
 }
 
 // NewGoogleProvider creates a new Google provider
 func NewGoogleProvider(model string) *GoogleProvider {
-	return &GoogleProvider{model: model}
+	apiKey := os.Getenv("GOOGLE_API_KEY")
+	registerSecret(apiKey)
+	return &GoogleProvider{model: model, apiKey: apiKey, baseURL: providerSettings("google").BaseURL}
 }
 
 // GetAPICallCount returns the number of API calls made
@@ -28,7 +29,7 @@ func (p *GoogleProvider) GetAPICallCount() int64 {
 }
 
 // Call makes a request to the Google AI API (backward compatibility)
-func (p *GoogleProvider) Call(prompt string) (string, error) {
+func (p *GoogleProvider) Call(ctx context.Context, prompt string) (Response, error) {
 	// Use default conversational schema
 	defaultSchema := &ResponseSchema{
 		Type: "OBJECT",
@@ -50,17 +51,22 @@ func (p *GoogleProvider) Call(prompt string) (string, error) {
 		},
 		Required: []string{"assistant_reply", "metadata"},
 	}
-	return p.CallWithSchema(prompt, defaultSchema)
+	return p.CallWithSchema(ctx, prompt, defaultSchema)
 }
 
 // CallWithSchema makes a request to the Google AI API with structured response schema
-func (p *GoogleProvider) CallWithSchema(prompt string, schema *ResponseSchema) (string, error) {
+func (p *GoogleProvider) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return p.Chat(ctx, userMessage(prompt), schema)
+}
+
+// Chat sends a conversation to the Google AI API. The system prompt becomes the system
+// instruction and assistant turns are sent with the "model" role.
+func (p *GoogleProvider) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
 	// Increment API call counter
 	atomic.AddInt64(&p.apiCalls, 1)
 
-	apiKey := os.Getenv("GOOGLE_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("GOOGLE_API_KEY not found")
+	if p.apiKey == "" {
+		return Response{}, fmt.Errorf("GOOGLE_API_KEY not found")
 	}
 
 	// Support for new Gemini models
@@ -74,16 +80,29 @@ func (p *GoogleProvider) CallWithSchema(prompt string, schema *ResponseSchema) (
 		// Default to the specified model name
 	}
 
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", modelName, apiKey)
+	// The key is sent as a header rather than a query parameter so it never shows up in URLs
+	endpoint := joinURL(p.baseURL, fmt.Sprintf("/models/%s:generateContent", modelName))
+
+	system, turns := splitSystem(messages)
+	contents := make([]map[string]interface{}, 0, len(turns))
+	for _, turn := range alternateTurns(turns) {
+		role := "user"
+		if turn.Role == RoleAssistant {
+			role = "model"
+		}
+		contents = append(contents, map[string]interface{}{
+			"role":  role,
+			"parts": []map[string]string{{"text": turn.Content}},
+		})
+	}
 
 	payload := map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"parts": []map[string]string{
-					{"text": prompt},
-				},
-			},
-		},
+		"contents": contents,
+	}
+	if system != "" {
+		payload["systemInstruction"] = map[string]interface{}{
+			"parts": []map[string]string{{"text": system}},
+		}
 	}
 
 	// Configure generation settings based on whether schema is provided
@@ -99,75 +118,43 @@ func (p *GoogleProvider) CallWithSchema(prompt string, schema *ResponseSchema) (
 
 	payload["generationConfig"] = generationConfig
 
-	result, err := p.makeHTTPCall(url, payload, map[string]string{
-		"Content-Type": "application/json",
+	body, err := postJSON(ctx, "google", endpoint, payload, map[string]string{
+		"Content-Type":   "application/json",
+		"x-goog-api-key": p.apiKey,
 	})
-
-	if err == nil {
-		// Log API call count for Gemini
-		fmt.Printf("📊 Gemini API Call #%d completed\n", p.GetAPICallCount())
+	if err != nil {
+		return Response{}, err
 	}
 
-	return result, err
+	// Log API call count for Gemini
+	fmt.Printf("📊 Gemini API Call #%d completed\n", p.GetAPICallCount())
+
+	return p.extractResponseText(body)
 }
 
 // IsAvailable checks if Google API credentials are available
 func (p *GoogleProvider) IsAvailable() bool {
-	apiKey := os.Getenv("GOOGLE_API_KEY")
 	credFile := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
-	return apiKey != "" || credFile != ""
-}
-
-// makeHTTPCall is a helper function to make HTTP calls to the Google AI API
-func (p *GoogleProvider) makeHTTPCall(url string, payload map[string]interface{}, headers map[string]string) (string, error) {
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	return p.extractResponseText(body)
+	return p.apiKey != "" || credFile != ""
 }
 
 // extractResponseText extracts the response text from Google AI API response
-func (p *GoogleProvider) extractResponseText(body []byte) (string, error) {
+func (p *GoogleProvider) extractResponseText(body []byte) (Response, error) {
 	var response map[string]interface{}
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return Response{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	usage, _ := response["usageMetadata"].(map[string]interface{})
+	tokens := parseUsage(usage, "promptTokenCount", "candidatesTokenCount")
+
 	if candidates, ok := response["candidates"].([]interface{}); ok && len(candidates) > 0 {
 		if candidate, ok := candidates[0].(map[string]interface{}); ok {
 			if content, ok := candidate["content"].(map[string]interface{}); ok {
 				if parts, ok := content["parts"].([]interface{}); ok && len(parts) > 0 {
 					if part, ok := parts[0].(map[string]interface{}); ok {
 						if text, ok := part["text"].(string); ok {
-							return text, nil
+							return Response{Text: text, Usage: tokens}, nil
 						}
 					}
 				}
@@ -175,5 +162,5 @@ func (p *GoogleProvider) extractResponseText(body []byte) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("could not extract response text from Google AI API response")
+	return Response{}, fmt.Errorf("could not extract response text from Google AI API response")
 }
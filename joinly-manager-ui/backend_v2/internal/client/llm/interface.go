@@ -1,19 +1,27 @@
 package llm
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // ResponseSchema represents a structured response schema for LLM providers
 type ResponseSchema struct {
+	Name       string                 `json:"-"` // Identifies the kind of call for mock responses and recordings
 	Type       string                 `json:"type"`
 	Properties map[string]interface{} `json:"properties,omitempty"`
 	Required   []string               `json:"required,omitempty"`
 	Items      interface{}            `json:"items,omitempty"`
 }
 
-// LLMProvider defines the interface for LLM providers
+// LLMProvider defines the interface for LLM providers. Call and CallWithSchema send a single
+// user prompt; Chat sends a system/user/assistant conversation. Responses carry the token
+// usage reported by the provider (zero when the provider does not report it). Calls are
+// abandoned as soon as ctx is cancelled.
 type LLMProvider interface {
-	Call(prompt string) (string, error)
-	CallWithSchema(prompt string, schema *ResponseSchema) (string, error)
+	Call(ctx context.Context, prompt string) (Response, error)
+	CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error)
+	Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error)
 	IsAvailable() bool
 }
 
@@ -28,6 +36,10 @@ func GetProvider(providerType, model string) (LLMProvider, error) {
 		return NewGoogleProvider(model), nil
 	case "ollama":
 		return NewOllamaProvider(model), nil
+	case ProviderOpenAICompatible:
+		return NewOpenAICompatibleProvider(model, compatibleSettingsFromEnv()), nil
+	case ProviderMock:
+		return NewMockProvider(model, MockSettings{}), nil
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", providerType)
 	}
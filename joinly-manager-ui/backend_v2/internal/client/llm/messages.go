@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Role is the author of a chat message
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+// Message is one entry of a chat conversation sent to a provider
+type Message struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+}
+
+// userMessage wraps a single prompt as a conversation
+func userMessage(prompt string) []Message {
+	return []Message{{Role: RoleUser, Content: prompt}}
+}
+
+// EstimateTokens approximates the token count of a text (about four characters per token)
+// for context window budgeting; providers report the exact count after the call
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
+
+// withSchemaInstructions inlines a response schema into the last user message for providers
+// without native structured output
+func withSchemaInstructions(messages []Message, schema *ResponseSchema) []Message {
+	if schema == nil {
+		return messages
+	}
+
+	schemaStr, _ := json.MarshalIndent(schema, "", "  ")
+	result := make([]Message, len(messages))
+	copy(result, messages)
+
+	for i := len(result) - 1; i >= 0; i-- {
+		if result[i].Role == RoleUser {
+			result[i].Content = fmt.Sprintf(`Please respond with a valid JSON object that matches this schema:
+
+%s
+
+%s
+
+Respond ONLY with the JSON object, no additional text or explanation.`, string(schemaStr), result[i].Content)
+			break
+		}
+	}
+	return result
+}
+
+// splitSystem separates the system messages, joined into one prompt, from the conversation turns
+func splitSystem(messages []Message) (string, []Message) {
+	var system []string
+	var turns []Message
+	for _, message := range messages {
+		if message.Role == RoleSystem {
+			system = append(system, message.Content)
+		} else {
+			turns = append(turns, message)
+		}
+	}
+	return strings.Join(system, "\n\n"), turns
+}
+
+// alternateTurns merges consecutive messages of the same role and makes the conversation start
+// with a user turn, as required by APIs that enforce strict user/assistant alternation
+func alternateTurns(turns []Message) []Message {
+	var result []Message
+	for _, turn := range turns {
+		if len(result) > 0 && result[len(result)-1].Role == turn.Role {
+			result[len(result)-1].Content += "\n" + turn.Content
+			continue
+		}
+		if len(result) == 0 && turn.Role != RoleUser {
+			result = append(result, Message{Role: RoleUser, Content: "(conversation start)"})
+		}
+		result = append(result, turn)
+	}
+	return result
+}
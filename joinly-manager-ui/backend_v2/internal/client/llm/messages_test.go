@@ -0,0 +1,20 @@
+package llm
+
+import "testing"
+
+func TestAlternateTurns(t *testing.T) {
+	system, turns := splitSystem([]Message{
+		{Role: RoleSystem, Content: "Be brief."},
+		{Role: RoleAssistant, Content: "Hello."},
+		{Role: RoleUser, Content: "Bob: hi"},
+		{Role: RoleUser, Content: "Carol: hey"},
+	})
+	if system != "Be brief." {
+		t.Errorf("Unexpected system prompt %q", system)
+	}
+
+	merged := alternateTurns(turns)
+	if len(merged) != 3 || merged[0].Role != RoleUser || merged[1].Role != RoleAssistant || merged[2].Content != "Bob: hi\nCarol: hey" {
+		t.Errorf("Expected a user-first alternating conversation, got %+v", merged)
+	}
+}
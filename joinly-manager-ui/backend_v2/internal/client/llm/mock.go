@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ProviderMock names the provider answering from scripted responses without any API
+const ProviderMock = "mock"
+
+// MockRule is one scripted answer of the mock provider
+type MockRule struct {
+	Pattern  string // Regular expression matched against the prompt (empty matches any)
+	Schema   string // Name of the requested response schema (empty matches any)
+	Response string
+}
+
+// MockSettings configures the answers of a mock provider
+type MockSettings struct {
+	Rules   []MockRule
+	Default string // Answer when no rule matches (empty = an empty answer of the requested shape)
+}
+
+// mockRule is a rule with its pattern compiled
+type mockRule struct {
+	MockRule
+	pattern *regexp.Regexp
+}
+
+// MockProvider implements the LLMProvider interface with deterministic scripted responses
+type MockProvider struct {
+	model    string
+	rules    []mockRule
+	fallback string
+}
+
+// NewMockProvider creates a mock provider. Rules with an invalid pattern never match.
+func NewMockProvider(model string, settings MockSettings) *MockProvider {
+	provider := &MockProvider{model: model, fallback: settings.Default}
+	for _, rule := range settings.Rules {
+		compiled := mockRule{MockRule: rule}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				continue
+			}
+			compiled.pattern = pattern
+		}
+		provider.rules = append(provider.rules, compiled)
+	}
+	return provider
+}
+
+// Call answers a prompt
+func (p *MockProvider) Call(ctx context.Context, prompt string) (Response, error) {
+	return p.CallWithSchema(ctx, prompt, nil)
+}
+
+// CallWithSchema answers a prompt with a structured response schema
+func (p *MockProvider) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return p.Chat(ctx, userMessage(prompt), schema)
+}
+
+// Chat answers a conversation with the first rule matching its prompt and schema, the
+// default answer, or an empty answer shaped like the schema
+func (p *MockProvider) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+
+	prompt := promptText(messages)
+	name := SchemaName(schema)
+
+	text, matched := p.fallback, false
+	for _, rule := range p.rules {
+		if rule.Schema != "" && rule.Schema != name {
+			continue
+		}
+		if rule.pattern != nil && !rule.pattern.MatchString(prompt) {
+			continue
+		}
+		text, matched = rule.Response, true
+		break
+	}
+	if !matched && text == "" {
+		text = emptyAnswer(schema)
+	}
+
+	return Response{
+		Text:  text,
+		Usage: Usage{PromptTokens: EstimateTokens(prompt), CompletionTokens: EstimateTokens(text)},
+	}, nil
+}
+
+// IsAvailable always reports true: the mock needs no credentials
+func (p *MockProvider) IsAvailable() bool {
+	return true
+}
+
+// SchemaName returns the name of a response schema, or "" for free-text calls
+func SchemaName(schema *ResponseSchema) string {
+	if schema == nil {
+		return ""
+	}
+	return schema.Name
+}
+
+// promptText joins the contents of a conversation, one message per line
+func promptText(messages []Message) string {
+	parts := make([]string, len(messages))
+	for i, message := range messages {
+		parts[i] = message.Content
+	}
+	return strings.Join(parts, "\n")
+}
+
+// emptyAnswer returns a JSON answer with every schema property set to its zero value, so that
+// callers parse it like a real reply. Free-text calls get an empty conversational reply.
+func emptyAnswer(schema *ResponseSchema) string {
+	var value interface{} = map[string]interface{}{"assistant_reply": ""}
+	if schema != nil {
+		value = zeroValue(map[string]interface{}{"type": schema.Type, "properties": schema.Properties})
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// zeroValue returns the zero value of a JSON schema node
+func zeroValue(node map[string]interface{}) interface{} {
+	kind, _ := node["type"].(string)
+	switch strings.ToUpper(kind) {
+	case "OBJECT":
+		object := make(map[string]interface{})
+		properties, _ := node["properties"].(map[string]interface{})
+		for name, property := range properties {
+			if child, ok := property.(map[string]interface{}); ok {
+				object[name] = zeroValue(child)
+			}
+		}
+		return object
+	case "ARRAY":
+		return []interface{}{}
+	case "BOOLEAN":
+		return false
+	case "NUMBER", "INTEGER":
+		return 0
+	default:
+		return ""
+	}
+}
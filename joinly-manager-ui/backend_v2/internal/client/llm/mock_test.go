@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/storage"
+)
+
+func TestMockProviderMatchesRules(t *testing.T) {
+	provider := NewMockProvider("scripted", MockSettings{
+		Rules: []MockRule{
+			{Schema: "summary", Response: `{"summary": "Release planning"}`},
+			{Pattern: `(?i)\brelease\b`, Response: `{"assistant_reply": "Friday."}`},
+			{Pattern: `(`, Response: "never"}, // Invalid patterns never match
+		},
+		Default: `{"assistant_reply": "Sorry?"}`,
+	})
+	ctx := context.Background()
+
+	cases := []struct {
+		prompt   string
+		schema   *ResponseSchema
+		expected string
+	}{
+		{"When is the release?", nil, `{"assistant_reply": "Friday."}`},
+		{"When is the release?", &ResponseSchema{Name: "summary", Type: "OBJECT"}, `{"summary": "Release planning"}`},
+		{"(", nil, `{"assistant_reply": "Sorry?"}`},
+	}
+	for _, c := range cases {
+		response, err := provider.CallWithSchema(ctx, c.prompt, c.schema)
+		if err != nil || response.Text != c.expected {
+			t.Errorf("CallWithSchema(%q, %s) = %q, %v; expected %q", c.prompt, SchemaName(c.schema), response.Text, err, c.expected)
+		}
+	}
+}
+
+func TestMockProviderEmptyAnswerFollowsSchema(t *testing.T) {
+	schema := &ResponseSchema{
+		Name: "sentiment",
+		Type: "OBJECT",
+		Properties: map[string]interface{}{
+			"sentiment":  map[string]interface{}{"type": "STRING"},
+			"keywords":   map[string]interface{}{"type": "ARRAY"},
+			"confidence": map[string]interface{}{"type": "NUMBER"},
+		},
+	}
+	response, err := NewMockProvider("scripted", MockSettings{}).CallWithSchema(context.Background(), "analyse", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"confidence":0,"keywords":[],"sentiment":""}`; response.Text != expected {
+		t.Errorf("Expected %s, got %s", expected, response.Text)
+	}
+	if response.Usage.PromptTokens == 0 || response.Usage.CompletionTokens == 0 {
+		t.Errorf("Expected estimated usage, got %+v", response.Usage)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	cassettePath := filepath.Join(t.TempDir(), "cassette.jsonl")
+	schema := &ResponseSchema{Name: "summary", Type: "OBJECT"}
+	target := Target{Provider: "openai", Model: "gpt-4o"}
+
+	setupChainTest(t, Options{MaxAttempts: 1, Recording: RecordingRecord, Cassette: cassettePath})
+	recorder := withRecording(target, &scriptedProvider{})
+	if _, err := recorder.CallWithSchema(context.Background(), "Summarise: we ship Friday", schema); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cassettePath)
+	if err != nil || !strings.Contains(string(data), `"schema":"summary"`) {
+		t.Fatalf("Expected a recorded summary exchange, got %s, %v", data, err)
+	}
+
+	// Replay needs no provider and survives a prompt change
+	Configure(Options{MaxAttempts: 1, Recording: RecordingReplay, Cassette: cassettePath})
+	replayer := withRecording(target, &scriptedProvider{errs: []error{os.ErrPermission}})
+	if !replayer.IsAvailable() {
+		t.Error("Expected replay to be available without credentials")
+	}
+	response, err := replayer.CallWithSchema(context.Background(), "Summarise briefly: we ship Friday", schema)
+	if err != nil || response.Text != "ok" {
+		t.Errorf("Expected the recorded answer, got %q, %v", response.Text, err)
+	}
+	if _, err := replayer.CallWithSchema(context.Background(), "Another summary", schema); err == nil {
+		t.Error("Expected an error once the recordings of a schema are used up")
+	}
+	if response, err := replayer.CallWithSchema(context.Background(), "Summarise: we ship Friday", schema); err != nil || response.Text != "ok" {
+		t.Errorf("Expected an exact prompt to replay again, got %q, %v", response.Text, err)
+	}
+}
+
+func TestRecordingIsSealedWithVault(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := storage.OpenLocalKeys(filepath.Join(dir, "master.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ConfigureRecordingVault(storage.NewVault(keys))
+	t.Cleanup(func() { ConfigureRecordingVault(nil) })
+
+	cassettePath := filepath.Join(dir, "cassette.jsonl")
+	target := Target{Provider: "openai", Model: "gpt-4o"}
+	setupChainTest(t, Options{MaxAttempts: 1, Recording: RecordingRecord, Cassette: cassettePath})
+	if _, err := withRecording(target, &scriptedProvider{}).Call(context.Background(), "Bob's card is 4111"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cassettePath)
+	if err != nil || len(data) == 0 || strings.Contains(string(data), "4111") {
+		t.Fatalf("Expected a sealed recording, got %s, %v", data, err)
+	}
+
+	Configure(Options{MaxAttempts: 1, Recording: RecordingReplay, Cassette: cassettePath})
+	response, err := withRecording(target, &scriptedProvider{}).Call(context.Background(), "Bob's card is 4111")
+	if err != nil || response.Text != "ok" {
+		t.Errorf("Expected the sealed answer to replay, got %q, %v", response.Text, err)
+	}
+}
+
+// swapRedactor replaces one secret with a token
+type swapRedactor struct{ secret, token string }
+
+func (r swapRedactor) Redact(text string) string { return strings.ReplaceAll(text, r.secret, r.token) }
+func (r swapRedactor) Rehydrate(text string) string {
+	return strings.ReplaceAll(text, r.token, r.secret)
+}
+
+func TestChainRedactsPromptsAndRehydratesAnswers(t *testing.T) {
+	chain, err := NewChain([]Target{{Provider: ProviderMock, Model: "scripted", Mock: &MockSettings{
+		Rules:   []MockRule{{Pattern: `\[EMAIL_1\]`, Response: "I will write to [EMAIL_1]."}},
+		Default: "The address reached the provider.",
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.SetRedactor(swapRedactor{secret: "jane@example.com", token: "[EMAIL_1]"})
+
+	response, err := chain.Call(context.Background(), "Please mail jane@example.com")
+	if err != nil || response.Text != "I will write to jane@example.com." {
+		t.Errorf("Call = %q, %v", response.Text, err)
+	}
+}
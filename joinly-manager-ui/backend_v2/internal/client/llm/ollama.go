@@ -1,146 +1,114 @@
 package llm
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
 // OllamaProvider implements the LLMProvider interface for Ollama
 type OllamaProvider struct {
-	model string
+	model   string
+	baseURL string
 }
 
 // NewOllamaProvider creates a new Ollama provider
 func NewOllamaProvider(model string) *OllamaProvider {
-	return &OllamaProvider{model: model}
-}
-
-// Call makes a request to the Ollama API (backward compatibility)
-func (p *OllamaProvider) Call(prompt string) (string, error) {
-	return p.CallWithSchema(prompt, nil)
+	baseURL := providerSettings("ollama").BaseURL
+	if baseURL == "" {
+		baseURL = ollamaURLFromEnv()
+	}
+	return &OllamaProvider{model: model, baseURL: baseURL}
 }
 
-// CallWithSchema makes a request to the Ollama API with optional structured response schema
-func (p *OllamaProvider) CallWithSchema(prompt string, schema *ResponseSchema) (string, error) {
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		host := os.Getenv("OLLAMA_HOST")
-		if host == "" {
-			host = "localhost"
-		}
-		port := os.Getenv("OLLAMA_PORT")
-		if port == "" {
-			port = "11434"
-		}
-		ollamaURL = fmt.Sprintf("http://%s:%s", host, port)
+// ollamaURLFromEnv builds the Ollama server URL from OLLAMA_URL or OLLAMA_HOST/OLLAMA_PORT
+func ollamaURLFromEnv() string {
+	if ollamaURL := os.Getenv("OLLAMA_URL"); ollamaURL != "" {
+		return strings.TrimRight(ollamaURL, "/")
 	}
 
-	url := ollamaURL + "/api/generate"
-
-	// Enhance prompt with schema instructions if provided
-	enhancedPrompt := prompt
-	if schema != nil {
-		schemaStr, _ := json.MarshalIndent(schema, "", "  ")
-		enhancedPrompt = fmt.Sprintf(`Please respond with a valid JSON object that matches this schema:
-
-%s
+	host := os.Getenv("OLLAMA_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	port := os.Getenv("OLLAMA_PORT")
+	if port == "" {
+		port = "11434"
+	}
+	return fmt.Sprintf("http://%s:%s", host, port)
+}
 
-%s
+// Call makes a request to the Ollama API (backward compatibility)
+func (p *OllamaProvider) Call(ctx context.Context, prompt string) (Response, error) {
+	return p.CallWithSchema(ctx, prompt, nil)
+}
 
-Respond ONLY with the JSON object, no additional text or explanation.`, string(schemaStr), prompt)
-	}
+// CallWithSchema makes a request to the Ollama API with optional structured response schema
+func (p *OllamaProvider) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return p.Chat(ctx, userMessage(prompt), schema)
+}
 
+// Chat sends a conversation to the Ollama chat API with schema instructions added to the last user message
+func (p *OllamaProvider) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
 	payload := map[string]interface{}{
-		"model":  p.model,
-		"prompt": enhancedPrompt,
-		"stream": false,
+		"model":    p.model,
+		"messages": withSchemaInstructions(messages, schema),
+		"stream":   false,
 		"options": map[string]interface{}{
 			"num_predict": 2000, // Increased for analysis tasks
 			"temperature": 0.3,  // Lower temperature for more consistent analysis
 		},
 	}
 
-	return p.makeHTTPCall(url, payload, map[string]string{
+	body, err := postJSON(ctx, "ollama", joinURL(p.baseURL, "/api/chat"), payload, map[string]string{
 		"Content-Type": "application/json",
 	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	return p.extractResponseText(body)
 }
 
 // IsAvailable checks if Ollama server is accessible
 func (p *OllamaProvider) IsAvailable() bool {
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		host := os.Getenv("OLLAMA_HOST")
-		if host == "" {
-			host = "localhost"
-		}
-		port := os.Getenv("OLLAMA_PORT")
-		if port == "" {
-			port = "11434"
-		}
-		ollamaURL = fmt.Sprintf("http://%s:%s", host, port)
-	}
-
 	// Quick health check to Ollama (with reasonable timeout for network issues)
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(ollamaURL + "/api/tags")
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-	return resp.StatusCode == 200
-}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-// makeHTTPCall is a helper function to make HTTP calls to the Ollama API
-func (p *OllamaProvider) makeHTTPCall(url string, payload map[string]interface{}, headers map[string]string) (string, error) {
-	jsonData, err := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, joinURL(p.baseURL, "/api/tags"), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	for key, value := range headers {
-		req.Header.Set(key, value)
+		return false
 	}
-
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+		return false
 	}
 	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	return p.extractResponseText(body)
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode == 200
 }
 
 // extractResponseText extracts the response text from Ollama API response
-func (p *OllamaProvider) extractResponseText(body []byte) (string, error) {
+func (p *OllamaProvider) extractResponseText(body []byte) (Response, error) {
 	var response map[string]interface{}
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return Response{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if responseText, ok := response["response"].(string); ok {
-		return responseText, nil
+	// Ollama reports token counts at the top level of the response
+	tokens := parseUsage(response, "prompt_eval_count", "eval_count")
+
+	if message, ok := response["message"].(map[string]interface{}); ok {
+		if content, ok := message["content"].(string); ok {
+			return Response{Text: content, Usage: tokens}, nil
+		}
 	}
 
-	return "", fmt.Errorf("could not extract response text from Ollama API response")
+	return Response{}, fmt.Errorf("could not extract response text from Ollama API response")
 }
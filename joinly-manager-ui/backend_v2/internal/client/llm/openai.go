@@ -1,39 +1,41 @@
 package llm
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"time"
 )
 
 // OpenAIProvider implements the LLMProvider interface for OpenAI
 type OpenAIProvider struct {
-	model string
+	model   string
+	apiKey  string
+	baseURL string
 }
 
 // NewOpenAIProvider creates a new OpenAI provider
 func NewOpenAIProvider(model string) *OpenAIProvider {
-	return &OpenAIProvider{model: model}
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	registerSecret(apiKey)
+	return &OpenAIProvider{model: model, apiKey: apiKey, baseURL: providerSettings("openai").BaseURL}
 }
 
 // Call makes a request to the OpenAI API (backward compatibility)
-func (p *OpenAIProvider) Call(prompt string) (string, error) {
-	return p.CallWithSchema(prompt, nil)
+func (p *OpenAIProvider) Call(ctx context.Context, prompt string) (Response, error) {
+	return p.CallWithSchema(ctx, prompt, nil)
 }
 
 // CallWithSchema makes a request to the OpenAI API with optional structured response schema
-func (p *OpenAIProvider) CallWithSchema(prompt string, schema *ResponseSchema) (string, error) {
-	url := "https://api.openai.com/v1/chat/completions"
+func (p *OpenAIProvider) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return p.Chat(ctx, userMessage(prompt), schema)
+}
 
+// Chat sends a conversation to the OpenAI API with optional structured response schema
+func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
 	payload := map[string]interface{}{
-		"model": p.model,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
+		"model":       p.model,
+		"messages":    messages,
 		"max_tokens":  2000, // Increased for analysis tasks
 		"temperature": 0.3,  // Lower temperature for more consistent analysis
 	}
@@ -49,70 +51,46 @@ func (p *OpenAIProvider) CallWithSchema(prompt string, schema *ResponseSchema) (
 		}
 	}
 
-	return p.makeHTTPCall(url, payload, map[string]string{
-		"Authorization": "Bearer " + os.Getenv("OPENAI_API_KEY"),
+	body, err := postJSON(ctx, "openai", joinURL(p.baseURL, "/chat/completions"), payload, map[string]string{
+		"Authorization": "Bearer " + p.apiKey,
 		"Content-Type":  "application/json",
 	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	return p.extractResponseText(body)
 }
 
 // IsAvailable checks if the OpenAI API key is available
 func (p *OpenAIProvider) IsAvailable() bool {
-	key := os.Getenv("OPENAI_API_KEY")
-	return key != ""
+	return p.apiKey != ""
 }
 
-// makeHTTPCall is a helper function to make HTTP calls to the OpenAI API
-func (p *OpenAIProvider) makeHTTPCall(url string, payload map[string]interface{}, headers map[string]string) (string, error) {
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	return p.extractResponseText(body)
+// extractResponseText extracts the response text from OpenAI API response
+func (p *OpenAIProvider) extractResponseText(body []byte) (Response, error) {
+	return parseChatCompletion(body, "OpenAI")
 }
 
-// extractResponseText extracts the response text from OpenAI API response
-func (p *OpenAIProvider) extractResponseText(body []byte) (string, error) {
+// parseChatCompletion extracts the reply and token usage from a chat-completions response
+func parseChatCompletion(body []byte, apiName string) (Response, error) {
 	var response map[string]interface{}
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return Response{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	usage, _ := response["usage"].(map[string]interface{})
+	tokens := parseUsage(usage, "prompt_tokens", "completion_tokens")
+
 	if choices, ok := response["choices"].([]interface{}); ok && len(choices) > 0 {
 		if choice, ok := choices[0].(map[string]interface{}); ok {
 			if message, ok := choice["message"].(map[string]interface{}); ok {
 				if content, ok := message["content"].(string); ok {
-					return content, nil
+					return Response{Text: content, Usage: tokens}, nil
 				}
 			}
 		}
 	}
 
-	return "", fmt.Errorf("could not extract response text from OpenAI API response")
+	return Response{}, fmt.Errorf("could not extract response text from %s API response", apiName)
 }
@@ -0,0 +1,258 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ProviderOpenAICompatible names servers that speak the OpenAI chat-completions API
+// (vLLM, llama.cpp server, LM Studio, LiteLLM, ...)
+const ProviderOpenAICompatible = "openai_compatible"
+
+// Capabilities describes optional API features a provider supports
+type Capabilities struct {
+	JSONSchema bool `json:"json_schema"` // Structured output via response_format json_schema
+	Tools      bool `json:"tools"`       // Tool/function calling
+	Streaming  bool `json:"streaming"`   // Server-sent event streaming
+}
+
+// CapabilityReporter is implemented by providers whose features vary per deployment
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// CompatibleSettings configures an OpenAI-compatible endpoint
+type CompatibleSettings struct {
+	BaseURL      string            // API root including the version, e.g. http://vllm:8000/v1
+	APIKeyEnv    string            // Environment variable holding the API key (empty = unauthenticated)
+	Headers      map[string]string // Extra request headers
+	Capabilities Capabilities
+}
+
+// compatibleSettingsFromEnv returns the default OpenAI-compatible endpoint configured by
+// OPENAI_COMPATIBLE_BASE_URL and OPENAI_COMPATIBLE_API_KEY
+func compatibleSettingsFromEnv() CompatibleSettings {
+	settings := CompatibleSettings{BaseURL: providerSettings(ProviderOpenAICompatible).BaseURL}
+	if settings.BaseURL == "" {
+		settings.BaseURL = os.Getenv("OPENAI_COMPATIBLE_BASE_URL")
+	}
+	if os.Getenv("OPENAI_COMPATIBLE_API_KEY") != "" {
+		settings.APIKeyEnv = "OPENAI_COMPATIBLE_API_KEY"
+	}
+	return settings
+}
+
+// OpenAICompatibleProvider implements the LLMProvider interface for OpenAI-compatible servers
+type OpenAICompatibleProvider struct {
+	model    string
+	apiKey   string
+	settings CompatibleSettings
+}
+
+// NewOpenAICompatibleProvider creates a provider for an OpenAI-compatible server
+func NewOpenAICompatibleProvider(model string, settings CompatibleSettings) *OpenAICompatibleProvider {
+	settings.BaseURL = strings.TrimRight(settings.BaseURL, "/")
+
+	var apiKey string
+	if settings.APIKeyEnv != "" {
+		apiKey = os.Getenv(settings.APIKeyEnv)
+		registerSecret(apiKey)
+	}
+
+	return &OpenAICompatibleProvider{model: model, apiKey: apiKey, settings: settings}
+}
+
+// Capabilities returns the features the server was configured to support
+func (p *OpenAICompatibleProvider) Capabilities() Capabilities {
+	return p.settings.Capabilities
+}
+
+// Call makes a request to the OpenAI-compatible server (backward compatibility)
+func (p *OpenAICompatibleProvider) Call(ctx context.Context, prompt string) (Response, error) {
+	return p.CallWithSchema(ctx, prompt, nil)
+}
+
+// CallWithSchema makes a request to the OpenAI-compatible server with optional structured response schema
+func (p *OpenAICompatibleProvider) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return p.Chat(ctx, userMessage(prompt), schema)
+}
+
+// Chat sends a conversation to the OpenAI-compatible server. The schema is sent as
+// response_format on servers with JSON schema support, as a forced function call on servers
+// with tool calling only, and as prompt instructions otherwise.
+func (p *OpenAICompatibleProvider) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
+	capabilities := p.settings.Capabilities
+	if schema != nil && !capabilities.JSONSchema && !capabilities.Tools {
+		messages = withSchemaInstructions(messages, schema)
+		schema = nil
+	}
+
+	payload := map[string]interface{}{
+		"model":       p.model,
+		"messages":    messages,
+		"max_tokens":  2000,
+		"temperature": 0.3,
+	}
+
+	if schema != nil {
+		name := schema.Name
+		if name == "" {
+			name = "structured_response"
+		}
+		if capabilities.JSONSchema {
+			payload["response_format"] = map[string]interface{}{
+				"type": "json_schema",
+				"json_schema": map[string]interface{}{
+					"name":   name,
+					"schema": schema,
+				},
+			}
+		} else {
+			payload["tools"] = []interface{}{map[string]interface{}{
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":        name,
+					"description": "Return the response in this structure",
+					"parameters":  schema,
+				},
+			}}
+			payload["tool_choice"] = map[string]interface{}{
+				"type":     "function",
+				"function": map[string]interface{}{"name": name},
+			}
+		}
+	}
+
+	if capabilities.Streaming {
+		payload["stream"] = true
+		payload["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for key, value := range p.settings.Headers {
+		headers[key] = value
+	}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+
+	body, err := postJSON(ctx, ProviderOpenAICompatible, joinURL(p.settings.BaseURL, "/chat/completions"), payload, headers)
+	if err != nil {
+		return Response{}, err
+	}
+
+	// Servers that ignore the stream flag answer with a plain completion
+	if capabilities.Streaming && !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		return parseChatCompletionStream(body)
+	}
+	return parseCompatibleCompletion(body)
+}
+
+// parseCompatibleCompletion extracts the reply of a chat-completions response, taking the
+// arguments of the forced function call when the message has no content
+func parseCompatibleCompletion(body []byte) (Response, error) {
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content   *string        `json:"content"`
+				ToolCalls []toolCallJSON `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage map[string]interface{} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Response{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(response.Choices) > 0 {
+		message := response.Choices[0].Message
+		tokens := parseUsage(response.Usage, "prompt_tokens", "completion_tokens")
+		if message.Content != nil && *message.Content != "" {
+			return Response{Text: *message.Content, Usage: tokens}, nil
+		}
+		if len(message.ToolCalls) > 0 {
+			return Response{Text: message.ToolCalls[0].Function.Arguments, Usage: tokens}, nil
+		}
+		if message.Content != nil {
+			return Response{Text: "", Usage: tokens}, nil
+		}
+	}
+	return Response{}, fmt.Errorf("could not extract response text from OpenAI-compatible API response")
+}
+
+// toolCallJSON is a function call in a chat-completions message or stream delta
+type toolCallJSON struct {
+	Function struct {
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// IsAvailable checks that a base URL is configured and, if the server needs one, an API key
+func (p *OpenAICompatibleProvider) IsAvailable() bool {
+	if p.settings.BaseURL == "" {
+		return false
+	}
+	return p.settings.APIKeyEnv == "" || p.apiKey != ""
+}
+
+// parseChatCompletionStream assembles the reply and usage from a streamed chat-completions response
+func parseChatCompletionStream(body []byte) (Response, error) {
+	var text, arguments strings.Builder
+	var tokens Usage
+	chunks := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content   string         `json:"content"`
+					ToolCalls []toolCallJSON `json:"tool_calls"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage map[string]interface{} `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return Response{}, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		chunks++
+
+		if chunk.Usage != nil {
+			tokens = parseUsage(chunk.Usage, "prompt_tokens", "completion_tokens")
+		}
+		if len(chunk.Choices) > 0 {
+			delta := chunk.Choices[0].Delta
+			text.WriteString(delta.Content)
+			// Function call arguments arrive in fragments of the first call
+			if len(delta.ToolCalls) > 0 {
+				arguments.WriteString(delta.ToolCalls[0].Function.Arguments)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Response{}, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	if chunks == 0 {
+		return Response{}, fmt.Errorf("could not extract response text from OpenAI-compatible stream")
+	}
+	if text.Len() == 0 {
+		return Response{Text: arguments.String(), Usage: tokens}, nil
+	}
+	return Response{Text: text.String(), Usage: tokens}, nil
+}
@@ -0,0 +1,291 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/storage"
+)
+
+// RecordingMode selects whether provider exchanges are recorded to or replayed from a cassette
+type RecordingMode string
+
+const (
+	RecordingOff    RecordingMode = ""
+	RecordingRecord RecordingMode = "record" // Call the providers and append every answer to the cassette
+	RecordingReplay RecordingMode = "replay" // Answer from the cassette without calling any provider
+)
+
+// ParseRecordingMode converts a configured mode, where "off" and "" disable recording
+func ParseRecordingMode(mode string) (RecordingMode, error) {
+	switch mode {
+	case "", "off":
+		return RecordingOff, nil
+	case string(RecordingRecord), string(RecordingReplay):
+		return RecordingMode(mode), nil
+	}
+	return RecordingOff, fmt.Errorf("unsupported LLM recording mode: %s", mode)
+}
+
+// RecordedExchange is one provider answer stored in a cassette
+type RecordedExchange struct {
+	Key      string    `json:"key"` // Hash of the messages and schema name
+	Time     time.Time `json:"time"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Schema   string    `json:"schema,omitempty"`
+	Messages []Message `json:"messages"`
+	Response string    `json:"response"`
+	Usage    Usage     `json:"usage"`
+}
+
+// cassette is a JSONL file of recorded exchanges shared by every chain using it. Recordings
+// contain whole prompts, so with an encrypting vault every line is sealed on its own.
+type cassette struct {
+	path string
+
+	mu        sync.Mutex
+	loaded    bool
+	exchanges []RecordedExchange
+	used      []bool
+}
+
+var (
+	cassettes   = make(map[string]*cassette)
+	cassettesMu sync.Mutex
+
+	cassetteVault   *storage.Vault
+	cassetteVaultMu sync.RWMutex
+)
+
+// ConfigureRecordingVault sets the vault cassettes are written and read through (nil for
+// plaintext)
+func ConfigureRecordingVault(vault *storage.Vault) {
+	cassetteVaultMu.Lock()
+	defer cassetteVaultMu.Unlock()
+	cassetteVault = vault
+}
+
+// recordingVault returns the vault cassettes are written and read through
+func recordingVault() *storage.Vault {
+	cassetteVaultMu.RLock()
+	defer cassetteVaultMu.RUnlock()
+	return cassetteVault
+}
+
+// cassetteFor returns the cassette stored at path
+func cassetteFor(path string) *cassette {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	if c, ok := cassettes[path]; ok {
+		return c
+	}
+	c := &cassette{path: path}
+	cassettes[path] = c
+	return c
+}
+
+// record appends an exchange to the cassette file
+func (c *cassette) record(exchange RecordedExchange) error {
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if data, err = recordingVault().SealLine(data); err != nil {
+		return fmt.Errorf("failed to encrypt recording: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// replay returns the recorded answer to a call. An unused exchange with the same key is
+// preferred, then any exchange with the same key; when the prompt changed since recording,
+// the next unused exchange of the same schema answers in recorded order and exact is false.
+func (c *cassette) replay(key, schema string) (exchange RecordedExchange, exact bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.loadUnsafe(); err != nil {
+		return RecordedExchange{}, false, err
+	}
+
+	reused := -1
+	for i, recorded := range c.exchanges {
+		if recorded.Key != key {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return recorded, true, nil
+		}
+		reused = i
+	}
+	if reused >= 0 {
+		return c.exchanges[reused], true, nil
+	}
+
+	for i, recorded := range c.exchanges {
+		if !c.used[i] && recorded.Schema == schema {
+			c.used[i] = true
+			return recorded, false, nil
+		}
+	}
+
+	return RecordedExchange{}, false, fmt.Errorf("no recorded response for %s call %s in %s", schemaLabel(schema), key, c.path)
+}
+
+// loadUnsafe reads the cassette file once (caller must hold lock)
+func (c *cassette) loadUnsafe() error {
+	if c.loaded {
+		return nil
+	}
+
+	file, err := os.Open(c.path)
+	if err != nil {
+		return fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	vault := recordingVault()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		data, err := vault.OpenLine(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to decrypt cassette line %d: %w", line, err)
+		}
+		var exchange RecordedExchange
+		if err := json.Unmarshal(data, &exchange); err != nil {
+			return fmt.Errorf("failed to parse cassette line %d: %w", line, err)
+		}
+		c.exchanges = append(c.exchanges, exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	c.used = make([]bool, len(c.exchanges))
+	c.loaded = true
+	return nil
+}
+
+// recordingProvider records the answers of a provider to a cassette or replays them
+type recordingProvider struct {
+	Target
+	llm      LLMProvider
+	mode     RecordingMode
+	cassette *cassette
+}
+
+// withRecording wraps a chain member's provider when recording or replay is enabled
+func withRecording(target Target, provider LLMProvider) LLMProvider {
+	settings := currentOptions()
+	if settings.Recording == RecordingOff || settings.Cassette == "" || target.Provider == ProviderMock {
+		return provider
+	}
+	return &recordingProvider{Target: target, llm: provider, mode: settings.Recording, cassette: cassetteFor(settings.Cassette)}
+}
+
+// Call sends a prompt
+func (p *recordingProvider) Call(ctx context.Context, prompt string) (Response, error) {
+	return p.CallWithSchema(ctx, prompt, nil)
+}
+
+// CallWithSchema sends a prompt with an optional structured response schema
+func (p *recordingProvider) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return p.Chat(ctx, userMessage(prompt), schema)
+}
+
+// Chat replays the recorded answer to a conversation, or calls the provider and records its answer
+func (p *recordingProvider) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
+	key := exchangeKey(messages, schema)
+
+	if p.mode == RecordingReplay {
+		if err := ctx.Err(); err != nil {
+			return Response{}, err
+		}
+		exchange, exact, err := p.cassette.replay(key, SchemaName(schema))
+		if err != nil {
+			return Response{}, err
+		}
+		if !exact {
+			logrus.Warnf("Prompt of %s call %s differs from the recording, replaying recorded call %s", schemaLabel(SchemaName(schema)), key, exchange.Key)
+		}
+		return Response{Text: exchange.Response, Usage: exchange.Usage}, nil
+	}
+
+	response, err := p.llm.Chat(ctx, messages, schema)
+	if err != nil {
+		return response, err
+	}
+
+	exchange := RecordedExchange{
+		Key:      key,
+		Time:     time.Now(),
+		Provider: p.Provider,
+		Model:    p.Model,
+		Schema:   SchemaName(schema),
+		Messages: messages,
+		Response: response.Text,
+		Usage:    response.Usage,
+	}
+	if err := p.cassette.record(exchange); err != nil {
+		logrus.Warnf("Failed to record LLM exchange: %v", err)
+	}
+	return response, nil
+}
+
+// IsAvailable reports true when replaying, since no provider is called
+func (p *recordingProvider) IsAvailable() bool {
+	return p.mode == RecordingReplay || p.llm.IsAvailable()
+}
+
+// exchangeKey identifies a call by its messages and schema name
+func exchangeKey(messages []Message, schema *ResponseSchema) string {
+	data, _ := json.Marshal(struct {
+		Messages []Message `json:"messages"`
+		Schema   string    `json:"schema"`
+	}{messages, SchemaName(schema)})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// schemaLabel names a schema in log and error messages
+func schemaLabel(schema string) string {
+	if schema == "" {
+		return "free-text"
+	}
+	return schema
+}
@@ -0,0 +1,303 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/metrics"
+)
+
+// Options controls endpoints, retries, circuit breaking and debug capture for provider calls
+type Options struct {
+	MaxAttempts      int           // Attempts per provider, including the first call
+	BaseDelay        time.Duration // Backoff before the first retry, doubled for each further retry
+	MaxDelay         time.Duration // Upper bound for a single backoff; longer Retry-After values move on to the next provider
+	BreakerThreshold int           // Consecutive failures that open a provider's circuit breaker
+	BreakerCooldown  time.Duration // How long an open breaker skips the provider before a trial call
+
+	Providers    map[string]ProviderSettings // Base URL and timeout per provider name
+	DebugCapture bool                        // Capture redacted request/response pairs for inspection
+
+	Recording RecordingMode // Record provider answers to the cassette or replay them from it
+	Cassette  string        // JSONL file of recorded exchanges
+}
+
+// DefaultOptions returns the default retry and circuit breaker settings
+func DefaultOptions() Options {
+	return Options{
+		MaxAttempts:      3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         8 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+var (
+	options   = DefaultOptions()
+	optionsMu sync.RWMutex
+
+	// wait pauses between retries (replaced in tests)
+	wait = defaultWait
+)
+
+// defaultWait sleeps for d, returning early with the context error when ctx is cancelled
+func defaultWait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Configure replaces the provider call settings. Providers and breakers created before
+// the call keep their previous endpoints, threshold and cooldown.
+func Configure(opts Options) {
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+
+	optionsMu.Lock()
+	options = opts
+	optionsMu.Unlock()
+}
+
+// currentOptions returns the active retry and circuit breaker settings
+func currentOptions() Options {
+	optionsMu.RLock()
+	defer optionsMu.RUnlock()
+	return options
+}
+
+// Target identifies one provider/model pair of a fallback chain
+type Target struct {
+	Provider   string
+	Model      string
+	Compatible *CompatibleSettings // Endpoint of an openai_compatible target (nil = environment defaults)
+	Mock       *MockSettings       // Scripted responses of a mock target (nil = empty answers)
+}
+
+// breakerKey identifies the endpoint a circuit breaker guards. OpenAI-compatible targets
+// are distinct servers, so each base URL gets its own breaker.
+func (t Target) breakerKey() string {
+	if t.Provider == ProviderOpenAICompatible && t.Compatible != nil {
+		return t.Provider + " " + t.Compatible.BaseURL
+	}
+	return t.Provider
+}
+
+// chainMember is a target together with its provider implementation
+type chainMember struct {
+	Target
+	llm LLMProvider
+}
+
+// Chain calls an ordered list of providers, retrying transient failures with backoff
+// and falling back to the next provider when one fails or its circuit breaker is open.
+// It implements LLMProvider; responses name the provider and model that answered.
+type Chain struct {
+	members  []chainMember
+	redactor Redactor
+}
+
+// Redactor replaces personal data in prompts with tokens and restores it in answers
+type Redactor interface {
+	Redact(text string) string
+	Rehydrate(text string) string
+}
+
+// SetRedactor makes the chain redact every message before it reaches a provider and rehydrate
+// the answer
+func (c *Chain) SetRedactor(redactor Redactor) {
+	c.redactor = redactor
+}
+
+// NewChain creates a fallback chain from provider/model pairs. Unsupported providers
+// are skipped; an error is returned only when no usable target remains.
+func NewChain(targets []Target) (*Chain, error) {
+	chain := &Chain{}
+	var lastErr error
+
+	for _, target := range targets {
+		if target.Provider == "" || target.Model == "" {
+			continue
+		}
+		var provider LLMProvider
+		var err error
+		switch {
+		case target.Provider == ProviderOpenAICompatible && target.Compatible != nil:
+			provider = NewOpenAICompatibleProvider(target.Model, *target.Compatible)
+		case target.Provider == ProviderMock && target.Mock != nil:
+			provider = NewMockProvider(target.Model, *target.Mock)
+		default:
+			provider, err = GetProvider(target.Provider, target.Model)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		chain.members = append(chain.members, chainMember{Target: target, llm: withRecording(target, provider)})
+	}
+
+	if len(chain.members) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("no LLM provider/model configured")
+	}
+	return chain, nil
+}
+
+// Targets returns the provider/model pairs of the chain in order
+func (c *Chain) Targets() []Target {
+	targets := make([]Target, len(c.members))
+	for i, member := range c.members {
+		targets[i] = member.Target
+	}
+	return targets
+}
+
+// Call sends a prompt through the chain
+func (c *Chain) Call(ctx context.Context, prompt string) (Response, error) {
+	return c.CallWithSchema(ctx, prompt, nil)
+}
+
+// CallWithSchema sends a prompt through the chain with an optional structured response schema
+func (c *Chain) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return c.Chat(ctx, userMessage(prompt), schema)
+}
+
+// Chat sends a conversation through the chain with an optional structured response schema.
+// A cancelled ctx stops the chain immediately without trying further providers.
+func (c *Chain) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
+	var lastErr error
+
+	if c.redactor != nil {
+		redacted := make([]Message, len(messages))
+		for i, message := range messages {
+			message.Content = c.redactor.Redact(message.Content)
+			redacted[i] = message
+		}
+		messages = redacted
+	}
+
+	for i, member := range c.members {
+		if err := ctx.Err(); err != nil {
+			return Response{}, err
+		}
+
+		if !member.llm.IsAvailable() {
+			lastErr = fmt.Errorf("no API key found for provider %s", member.Provider)
+			continue
+		}
+
+		breaker := breakerFor(member.breakerKey())
+		if !breaker.Allow(time.Now()) {
+			lastErr = fmt.Errorf("circuit breaker open for provider %s", member.Provider)
+			continue
+		}
+
+		response, err := c.callWithRetry(ctx, member, breaker, messages, schema)
+		if err == nil {
+			response.Provider = member.Provider
+			response.Model = member.Model
+			if c.redactor != nil {
+				response.Text = c.redactor.Rehydrate(response.Text)
+			}
+			return response, nil
+		}
+		if ctx.Err() != nil {
+			return Response{}, ctx.Err()
+		}
+
+		lastErr = err
+		if i < len(c.members)-1 {
+			logrus.Warnf("LLM %s/%s failed, falling back to the next provider: %v", member.Provider, member.Model, err)
+		}
+	}
+
+	return Response{}, fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// IsAvailable reports whether any provider of the chain has credentials configured
+func (c *Chain) IsAvailable() bool {
+	for _, member := range c.members {
+		if member.llm.IsAvailable() {
+			return true
+		}
+	}
+	return false
+}
+
+// callWithRetry calls one provider, retrying rate limits, server errors and transport failures
+func (c *Chain) callWithRetry(ctx context.Context, member chainMember, breaker *circuitBreaker, messages []Message, schema *ResponseSchema) (Response, error) {
+	settings := currentOptions()
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		response, err := member.llm.Chat(ctx, messages, schema)
+
+		// A caller that gave up says nothing about the provider's health
+		if ctx.Err() != nil {
+			breaker.Release()
+			return Response{}, ctx.Err()
+		}
+		metrics.ObserveLLMCall(member.Provider, member.Model, time.Since(start), err)
+
+		if err == nil {
+			breaker.Success()
+			return response, nil
+		}
+
+		// The provider answered, so a non-retryable error says nothing about its health:
+		// give back a trial call without closing the breaker
+		if !isRetryable(err) {
+			breaker.Release()
+			return Response{}, err
+		}
+
+		breaker.Failure(time.Now())
+		if attempt >= settings.MaxAttempts || breaker.Open() {
+			return Response{}, err
+		}
+
+		delay := backoff(settings, attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			if apiErr.RetryAfter > settings.MaxDelay {
+				return Response{}, err
+			}
+			delay = apiErr.RetryAfter
+		}
+
+		logrus.Debugf("Retrying LLM %s/%s in %v (attempt %d/%d): %v", member.Provider, member.Model, delay, attempt+1, settings.MaxAttempts, err)
+		if err := wait(ctx, delay); err != nil {
+			return Response{}, err
+		}
+	}
+}
+
+// backoff returns the jittered exponential delay before the given retry
+func backoff(settings Options, attempt int) time.Duration {
+	delay := settings.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > settings.MaxDelay {
+		delay = settings.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter: half the delay is fixed, the other half random
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
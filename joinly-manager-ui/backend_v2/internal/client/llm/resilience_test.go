@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// scriptedProvider returns the scripted errors in order, then succeeds
+type scriptedProvider struct {
+	errs  []error
+	calls int
+}
+
+func (p *scriptedProvider) Call(ctx context.Context, prompt string) (Response, error) {
+	return p.CallWithSchema(ctx, prompt, nil)
+}
+
+func (p *scriptedProvider) CallWithSchema(ctx context.Context, prompt string, schema *ResponseSchema) (Response, error) {
+	return p.Chat(ctx, userMessage(prompt), schema)
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, messages []Message, schema *ResponseSchema) (Response, error) {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		return Response{}, p.errs[p.calls-1]
+	}
+	return Response{Text: "ok"}, nil
+}
+
+func (p *scriptedProvider) IsAvailable() bool { return true }
+
+// setupChainTest disables backoff waits and resets breakers and options
+func setupChainTest(t *testing.T, opts Options) *[]time.Duration {
+	t.Helper()
+
+	var waits []time.Duration
+	wait = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	Configure(opts)
+
+	breakersMu.Lock()
+	breakers = make(map[string]*circuitBreaker)
+	breakersMu.Unlock()
+
+	t.Cleanup(func() {
+		wait = defaultWait
+		Configure(DefaultOptions())
+	})
+	return &waits
+}
+
+// testChain builds a chain over fake providers named a, b, ...
+func testChain(providers ...LLMProvider) *Chain {
+	chain := &Chain{}
+	for i, provider := range providers {
+		target := Target{Provider: string(rune('a' + i)), Model: "model"}
+		chain.members = append(chain.members, chainMember{Target: target, llm: provider})
+	}
+	return chain
+}
+
+func TestChainRetriesAndHonoursRetryAfter(t *testing.T) {
+	waits := setupChainTest(t, Options{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Second, BreakerThreshold: 5, BreakerCooldown: time.Minute})
+
+	primary := &scriptedProvider{errs: []error{
+		&APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second},
+		&transportError{err: errors.New("connection reset")},
+	}}
+	response, err := testChain(primary).Call(context.Background(), "hi")
+	if err != nil || response.Text != "ok" || response.Provider != "a" {
+		t.Fatalf("Expected success from provider a, got %+v, %v", response, err)
+	}
+	if primary.calls != 3 || len(*waits) != 2 || (*waits)[0] != 2*time.Second {
+		t.Errorf("Expected 3 calls with a 2s Retry-After wait, got %d calls, waits %v", primary.calls, *waits)
+	}
+}
+
+func TestChainFallsBackWithoutRetryingClientErrors(t *testing.T) {
+	setupChainTest(t, Options{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second, BreakerThreshold: 5, BreakerCooldown: time.Minute})
+
+	primary := &scriptedProvider{errs: []error{&APIError{StatusCode: http.StatusBadRequest}}}
+	secondary := &scriptedProvider{}
+	response, err := testChain(primary, secondary).Call(context.Background(), "hi")
+	if err != nil || response.Provider != "b" {
+		t.Fatalf("Expected fallback to provider b, got %+v, %v", response, err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("Expected a 400 not to be retried, got %d calls", primary.calls)
+	}
+}
+
+func TestChainCircuitBreaker(t *testing.T) {
+	setupChainTest(t, Options{MaxAttempts: 1, BreakerThreshold: 2, BreakerCooldown: time.Hour})
+
+	serverError := &APIError{StatusCode: http.StatusBadGateway}
+	primary := &scriptedProvider{errs: []error{serverError, serverError, serverError}}
+	secondary := &scriptedProvider{}
+	chain := testChain(primary, secondary)
+
+	for i := 0; i < 3; i++ {
+		if _, err := chain.Call(context.Background(), "hi"); err != nil {
+			t.Fatalf("Call %d: expected fallback to succeed, got %v", i, err)
+		}
+	}
+	if primary.calls != 2 {
+		t.Errorf("Expected the open breaker to skip provider a after 2 failures, got %d calls", primary.calls)
+	}
+
+	// After the cooldown a single trial call is let through
+	breaker := breakerFor("a")
+	if breaker.Allow(time.Now()) {
+		t.Error("Expected breaker to stay open during the cooldown")
+	}
+	if !breaker.Allow(time.Now().Add(2*time.Hour)) || breaker.Allow(time.Now().Add(2*time.Hour)) {
+		t.Error("Expected exactly one trial call after the cooldown")
+	}
+	breaker.Success()
+	if breaker.Open() || !breaker.Allow(time.Now()) {
+		t.Error("Expected a successful trial to close the breaker")
+	}
+}
+
+func TestChainNonRetryableErrorKeepsBreakerHalfOpen(t *testing.T) {
+	setupChainTest(t, Options{MaxAttempts: 1, BreakerThreshold: 1, BreakerCooldown: time.Millisecond})
+
+	breaker := breakerFor("a")
+	breaker.Failure(time.Now())
+	time.Sleep(5 * time.Millisecond)
+
+	primary := &scriptedProvider{errs: []error{&APIError{StatusCode: http.StatusBadRequest}}}
+	secondary := &scriptedProvider{}
+	if _, err := testChain(primary, secondary).Call(context.Background(), "hi"); err != nil {
+		t.Fatalf("Expected fallback to succeed, got %v", err)
+	}
+	if primary.calls != 1 {
+		t.Fatalf("Expected the trial call to reach provider a, got %d calls", primary.calls)
+	}
+
+	// The trial slot is free again, but only a real success closes the breaker
+	if !breaker.Allow(time.Now()) || breaker.Allow(time.Now()) {
+		t.Error("Expected the breaker to stay half-open with a single trial call")
+	}
+}
+
+func TestChainAllProvidersFail(t *testing.T) {
+	setupChainTest(t, Options{MaxAttempts: 1, BreakerThreshold: 5, BreakerCooldown: time.Minute})
+
+	chain := testChain(&scriptedProvider{errs: []error{&APIError{StatusCode: 500}}})
+	if _, err := chain.Call(context.Background(), "hi"); err == nil {
+		t.Fatal("Expected an error when every provider fails")
+	}
+}
+
+func TestChainStopsWhenCancelled(t *testing.T) {
+	setupChainTest(t, Options{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second, BreakerThreshold: 5, BreakerCooldown: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	secondary := &scriptedProvider{}
+	_, err := testChain(&scriptedProvider{}, secondary).Call(ctx, "hi")
+	if !errors.Is(err, context.Canceled) || secondary.calls != 0 {
+		t.Errorf("Expected cancellation without fallback, got %v after %d fallback calls", err, secondary.calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"7":                             7 * time.Second,
+		"soon":                          0,
+		"Wed, 01 Jan 2025 12:00:30 GMT": 30 * time.Second,
+	}
+	for value, expected := range cases {
+		if got := parseRetryAfter(value, now); got != expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", value, got, expected)
+		}
+	}
+}
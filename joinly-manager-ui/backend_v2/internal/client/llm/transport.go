@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds a single provider request when no timeout is configured
+const defaultTimeout = 60 * time.Second
+
+// defaultBaseURLs are the API roots used when no base URL is configured
+var defaultBaseURLs = map[string]string{
+	"openai":    "https://api.openai.com/v1",
+	"anthropic": "https://api.anthropic.com/v1",
+	"google":    "https://generativelanguage.googleapis.com/v1beta",
+}
+
+// ProviderSettings configures the HTTP endpoint of one provider
+type ProviderSettings struct {
+	BaseURL string        // API root, e.g. a proxy or gateway (empty = provider default)
+	Timeout time.Duration // Per-request timeout (0 = 60s)
+}
+
+// httpClient is shared by all providers so that connections are reused across calls.
+// It has no global timeout: each request is bounded by its context and provider timeout.
+var httpClient = &http.Client{Transport: newTransport()}
+
+// newTransport returns the pooled transport used for provider requests
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 16
+	transport.IdleConnTimeout = 90 * time.Second
+	return transport
+}
+
+// providerSettings returns the configured endpoint settings of a provider with defaults applied
+func providerSettings(provider string) ProviderSettings {
+	settings := currentOptions().Providers[provider]
+	if settings.BaseURL == "" {
+		settings.BaseURL = defaultBaseURLs[provider]
+	}
+	settings.BaseURL = strings.TrimRight(settings.BaseURL, "/")
+	if settings.Timeout <= 0 {
+		settings.Timeout = defaultTimeout
+	}
+	return settings
+}
+
+// joinURL appends an API path to a base URL, keeping any query string of the base
+// (such as the api-version parameter of Azure OpenAI deployments)
+func joinURL(base, path string) string {
+	parsed, err := url.Parse(base)
+	if err != nil || parsed.RawQuery == "" {
+		return strings.TrimRight(base, "/") + path
+	}
+	parsed.Path = strings.TrimRight(parsed.Path, "/") + path
+	return parsed.String()
+}
+
+// postJSON sends a JSON request to a provider through the shared client and returns the
+// body of a successful response. The request is cancelled with ctx and bounded by the
+// provider timeout; non-200 answers are returned as *APIError.
+func postJSON(ctx context.Context, provider, endpoint string, payload interface{}, headers map[string]string) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, providerSettings(provider).Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		captureExchange(provider, req, jsonData, 0, nil, time.Since(start), err)
+		return nil, &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	captureExchange(provider, req, jsonData, resp.StatusCode, body, time.Since(start), err)
+	if err != nil {
+		return nil, &transportError{err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(provider, resp, body)
+	}
+
+	return body, nil
+}
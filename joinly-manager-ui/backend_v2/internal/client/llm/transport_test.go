@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostJSONUsesConfiguredBaseURL(t *testing.T) {
+	var path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		w.Write([]byte(`{"choices":[{"message":{"content":"hello"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "sk-test-0123456789")
+	Configure(Options{MaxAttempts: 1, DebugCapture: true, Providers: map[string]ProviderSettings{
+		"openai": {BaseURL: server.URL + "/proxy/v1/"},
+	}})
+	t.Cleanup(func() { Configure(DefaultOptions()) })
+
+	response, err := NewOpenAIProvider("gpt-4o").Call(context.Background(), "hi")
+	if err != nil || response.Text != "hello" || response.Usage.TotalTokens() != 4 {
+		t.Fatalf("Unexpected response %+v, %v", response, err)
+	}
+	if path != "/proxy/v1/chat/completions" || auth != "Bearer sk-test-0123456789" {
+		t.Errorf("Unexpected request path %q or auth header %q", path, auth)
+	}
+
+	captured := Exchanges()
+	if len(captured) == 0 {
+		t.Fatal("Expected the exchange to be captured")
+	}
+	last := captured[len(captured)-1]
+	if last.RequestHeaders["Authorization"] != redacted || strings.Contains(last.URL+last.RequestBody, "sk-test") {
+		t.Errorf("Expected credentials to be redacted, got %+v", last)
+	}
+}
+
+func TestPostJSONHonoursContextAndTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	Configure(Options{MaxAttempts: 1, Providers: map[string]ProviderSettings{
+		"ollama": {BaseURL: server.URL, Timeout: 50 * time.Millisecond},
+	}})
+	t.Cleanup(func() { Configure(DefaultOptions()) })
+
+	start := time.Now()
+	_, err := NewOllamaProvider("llama3").Call(context.Background(), "hi")
+	if !isRetryable(err) || time.Since(start) > 2*time.Second {
+		t.Errorf("Expected a retryable timeout after ~50ms, got %v after %v", err, time.Since(start))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewOllamaProvider("llama3").Call(ctx, "hi"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled call, got %v", err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	registerSecret("custom-secret-value")
+	text := redact(`{"key":"custom-secret-value","auth":"Bearer abc.def","openai":"sk-abcdefghijkl"}`)
+	for _, secret := range []string{"custom-secret-value", "abc.def", "sk-abcdefghijkl"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("Expected %q to be redacted from %s", secret, text)
+		}
+	}
+}
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	var request map[string]interface{}
+	var team string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		team = r.Header.Get("X-Team")
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n" +
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatibleProvider("llama", CompatibleSettings{
+		BaseURL:      server.URL + "/v1",
+		Headers:      map[string]string{"X-Team": "voice"},
+		Capabilities: Capabilities{Streaming: true},
+	})
+	if !provider.IsAvailable() {
+		t.Fatal("Expected an unauthenticated server to be available")
+	}
+
+	response, err := provider.CallWithSchema(context.Background(), "hi", &ResponseSchema{Type: "object"})
+	if err != nil || response.Text != "Hello" || response.Usage.TotalTokens() != 7 {
+		t.Fatalf("Unexpected response %+v, %v", response, err)
+	}
+	if team != "voice" || request["stream"] != true || request["response_format"] != nil || request["tools"] != nil {
+		t.Errorf("Expected extra header, streaming and an inlined schema, got header %q, request %v", team, request)
+	}
+}
+
+func TestOpenAICompatibleProviderToolSchema(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		// A server that ignores the stream flag answers with a plain completion
+		w.Write([]byte(`{"choices":[{"message":{"content":null,"tool_calls":[{"type":"function",` +
+			`"function":{"name":"summary","arguments":"{\"summary\":\"Ship Friday\"}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatibleProvider("llama", CompatibleSettings{
+		BaseURL:      server.URL + "/v1",
+		Capabilities: Capabilities{Tools: true, Streaming: true},
+	})
+	response, err := provider.CallWithSchema(context.Background(), "hi", &ResponseSchema{Name: "summary", Type: "object"})
+	if err != nil || response.Text != `{"summary":"Ship Friday"}` {
+		t.Fatalf("Unexpected response %+v, %v", response, err)
+	}
+	if request["tools"] == nil || request["tool_choice"] == nil || request["response_format"] != nil {
+		t.Errorf("Expected the schema as a forced function call, got %v", request)
+	}
+	if message := request["messages"].([]interface{})[0].(map[string]interface{}); message["content"] != "hi" {
+		t.Errorf("Expected no schema instructions in the prompt, got %v", message)
+	}
+}
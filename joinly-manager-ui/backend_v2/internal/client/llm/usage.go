@@ -0,0 +1,40 @@
+package llm
+
+// Usage represents the tokens consumed by one LLM call
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// TotalTokens returns the sum of prompt and completion tokens
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Response represents an LLM reply together with its token usage
+type Response struct {
+	Text     string
+	Usage    Usage
+	Provider string // Provider and model that produced the reply (set by Chain)
+	Model    string
+}
+
+// parseUsage reads the token counts from a decoded provider usage object.
+// promptKey and completionKey name the provider's counters.
+func parseUsage(usage map[string]interface{}, promptKey, completionKey string) Usage {
+	if usage == nil {
+		return Usage{}
+	}
+	return Usage{
+		PromptTokens:     intField(usage, promptKey),
+		CompletionTokens: intField(usage, completionKey),
+	}
+}
+
+// intField reads a JSON number field as an int
+func intField(fields map[string]interface{}, key string) int {
+	if value, ok := fields[key].(float64); ok {
+		return int(value)
+	}
+	return 0
+}
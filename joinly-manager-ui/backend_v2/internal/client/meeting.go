@@ -71,6 +71,7 @@ func (c *JoinlyClient) JoinMeeting() error {
 
 	c.isJoined = true
 	c.log("info", "Successfully joined meeting")
+	c.resetConsentUnsafe()
 
 	// Reset transcript tracking after successful join
 	c.lastUtteranceStart = 0.0
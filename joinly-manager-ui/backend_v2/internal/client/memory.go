@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"joinly-manager/internal/client/llm"
+	"joinly-manager/internal/models"
+)
+
+// MemoryExtraction represents what a meeting added to an identity's long-term memory
+type MemoryExtraction struct {
+	Items     []models.MemoryItem // New facts, decisions and action items
+	Completed []string            // IDs of known action items the meeting reported as done
+}
+
+// ExtractMemories asks the LLM for the facts, decisions and action items of a meeting worth
+// remembering at the next meeting. known lists what the identity already remembers so it is
+// not repeated and open action items can be closed.
+func (c *JoinlyClient) ExtractMemories(ctx context.Context, summary string, turns []models.ConversationEntry, known []models.MemoryItem) (MemoryExtraction, error) {
+	config, provider, err := c.currentChain()
+	if err != nil {
+		return MemoryExtraction{}, err
+	}
+
+	var transcript strings.Builder
+	if summary != "" {
+		fmt.Fprintf(&transcript, "(Summary of the earlier part of the meeting: %s)\n", summary)
+	}
+	for _, turn := range turns {
+		speaker := turn.Speaker
+		if turn.IsAgent {
+			speaker = config.Name + " (you)"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", speaker, turn.Message)
+	}
+
+	remembered := "(nothing yet)"
+	if len(known) > 0 {
+		remembered = formatMemories(known, true)
+	}
+
+	prompt := fmt.Sprintf(`You are %s, an AI assistant that attends a recurring meeting. Decide what you should remember for the next meeting.
+
+Extract:
+- fact: lasting information about the team, project or participants
+- decision: something the participants agreed on
+- action_item: a task someone committed to, with its owner if known
+
+Only include items that will still matter next time. Do not repeat anything you already remember.
+List the ids of remembered open action items that this meeting reported as done in completed_action_items.
+
+Already remembered:
+%s
+
+Meeting transcript:
+%s`, config.Name, remembered, transcript.String())
+
+	response, err := provider.CallWithSchema(ctx, prompt, memoryExtractionSchema())
+	c.recordLLMCall(response, err)
+	if err != nil {
+		return MemoryExtraction{}, fmt.Errorf("failed to extract memories: %w", err)
+	}
+
+	var parsed struct {
+		Items []struct {
+			Kind    string `json:"kind"`
+			Content string `json:"content"`
+			Owner   string `json:"owner"`
+		} `json:"items"`
+		CompletedActionItems []string `json:"completed_action_items"`
+	}
+	if err := json.Unmarshal([]byte(response.Text), &parsed); err != nil {
+		return MemoryExtraction{}, fmt.Errorf("failed to parse extracted memories: %w", err)
+	}
+
+	extraction := MemoryExtraction{Completed: parsed.CompletedActionItems}
+	for _, item := range parsed.Items {
+		extraction.Items = append(extraction.Items, models.MemoryItem{
+			Kind:    models.MemoryKind(item.Kind),
+			Content: item.Content,
+			Owner:   strings.TrimSpace(item.Owner),
+		})
+	}
+	return extraction, nil
+}
+
+// formatMemories renders memory items as a bulleted list, optionally with their IDs
+func formatMemories(items []models.MemoryItem, withIDs bool) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString("- ")
+		if withIDs {
+			fmt.Fprintf(&b, "[%s] ", item.ID)
+		}
+
+		switch item.Kind {
+		case models.MemoryKindActionItem:
+			status := "Open action item"
+			if item.Done {
+				status = "Completed action item"
+			}
+			if item.Owner != "" {
+				fmt.Fprintf(&b, "%s (owner: %s, %s): ", status, item.Owner, item.CreatedAt.Format("2006-01-02"))
+			} else {
+				fmt.Fprintf(&b, "%s (%s): ", status, item.CreatedAt.Format("2006-01-02"))
+			}
+		case models.MemoryKindDecision:
+			fmt.Fprintf(&b, "Decision (%s): ", item.CreatedAt.Format("2006-01-02"))
+		default:
+			b.WriteString("Fact: ")
+		}
+
+		b.WriteString(item.Content)
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// memoryExtractionSchema returns the schema of the memory extraction response
+func memoryExtractionSchema() *llm.ResponseSchema {
+	return &llm.ResponseSchema{
+		Name: "memory_extraction",
+		Type: "OBJECT",
+		Properties: map[string]interface{}{
+			"items": map[string]interface{}{
+				"type": "ARRAY",
+				"items": map[string]interface{}{
+					"type": "OBJECT",
+					"properties": map[string]interface{}{
+						"kind": map[string]interface{}{
+							"type": "STRING",
+							"enum": []string{
+								string(models.MemoryKindFact),
+								string(models.MemoryKindDecision),
+								string(models.MemoryKindActionItem),
+							},
+						},
+						"content": map[string]interface{}{
+							"type":        "STRING",
+							"description": "One self-contained sentence",
+						},
+						"owner": map[string]interface{}{
+							"type":        "STRING",
+							"description": "Person responsible for an action item",
+						},
+					},
+					"required": []string{"kind", "content"},
+				},
+			},
+			"completed_action_items": map[string]interface{}{
+				"type":        "ARRAY",
+				"items":       map[string]interface{}{"type": "STRING"},
+				"description": "IDs of remembered action items reported as done",
+			},
+		},
+		Required: []string{"items"},
+	}
+}
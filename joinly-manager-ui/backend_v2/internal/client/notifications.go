@@ -6,8 +6,13 @@ import (
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+
+	"joinly-manager/internal/metrics"
 )
 
+// TranscriptPollInterval is how often the live transcript is polled for new segments
+const TranscriptPollInterval = 1 * time.Second
+
 // handleNotification handles incoming MCP notifications from the server
 func (c *JoinlyClient) handleNotification(notification mcp.JSONRPCNotification) {
 	c.log("debug", fmt.Sprintf("Received notification: method=%s", notification.Notification.Method))
@@ -51,6 +56,7 @@ func (c *JoinlyClient) handleResourceUpdatedNotification(notification mcp.JSONRP
 		if transcript, err := c.getTranscriptSegments(); err == nil {
 			c.utteranceUpdate(transcript)
 		} else {
+			metrics.IncTranscriptPollFailures()
 			c.log("warn", fmt.Sprintf("❌ Failed to get updated transcript segments: %v", err))
 		}
 	} else {
@@ -61,7 +67,7 @@ func (c *JoinlyClient) handleResourceUpdatedNotification(notification mcp.JSONRP
 // handleResourceNotifications now implements a polling fallback to bypass notification flow
 func (c *JoinlyClient) handleResourceNotifications() {
 	c.log("info", "Starting resource handler with polling fallback")
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := time.NewTicker(TranscriptPollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -79,6 +85,7 @@ func (c *JoinlyClient) handleResourceNotifications() {
 			// Poll transcript segments and process updates
 			transcript, err := c.getTranscriptSegments()
 			if err != nil {
+				metrics.IncTranscriptPollFailures()
 				c.log("debug", fmt.Sprintf("Polling read failed: %v", err))
 				continue
 			}
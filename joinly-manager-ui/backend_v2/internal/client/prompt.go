@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"joinly-manager/internal/client/llm"
+	"joinly-manager/internal/knowledge"
+	"joinly-manager/internal/models"
+)
+
+// defaultSystemPrompt is used when the agent has no system prompt of its own
+const defaultSystemPrompt = `You are a helpful AI assistant named %s participating in a meeting.
+Messages from participants are prefixed with the speaker's name; your own earlier replies appear as assistant messages.
+Respond naturally and helpfully, considering the conversation history. Keep your response concise and conversational.`
+
+// replyFormatInstructions ask for the JSON reply parsed by parseJSONResponse
+const replyFormatInstructions = `You must respond ONLY with valid JSON in the following format:
+{
+  "assistant_reply": "<Your actual response to speak to the user>",
+  "metadata": {
+    "topic": "<Optional: topic of the response>",
+    "confidence": <Optional: confidence score as a float>
+  }
+}`
+
+// knowledgeInstructions introduce the knowledge base passages retrieved for the utterance
+const knowledgeInstructions = `Reference passages from the knowledge base follow. Answer from them when they are relevant and
+cite each passage you use by its number, e.g. [1]. If they do not cover the question, say so instead of guessing.
+
+`
+
+// noPreviousContext stands in for an empty history in custom prompt templates
+const noPreviousContext = "No previous context."
+
+// buildReplyMessages turns the conversation window and the current utterance into chat messages:
+// the system prompt, the summary of older turns, what the agent remembers from earlier meetings,
+// the retrieved knowledge base passages, what the interjection policy wants brought up, the recent
+// turns (the agent's own as assistant turns) and the current utterance, rendered through the
+// custom prompt template if one is set
+func buildReplyMessages(config models.AgentConfig, speaker, text string, window models.ConversationWindow) []llm.Message {
+	customPrompt := config.CustomPrompt != nil && *config.CustomPrompt != ""
+
+	system := fmt.Sprintf(defaultSystemPrompt, config.Name)
+	if config.SystemPrompt != nil && *config.SystemPrompt != "" {
+		system = strings.ReplaceAll(*config.SystemPrompt, "{agent_name}", config.Name)
+	}
+	// Custom prompt templates define their own output format
+	if !customPrompt {
+		system += "\n\n" + replyFormatInstructions
+	}
+
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: system}}
+	if window.Summary != "" {
+		messages = append(messages, llm.Message{Role: llm.RoleSystem, Content: "Summary of the earlier conversation:\n" + window.Summary})
+	}
+	if len(window.Memories) > 0 {
+		messages = append(messages, llm.Message{Role: llm.RoleSystem, Content: "What you remember from earlier meetings:\n" + formatMemories(window.Memories, false)})
+	}
+	if len(window.Passages) > 0 {
+		messages = append(messages, llm.Message{Role: llm.RoleSystem, Content: knowledgeInstructions + knowledge.FormatPassages(window.Passages)})
+	}
+	if window.Interjection != "" {
+		messages = append(messages, llm.Message{Role: llm.RoleSystem, Content: "You are speaking up without being asked. " + window.Interjection})
+	}
+
+	for _, turn := range window.Turns {
+		if turn.IsAgent {
+			messages = append(messages, llm.Message{Role: llm.RoleAssistant, Content: turn.Message})
+		} else {
+			messages = append(messages, llm.Message{Role: llm.RoleUser, Content: fmt.Sprintf("%s: %s", turn.Speaker, turn.Message)})
+		}
+	}
+
+	current := fmt.Sprintf("%s: %s", speaker, text)
+	if customPrompt {
+		// Custom prompt template - replace placeholders; {context} receives the summary since
+		// recent turns are already part of the conversation
+		previous := window.Summary
+		if previous == "" {
+			previous = noPreviousContext
+		}
+		current = *config.CustomPrompt
+		current = strings.ReplaceAll(current, "{agent_name}", config.Name)
+		current = strings.ReplaceAll(current, "{speaker}", speaker)
+		current = strings.ReplaceAll(current, "{text}", text)
+		current = strings.ReplaceAll(current, "{context}", previous)
+	}
+
+	return append(messages, llm.Message{Role: llm.RoleUser, Content: current})
+}
+
+// SummarizeConversation folds conversation turns into a rolling summary, extending the previous one
+func (c *JoinlyClient) SummarizeConversation(ctx context.Context, previousSummary string, turns []models.ConversationEntry) (string, error) {
+	config, provider, err := c.currentChain()
+	if err != nil {
+		return "", err
+	}
+
+	var transcript strings.Builder
+	for _, turn := range turns {
+		speaker := turn.Speaker
+		if turn.IsAgent {
+			speaker = config.Name + " (you)"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", speaker, turn.Message)
+	}
+
+	previous := previousSummary
+	if previous == "" {
+		previous = "(none)"
+	}
+
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: "You maintain a running summary of a meeting for an AI assistant taking part in it. " +
+			"Keep who said what, decisions, open questions and commitments. Write plain prose of at most 150 words."},
+		{Role: llm.RoleUser, Content: fmt.Sprintf("Summary so far:\n%s\n\nNew conversation to fold in:\n%s\nReturn the updated summary only.", previous, transcript.String())},
+	}
+
+	response, err := provider.Chat(ctx, messages, nil)
+	c.recordLLMCall(response, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarise conversation: %w", err)
+	}
+
+	summary := strings.TrimSpace(response.Text)
+	if summary == "" {
+		return "", fmt.Errorf("failed to summarise conversation: empty summary")
+	}
+	return summary, nil
+}
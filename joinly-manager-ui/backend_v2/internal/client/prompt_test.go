@@ -0,0 +1,78 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/client/llm"
+	"joinly-manager/internal/models"
+)
+
+func TestBuildReplyMessages(t *testing.T) {
+	systemPrompt := "You are {agent_name}, the team's scrum master."
+	config := models.AgentConfig{Name: "Ada", SystemPrompt: &systemPrompt}
+	window := models.ConversationWindow{
+		Summary: "Bob asked about the release date.",
+		Turns: []models.ConversationEntry{
+			{Speaker: "Bob", Message: "Is the release on Friday?", Timestamp: time.Now()},
+			{Speaker: "Ada", Message: "Yes, Friday.", IsAgent: true, Timestamp: time.Now()},
+		},
+	}
+
+	messages := buildReplyMessages(config, "Carol", "What about QA?", window)
+	roles := make([]llm.Role, len(messages))
+	for i, message := range messages {
+		roles[i] = message.Role
+	}
+
+	expected := []llm.Role{llm.RoleSystem, llm.RoleSystem, llm.RoleUser, llm.RoleAssistant, llm.RoleUser}
+	if len(roles) != len(expected) {
+		t.Fatalf("Expected roles %v, got %v", expected, roles)
+	}
+	for i := range expected {
+		if roles[i] != expected[i] {
+			t.Fatalf("Expected roles %v, got %v", expected, roles)
+		}
+	}
+
+	if !strings.HasPrefix(messages[0].Content, "You are Ada, the team's scrum master.") || !strings.Contains(messages[0].Content, "assistant_reply") {
+		t.Errorf("Expected the rendered system prompt with reply format, got %q", messages[0].Content)
+	}
+	if messages[2].Content != "Bob: Is the release on Friday?" || messages[4].Content != "Carol: What about QA?" {
+		t.Errorf("Expected speaker-prefixed user turns, got %q and %q", messages[2].Content, messages[4].Content)
+	}
+}
+
+func TestBuildReplyMessages_CustomPrompt(t *testing.T) {
+	customPrompt := "{speaker} said {text}. Earlier: {context}"
+	config := models.AgentConfig{Name: "Ada", CustomPrompt: &customPrompt}
+
+	messages := buildReplyMessages(config, "Bob", "hi", models.ConversationWindow{})
+	last := messages[len(messages)-1]
+	if last.Content != "Bob said hi. Earlier: No previous context." {
+		t.Errorf("Unexpected rendered custom prompt %q", last.Content)
+	}
+	if strings.Contains(messages[0].Content, "assistant_reply") {
+		t.Error("Expected custom prompts to keep their own output format")
+	}
+}
+
+func TestBuildReplyMessages_MemoriesAndPassages(t *testing.T) {
+	config := models.AgentConfig{Name: "Ada"}
+	window := models.ConversationWindow{
+		Memories: []models.MemoryItem{{Kind: models.MemoryKindActionItem, Content: "Draft the release notes", Owner: "Bob"}},
+		Passages: []models.KnowledgePassage{{Citation: 1, Title: "Runbook", Section: "Rollback", Text: "Run the rollback job."}},
+	}
+
+	messages := buildReplyMessages(config, "Carol", "How do we roll back?", window)
+	if len(messages) != 4 {
+		t.Fatalf("Expected system, memory, knowledge and user messages, got %d", len(messages))
+	}
+	if !strings.Contains(messages[1].Content, "Open action item (owner: Bob") {
+		t.Errorf("Expected the open action item in the memory message, got %q", messages[1].Content)
+	}
+	if !strings.Contains(messages[2].Content, "[1] Runbook > Rollback\nRun the rollback job.") {
+		t.Errorf("Expected the numbered passage in the knowledge message, got %q", messages[2].Content)
+	}
+}
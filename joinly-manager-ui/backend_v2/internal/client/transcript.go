@@ -1,20 +1,29 @@
 package client
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/tracing"
 )
 
+// maxMeetingSegments bounds the meeting transcript kept for export; the oldest segments are dropped
+const maxMeetingSegments = 20000
+
 // utteranceUpdate processes transcript updates for utterances with enhanced consolidation
 func (c *JoinlyClient) utteranceUpdate(transcript interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if !c.isJoined {
+	if !c.isJoined || c.objected {
 		return
 	}
 
@@ -47,8 +56,10 @@ func (c *JoinlyClient) utteranceUpdate(transcript interface{}) {
 
 		// Get segment details for processing
 		text, _ := segmentMap["text"].(string)
+		isAgent := c.isAgentSpeaker(segmentMap)
+		c.recordSegmentUnsafe(segmentMap, startVal, isAgent)
 
-		if c.isAgentSpeaker(segmentMap) {
+		if isAgent {
 			// Check if we've already processed this assistant segment (by text content)
 			if c.hasProcessedSegment(text) {
 				continue
@@ -62,8 +73,15 @@ func (c *JoinlyClient) utteranceUpdate(transcript interface{}) {
 			if startVal <= c.lastSegmentStart { // already queued before
 				continue
 			}
+			// An objection ends the processing of the meeting
+			speaker, _ := segmentMap["speaker"].(string)
+			if c.checkObjectionUnsafe(speaker, text, ObjectionSourceSpeech) {
+				return
+			}
 			participantSegments = append(participantSegments, segmentMap)
 			newParticipantAdded = true
+			// A participant talking while the agent speaks may cut the agent off
+			c.noteParticipantSpeechUnsafe(text)
 			if startVal > latestStart {
 				latestStart = startVal
 			}
@@ -78,6 +96,9 @@ func (c *JoinlyClient) utteranceUpdate(transcript interface{}) {
 	// Handle participant segments (for traditional STT+LLM flow)
 	if len(participantSegments) > 0 {
 		// Add new segments to pending buffer
+		if len(c.pendingSegments) == 0 {
+			c.pendingSince = time.Now()
+		}
 		c.pendingSegments = append(c.pendingSegments, participantSegments...)
 		// Mark these as queued so we don't re-add on next poll before debounce fires
 		if newParticipantAdded {
@@ -87,26 +108,21 @@ func (c *JoinlyClient) utteranceUpdate(transcript interface{}) {
 		}
 		c.lastUtteranceTime = time.Now()
 
-		// Check name trigger for any pending segments
-		shouldTrigger := !c.config.NameTrigger
-		if c.config.NameTrigger {
-			for _, segment := range c.pendingSegments {
-				if text, ok := segment["text"].(string); ok {
-					if c.nameInText(text) {
-						shouldTrigger = true
-						break
-					}
-				}
-			}
+		// Name-triggered agents only answer once a segment is directed at them
+		shouldTrigger := !c.config.NameTrigger || c.pendingAddressed
+		if !shouldTrigger {
+			shouldTrigger = c.classifySegmentsUnsafe(participantSegments)
 		}
 
-		if shouldTrigger {
-			// Reset or start the debounce timer
+		// Utterances held during the objection window are processed once it has passed
+		if shouldTrigger && !c.consentHold {
+			// Reset or start the debounce timer; how long to wait depends on how the latest segment ends
 			if c.debounceTimer != nil {
 				c.debounceTimer.Stop()
 			}
 
-			c.debounceTimer = time.AfterFunc(c.utteranceDebounce, func() {
+			lastText, _ := c.pendingSegments[len(c.pendingSegments)-1]["text"].(string)
+			c.debounceTimer = time.AfterFunc(EndOfTurnDelay(c.config.TurnTaking, lastText), func() {
 				c.processConsolidatedUtterance(latestStart)
 			})
 		}
@@ -115,6 +131,9 @@ func (c *JoinlyClient) utteranceUpdate(transcript interface{}) {
 
 // handleAssistantSegments processes assistant response segments but does NOT speak them again
 func (c *JoinlyClient) handleAssistantSegments(segments []map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for _, segment := range segments {
 		if text, ok := segment["text"].(string); ok && strings.TrimSpace(text) != "" {
 			// Mark as processed so we don't try to speak these again
@@ -131,6 +150,13 @@ func (c *JoinlyClient) processConsolidatedUtterance(latestStart float64) {
 		return
 	}
 
+	// Utterances that do not name the agent wait for the LLM addressing check
+	if c.config.NameTrigger && !c.pendingAddressed && c.pendingNeedsCheck {
+		c.mu.Unlock()
+		c.checkPendingAddressing(latestStart)
+		return
+	}
+
 	// Update last utterance BEFORE calling callbacks
 	c.lastUtteranceStart = latestStart
 
@@ -148,16 +174,25 @@ func (c *JoinlyClient) processConsolidatedUtterance(latestStart float64) {
 	// Compute utterance hash and set lifecycle state to received (if new)
 	uttHash := c.hashText(combinedText)
 	if _, exists := c.utteranceStates[uttHash]; !exists {
-		c.utteranceStates[uttHash] = "received"
+		c.setUtteranceStateUnsafe(uttHash, "received")
 	}
 
+	// Start the utterance trace at the arrival of its first segment
+	ctx, utterance := tracing.StartUtterance(c.ctx, uttHash, c.pendingSince, c.onLatency,
+		attribute.String("agent.id", c.ID),
+		attribute.Int("utterance.segments", len(compactedSegments)),
+	)
+	utterance.RecordStage(tracing.StageDebounce, c.pendingSince, time.Now())
+
 	// Call utterance callbacks with all consolidated segments (non-blocking). Manager will handle LLM+TTS.
 	for _, callback := range c.utteranceCallbacks {
-		go callback(compactedSegments)
+		go callback(ctx, compactedSegments)
 	}
 
 	// Clear pending segments after processing
 	c.pendingSegments = make([]map[string]interface{}, 0)
+	c.pendingAddressed = false
+	c.pendingNeedsCheck = false
 	c.mu.Unlock()
 
 	// Do NOT generate response or speak here to avoid duplicate TTS. The manager owns LLM+TTS.
@@ -290,23 +325,85 @@ func (c *JoinlyClient) isAgentSpeaker(segment map[string]interface{}) bool {
 	return false
 }
 
-// nameInText checks if the agent's name is mentioned in the text
-func (c *JoinlyClient) nameInText(text string) bool {
-	if c.config.Name == "" {
-		return true // If no name set, always respond
+// classifySegmentsUnsafe decides whether new participant segments are directed at a
+// name-triggered agent (caller must hold lock). Segments that are not stay pending as context
+// for when the agent is addressed; with the LLM check enabled they are checked at end of turn.
+func (c *JoinlyClient) classifySegmentsUnsafe(segments []map[string]interface{}) bool {
+	for _, segment := range segments {
+		text, _ := segment["text"].(string)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		decision := classifyAddressing(c.config, text, c.lastReplyAt, time.Now())
+		if decision.Addressed || !llmAddressingCheck(c.config) {
+			c.noteAddressingDecision(decision, text)
+		}
+		if decision.Addressed {
+			c.pendingAddressed = true
+			return true
+		}
+	}
+
+	if llmAddressingCheck(c.config) {
+		c.pendingNeedsCheck = true
+		return true
+	}
+	return false
+}
+
+// checkPendingAddressing asks the LLM whether the pending segments are directed at the agent
+// and processes them if they are; otherwise they stay pending as context
+func (c *JoinlyClient) checkPendingAddressing(latestStart float64) {
+	c.mu.RLock()
+	checked := len(c.pendingSegments)
+	texts := make([]string, 0, checked)
+	for _, segment := range c.pendingSegments {
+		if text, ok := segment["text"].(string); ok && strings.TrimSpace(text) != "" {
+			texts = append(texts, strings.TrimSpace(text))
+		}
+	}
+	c.mu.RUnlock()
+	text := strings.Join(texts, " ")
+
+	var decision addressingDecision
+	if c.silenced() {
+		// A silenced agent does not answer, so the paid check is skipped
+		decision = addressingDecision{Reason: notAddressedSilenced}
+	} else {
+		var err error
+		decision, err = c.checkAddressedWithLLM(c.ctx, text)
+		if err != nil {
+			c.log("warn", fmt.Sprintf("Addressing check failed, not answering: %v", err))
+			decision = addressingDecision{Reason: notAddressedByLLM}
+		}
+	}
+	c.noteAddressingDecision(decision, text)
+
+	c.mu.Lock()
+	// Segments that arrived during the check restarted the end-of-turn timer, which
+	// processes (or checks) them together with these
+	moreArrived := len(c.pendingSegments) != checked
+	if decision.Addressed {
+		c.pendingAddressed = true
+	} else if !moreArrived {
+		c.pendingNeedsCheck = false
+	}
+	c.mu.Unlock()
+
+	if decision.Addressed && !moreArrived {
+		c.processConsolidatedUtterance(latestStart)
 	}
-	lowerText := strings.ToLower(text)
-	lowerName := strings.ToLower(c.config.Name)
-	return strings.Contains(lowerText, lowerName)
 }
 
-// hasProcessedSegment checks if we've already processed this assistant segment text (normalized)
+// hasProcessedSegment checks if we've already processed this assistant segment text (normalized,
+// caller must hold lock)
 func (c *JoinlyClient) hasProcessedSegment(text string) bool {
 	n := strings.ToLower(strings.TrimSpace(text))
 	return c.processedSegments[n]
 }
 
-// markSegmentProcessed marks an assistant segment text as processed to prevent repetition (normalized)
+// markSegmentProcessed marks an assistant segment text as processed to prevent repetition
+// (normalized, caller must hold lock)
 func (c *JoinlyClient) markSegmentProcessed(text string) {
 	n := strings.ToLower(strings.TrimSpace(text))
 	c.processedSegments[n] = true
@@ -315,9 +412,91 @@ func (c *JoinlyClient) markSegmentProcessed(text string) {
 	}
 }
 
+// setUtteranceState advances the lifecycle state of the utterance carried by the context
+func (c *JoinlyClient) setUtteranceState(ctx context.Context, state string) {
+	utterance := tracing.UtteranceFromContext(ctx)
+	if utterance == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setUtteranceStateUnsafe(utterance.ID, state)
+}
+
+// setUtteranceStateUnsafe records an utterance lifecycle state (caller must hold lock)
+func (c *JoinlyClient) setUtteranceStateUnsafe(hash, state string) {
+	// Keep the map bounded like processedSegments
+	if _, exists := c.utteranceStates[hash]; !exists && len(c.utteranceStates) >= 100 {
+		c.utteranceStates = make(map[string]string)
+	}
+	c.utteranceStates[hash] = state
+}
+
 // hashText returns a stable hash for utterance deduplication
 func (c *JoinlyClient) hashText(text string) string {
 	clean := strings.TrimSpace(text)
 	sum := sha256.Sum256([]byte(clean))
 	return hex.EncodeToString(sum[:])
 }
+
+// recordSegmentUnsafe adds a segment to the meeting transcript unless it was recorded by an
+// earlier poll (caller must hold lock)
+func (c *JoinlyClient) recordSegmentUnsafe(segment map[string]interface{}, start float64, isAgent bool) {
+	key := segmentKey(start, isAgent)
+	if c.recordedSegments[key] || (c.droppedAny && start <= c.droppedThrough) {
+		return
+	}
+
+	text, _ := segment["text"].(string)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	end, _ := segment["end"].(float64)
+	if end < start {
+		end = start
+	}
+	speaker, _ := segment["speaker"].(string)
+	if isAgent && (speaker == "" || speaker == "Assistant") {
+		speaker = c.config.Name
+	}
+	if !isAgent {
+		c.redactor.AddName(speaker)
+	}
+
+	c.recordedSegments[key] = true
+	c.meetingTranscript = append(c.meetingTranscript, models.MeetingSegment{
+		Start:   start,
+		End:     end,
+		Speaker: speaker,
+		Text:    text,
+		IsAgent: isAgent,
+	})
+
+	if excess := len(c.meetingTranscript) - maxMeetingSegments; excess > 0 {
+		for _, dropped := range c.meetingTranscript[:excess] {
+			delete(c.recordedSegments, segmentKey(dropped.Start, dropped.IsAgent))
+			if !c.droppedAny || dropped.Start > c.droppedThrough {
+				c.droppedThrough = dropped.Start
+			}
+			c.droppedAny = true
+		}
+		c.meetingTranscript = append([]models.MeetingSegment{}, c.meetingTranscript[excess:]...)
+	}
+}
+
+// segmentKey identifies a transcript segment across polls
+func segmentKey(start float64, isAgent bool) string {
+	return fmt.Sprintf("%.3f|%t", start, isAgent)
+}
+
+// MeetingTranscript returns every segment of the meeting so far, ordered by start time
+func (c *JoinlyClient) MeetingTranscript() []models.MeetingSegment {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	segments := append([]models.MeetingSegment{}, c.meetingTranscript...)
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start })
+	return segments
+}
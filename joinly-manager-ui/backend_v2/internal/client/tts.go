@@ -1,54 +1,130 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+
+	"joinly-manager/internal/metrics"
+	"joinly-manager/internal/tracing"
 )
 
-// SpeakText speaks the given text in the meeting (TTS functionality is handled server-side)
-// This is a placeholder since TTS is implemented server-side via MCP tools
-func (c *JoinlyClient) SpeakText(text string) error {
+// SpeakText speaks the given text in the meeting (TTS functionality is handled server-side).
+// The reply is spoken in sentence chunks: when a participant talks over the agent, joinly cuts
+// the current chunk short (or the agent is muted on barge-in) and the rest of the reply is
+// dropped. ctx carries the utterance trace the speak call is recorded under.
+func (c *JoinlyClient) SpeakText(ctx context.Context, text string) (SpeechResult, error) {
+	// Only hold the lock for the state check: speaking can take seconds and must not
+	// block transcript updates
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	isConnected := c.isConnected
+	isJoined := c.isJoined
+	ttsProvider := c.config.TTSProvider
+	mcpClient := c.client
+	c.mu.RUnlock()
 
-	if !c.isConnected {
-		return fmt.Errorf("client not connected")
+	if !isConnected {
+		return SpeechResult{}, fmt.Errorf("client not connected")
 	}
 
-	if !c.isJoined {
-		return fmt.Errorf("not joined to any meeting")
+	if !isJoined {
+		return SpeechResult{}, fmt.Errorf("not joined to any meeting")
 	}
 
-	c.log("info", fmt.Sprintf("🎵 Speaking text (TTS=%s): %s", c.config.TTSProvider, text))
+	c.log("info", fmt.Sprintf("🎵 Speaking text (TTS=%s): %s", ttsProvider, text))
+
+	_, endStage := tracing.StartStage(ctx, tracing.StageSpeak,
+		attribute.String("tts.provider", string(ttsProvider)),
+		attribute.Int("speak.chars", len(text)),
+	)
+
+	speakCtx, finishSpeaking := c.beginSpeaking(ctx)
+	var result SpeechResult
+	var spoken []string
+
+	start := time.Now()
+	for _, chunk := range speechChunks(text) {
+		if speakCtx.Err() != nil {
+			break
+		}
 
-	// Call the speak_text tool using MCP protocol (matches original joinly_client)
-	result, err := c.client.CallTool(c.ctx, mcp.CallToolRequest{
-		Params: mcp.CallToolParams{
-			Name: "speak_text",
-			Arguments: map[string]interface{}{
-				"text": text,
+		// Call the speak_text tool using MCP protocol (matches original joinly_client). A
+		// barge-in does not abandon the call: it mutes the agent and joinly reports how much of
+		// the chunk was spoken.
+		toolResult, err := mcpClient.CallTool(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "speak_text",
+				Arguments: map[string]interface{}{
+					"text": chunk,
+				},
 			},
-		},
-	})
+		})
 
-	if err != nil {
-		c.log("error", fmt.Sprintf("❌ Failed to speak text with TTS provider '%s': %v", c.config.TTSProvider, err))
-		return fmt.Errorf("failed to speak text: %w", err)
-	}
+		if err != nil {
+			if ctx.Err() != nil {
+				// A newer utterance stopped the reply mid-chunk, so the chunk was partly spoken
+				spoken = append(spoken, chunk+"...")
+				break
+			}
+			finishSpeaking()
+			metrics.ObserveSpeak(time.Since(start), err)
+			endStage(err)
+			c.log("error", fmt.Sprintf("❌ Failed to speak text with TTS provider '%s': %v", ttsProvider, err))
+			return SpeechResult{Spoken: strings.Join(spoken, " ")}, fmt.Errorf("failed to speak text: %w", err)
+		}
 
-	// Check if the tool call was successful
-	if result.IsError {
-		errorMsg := "unknown error"
-		if len(result.Content) > 0 {
-			if textContent, ok := mcp.AsTextContent(result.Content[0]); ok {
-				errorMsg = textContent.Text
+		resultText := ""
+		if len(toolResult.Content) > 0 {
+			if textContent, ok := mcp.AsTextContent(toolResult.Content[0]); ok {
+				resultText = textContent.Text
 			}
 		}
-		c.log("error", fmt.Sprintf("❌ Speak tool returned error with TTS provider '%s': %s", c.config.TTSProvider, errorMsg))
-		return fmt.Errorf("speak failed: %s", errorMsg)
+
+		// Check if the tool call was successful
+		if toolResult.IsError {
+			errorMsg := resultText
+			if errorMsg == "" {
+				errorMsg = "unknown error"
+			}
+			finishSpeaking()
+			c.log("error", fmt.Sprintf("❌ Speak tool returned error with TTS provider '%s': %s", ttsProvider, errorMsg))
+			err := fmt.Errorf("speak failed: %s", errorMsg)
+			metrics.ObserveSpeak(time.Since(start), err)
+			endStage(err)
+			return SpeechResult{Spoken: strings.Join(spoken, " ")}, err
+		}
+
+		// joinly stops speaking by itself when it detects speech over the agent
+		if partial, interrupted := parseInterruption(resultText); interrupted {
+			if partial != "" {
+				spoken = append(spoken, partial)
+			}
+			result.Interrupted = true
+			metrics.IncSpeechInterruptions("server")
+			break
+		}
+		spoken = append(spoken, chunk)
+	}
+
+	if finishSpeaking() || ctx.Err() != nil {
+		result.Interrupted = true
+	}
+	result.Spoken = strings.Join(spoken, " ")
+
+	metrics.ObserveSpeak(time.Since(start), nil)
+	endStage(nil)
+
+	if result.Interrupted {
+		c.setUtteranceState(ctx, "interrupted")
+		c.log("info", fmt.Sprintf("✋ Reply interrupted after: %s", result.Spoken))
+		return result, nil
 	}
 
+	c.setUtteranceState(ctx, "delivered")
 	c.log("info", "✅ Successfully spoke text")
-	return nil
+	return result, nil
 }
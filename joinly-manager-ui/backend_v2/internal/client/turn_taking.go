@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"joinly-manager/internal/metrics"
+	"joinly-manager/internal/models"
+)
+
+// End-of-turn defaults, used for zero values of models.TurnTakingConfig
+const (
+	defaultTurnDebounce       = 2 * time.Second
+	defaultQuestionDebounce   = 800 * time.Millisecond
+	defaultIncompleteDebounce = 3500 * time.Millisecond
+	defaultBargeInMinWords    = 2
+)
+
+// speechChunkChars is the size replies are split into for speaking, so that the rest of a
+// reply can be dropped when the agent is interrupted
+const speechChunkChars = 120
+
+// incompleteEndings are trailing words after which a speaker is usually not done
+var incompleteEndings = map[string]bool{
+	"and": true, "but": true, "or": true, "so": true, "because": true, "then": true,
+	"um": true, "uh": true, "erm": true, "like": true, "the": true, "a": true, "an": true,
+	"to": true, "of": true, "with": true, "if": true, "that": true, "which": true,
+}
+
+var (
+	sentenceEnd        = regexp.MustCompile(`[.!?]+["')\]]*\s+`)
+	interruptedPattern = regexp.MustCompile(`(?s)^Interrupted by detected speech\. Spoken until now: "(.*?)(?:\.\.\.)?"$`)
+)
+
+// SpeechResult reports how much of a reply reached the meeting
+type SpeechResult struct {
+	Spoken      string // Text that was actually spoken
+	Interrupted bool   // Whether a participant cut the agent off
+}
+
+// EndOfTurnDelay returns how long to wait for more speech before treating text as a finished
+// turn: shorter after a question, longer when the text trails off mid-sentence
+func EndOfTurnDelay(config *models.TurnTakingConfig, text string) time.Duration {
+	debounce, question, incomplete := defaultTurnDebounce, defaultQuestionDebounce, defaultIncompleteDebounce
+	if config != nil {
+		if config.DebounceMs > 0 {
+			debounce = time.Duration(config.DebounceMs) * time.Millisecond
+		}
+		if config.QuestionDebounceMs > 0 {
+			question = time.Duration(config.QuestionDebounceMs) * time.Millisecond
+		}
+		if config.IncompleteDebounceMs > 0 {
+			incomplete = time.Duration(config.IncompleteDebounceMs) * time.Millisecond
+		}
+	}
+
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return debounce
+	case strings.HasSuffix(text, "?"):
+		return question
+	case strings.HasSuffix(text, "...") || strings.HasSuffix(text, "…") || strings.ContainsAny(text[len(text)-1:], ",;:-"):
+		return incomplete
+	}
+
+	words := strings.Fields(strings.ToLower(text))
+	if last := strings.Trim(words[len(words)-1], ".!"); incompleteEndings[last] && !strings.HasSuffix(text, ".") {
+		return incomplete
+	}
+	return debounce
+}
+
+// bargeInEnabled reports whether participant speech interrupts the agent
+func bargeInEnabled(config *models.TurnTakingConfig) bool {
+	return config == nil || config.BargeIn == nil || *config.BargeIn
+}
+
+// isBargeIn reports whether a participant segment heard while the agent speaks should
+// interrupt it; short backchannels such as "mhm" or "okay" do not
+func isBargeIn(config *models.TurnTakingConfig, text string) bool {
+	if !bargeInEnabled(config) {
+		return false
+	}
+	minWords := defaultBargeInMinWords
+	if config != nil && config.BargeInMinWords > 0 {
+		minWords = config.BargeInMinWords
+	}
+	return len(strings.Fields(text)) >= minWords
+}
+
+// speechChunks splits a reply at sentence boundaries into pieces of about speechChunkChars
+func speechChunks(text string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	var chunks []string
+	var current strings.Builder
+	last := 0
+	for _, bounds := range sentenceEnd.FindAllStringIndex(text, -1) {
+		current.WriteString(text[last:bounds[1]])
+		last = bounds[1]
+		if current.Len() >= speechChunkChars {
+			chunks = append(chunks, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	current.WriteString(text[last:])
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		chunks = append(chunks, rest)
+	}
+
+	return chunks
+}
+
+// parseInterruption recognises the joinly speak_text result for speech cut off by detected
+// speech and returns the part that was spoken
+func parseInterruption(result string) (string, bool) {
+	match := interruptedPattern.FindStringSubmatch(strings.TrimSpace(result))
+	if match == nil {
+		return "", false
+	}
+	return strings.TrimSpace(match[1]), true
+}
+
+// noteParticipantSpeechUnsafe interrupts the agent when a participant talks over it (caller must hold lock)
+func (c *JoinlyClient) noteParticipantSpeechUnsafe(text string) {
+	if c.speakCancel == nil || c.bargedIn || !isBargeIn(c.config.TurnTaking, text) {
+		return
+	}
+
+	c.bargedIn = true
+	c.speakCancel()
+	metrics.IncSpeechInterruptions("barge_in")
+	c.log("info", "✋ Participant started speaking, stopping the current reply")
+
+	// joinly keeps playing a chunk whose call was abandoned, so mute the agent to stop it
+	muted := make(chan struct{})
+	c.speechMuted = muted
+	go func(mcpClient *client.Client) {
+		defer close(muted)
+		c.callMuteTool(mcpClient, "mute_yourself")
+	}(c.client)
+}
+
+// callMuteTool calls mute_yourself or unmute_yourself on the joinly server
+func (c *JoinlyClient) callMuteTool(mcpClient *client.Client, tool string) {
+	if mcpClient == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := mcpClient.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      tool,
+			Arguments: map[string]string{},
+		},
+	})
+	if err == nil && result.IsError {
+		err = fmt.Errorf("%s tool returned an error", tool)
+	}
+	if err != nil {
+		c.log("warn", fmt.Sprintf("Failed to call %s: %v", tool, err))
+	}
+}
+
+// beginSpeaking marks the agent as speaking; the returned context is cancelled on barge-in.
+// Finishing records when the agent last spoke, which starts the addressing follow-up window,
+// and unmutes the agent if a barge-in muted it.
+func (c *JoinlyClient) beginSpeaking(ctx context.Context) (context.Context, func() bool) {
+	speakCtx, cancel := context.WithCancel(ctx)
+
+	c.mu.Lock()
+	c.speakCancel = cancel
+	c.bargedIn = false
+	c.mu.Unlock()
+
+	return speakCtx, func() bool {
+		c.mu.Lock()
+		cancel()
+		c.speakCancel = nil
+		c.lastReplyAt = time.Now()
+		bargedIn, muted, mcpClient := c.bargedIn, c.speechMuted, c.client
+		c.speechMuted = nil
+		c.mu.Unlock()
+
+		if muted != nil {
+			<-muted
+			c.callMuteTool(mcpClient, "unmute_yourself")
+		}
+		return bargedIn
+	}
+}
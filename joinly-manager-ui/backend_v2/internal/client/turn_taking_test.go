@@ -0,0 +1,77 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+)
+
+func TestEndOfTurnDelay(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected time.Duration
+	}{
+		{"Can we ship on Friday?", defaultQuestionDebounce},
+		{"We could ship on Friday, and", defaultIncompleteDebounce},
+		{"I think the problem is, um", defaultIncompleteDebounce},
+		{"Let me check the numbers...", defaultIncompleteDebounce},
+		{"We ship on Friday.", defaultTurnDebounce},
+		{"", defaultTurnDebounce},
+	}
+	for _, tt := range tests {
+		if got := EndOfTurnDelay(nil, tt.text); got != tt.expected {
+			t.Errorf("EndOfTurnDelay(%q) = %v, want %v", tt.text, got, tt.expected)
+		}
+	}
+
+	config := &models.TurnTakingConfig{DebounceMs: 1200, QuestionDebounceMs: 400}
+	if got := EndOfTurnDelay(config, "Any questions?"); got != 400*time.Millisecond {
+		t.Errorf("configured question delay = %v", got)
+	}
+	if got := EndOfTurnDelay(config, "All done."); got != 1200*time.Millisecond {
+		t.Errorf("configured debounce = %v", got)
+	}
+}
+
+func TestIsBargeIn(t *testing.T) {
+	if isBargeIn(nil, "mhm") {
+		t.Error("single-word backchannel should not interrupt")
+	}
+	if !isBargeIn(nil, "Sorry, quick question") {
+		t.Error("expected barge-in")
+	}
+
+	disabled := false
+	if isBargeIn(&models.TurnTakingConfig{BargeIn: &disabled}, "Sorry, quick question") {
+		t.Error("barge-in should be disabled")
+	}
+	if isBargeIn(&models.TurnTakingConfig{BargeInMinWords: 4}, "Wait, stop") {
+		t.Error("expected configured minimum to apply")
+	}
+}
+
+func TestSpeechChunks(t *testing.T) {
+	reply := strings.Repeat("This sentence is about forty characters. ", 5) + "Done"
+	chunks := speechChunks(reply)
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %q", chunks)
+	}
+	if strings.Join(chunks, " ") != strings.TrimSpace(reply) {
+		t.Errorf("chunks do not reassemble the reply: %q", chunks)
+	}
+	if chunks := speechChunks("Short reply."); len(chunks) != 1 || chunks[0] != "Short reply." {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+}
+
+func TestParseInterruption(t *testing.T) {
+	spoken, interrupted := parseInterruption(`Interrupted by detected speech. Spoken until now: "We ship on..."`)
+	if !interrupted || spoken != "We ship on" {
+		t.Errorf("got %q, %v", spoken, interrupted)
+	}
+	if _, interrupted := parseInterruption("Finished."); interrupted {
+		t.Error("normal result parsed as interruption")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,10 +13,18 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Logging  LoggingConfig  `yaml:"logging"`
-	Joinly   JoinlyConfig   `yaml:"joinly"`
-	Database DatabaseConfig `yaml:"database"`
+	Server    ServerConfig    `yaml:"server"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Joinly    JoinlyConfig    `yaml:"joinly"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Billing   BillingConfig   `yaml:"billing"`
+	LLM       LLMConfig       `yaml:"llm"`
+	Memory    MemoryConfig    `yaml:"memory"`
+	Knowledge KnowledgeConfig `yaml:"knowledge"`
+	Archive   ArchiveConfig   `yaml:"archive"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Audit     AuditConfig     `yaml:"audit"`
 }
 
 // ServerConfig represents the server configuration
@@ -45,6 +54,84 @@ type JoinlyConfig struct {
 	DefaultURL     string        `yaml:"default_url"`
 	DefaultTimeout time.Duration `yaml:"default_timeout"`
 	MaxAgents      int           `yaml:"max_agents"`
+
+	// Backend pool: each joinly server can only sit in one meeting at a time
+	BackendURLs         []string      `yaml:"backend_urls"`          // Static pool members; DefaultURL is used when empty
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"` // How often pool members are probed
+	QueueWhenBusy       bool          `yaml:"queue_when_busy"`       // Wait for a free backend instead of failing fast
+	QueueTimeout        time.Duration `yaml:"queue_timeout"`         // Maximum wait for a free backend (0 = until stopped)
+}
+
+// TracingConfig represents OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter"`      // none, stdout or otlp
+	OTLPEndpoint string  `yaml:"otlp_endpoint"` // Base URL of an OTLP/HTTP collector; /v1/traces is appended
+	ServiceName  string  `yaml:"service_name"`
+	SampleRatio  float64 `yaml:"sample_ratio"` // Fraction of utterances traced (0-1)
+}
+
+// BillingConfig represents LLM cost accounting configuration
+type BillingConfig struct {
+	PriceFile string `yaml:"price_file"` // JSON file overriding the built-in price table
+}
+
+// LLMConfig represents retry and circuit breaker settings for LLM provider calls
+type LLMConfig struct {
+	MaxAttempts      int           `yaml:"max_attempts"`      // Attempts per provider before falling back
+	RetryBaseDelay   time.Duration `yaml:"retry_base_delay"`  // First backoff, doubled per retry
+	RetryMaxDelay    time.Duration `yaml:"retry_max_delay"`   // Longest single backoff (or honoured Retry-After)
+	BreakerThreshold int           `yaml:"breaker_threshold"` // Consecutive failures that open a provider's breaker
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`  // How long an open breaker skips the provider
+
+	Providers    map[string]LLMProviderConfig `yaml:"providers"`     // Endpoint overrides keyed by provider name
+	DebugCapture bool                         `yaml:"debug_capture"` // Capture redacted request/response pairs
+
+	Record   string `yaml:"record"`   // off, record (append provider answers to the cassette) or replay (answer from it)
+	Cassette string `yaml:"cassette"` // JSONL file of recorded exchanges
+}
+
+// LLMProviderConfig represents the HTTP endpoint settings of one LLM provider
+type LLMProviderConfig struct {
+	BaseURL string        `yaml:"base_url"` // API root for proxies and compatible gateways (empty = provider default)
+	Timeout time.Duration `yaml:"timeout"`  // Per-request timeout
+}
+
+// MemoryConfig represents long-term agent memory configuration
+type MemoryConfig struct {
+	Dir string `yaml:"dir"` // Directory holding one JSON file per agent identity
+}
+
+// KnowledgeConfig represents knowledge base retrieval configuration
+type KnowledgeConfig struct {
+	Dir            string `yaml:"dir"`             // Directory holding one JSON file per knowledge base
+	TopK           int    `yaml:"top_k"`           // Passages retrieved per utterance
+	EmbeddingModel string `yaml:"embedding_model"` // Ollama embedding model for semantic search (empty = BM25 only)
+}
+
+// ArchiveConfig represents meeting archive storage and retention configuration
+type ArchiveConfig struct {
+	Dir        string        `yaml:"dir"`         // Directory holding one JSON file per meeting session
+	Retention  time.Duration `yaml:"retention"`   // Age after which records and their analysis files are deleted (0 = keep)
+	MaxRecords int           `yaml:"max_records"` // Records kept at most, oldest deleted first (0 = unlimited)
+}
+
+// AuditConfig represents the audit trail of mutating API actions
+type AuditConfig struct {
+	Dir             string `yaml:"dir"`              // Directory holding the append-only audit log
+	PrincipalHeader string `yaml:"principal_header"` // Request header naming the authenticated user, set by the auth proxy
+}
+
+// StorageConfig represents where and how the manager persists meeting data
+type StorageConfig struct {
+	DataDir    string `yaml:"data_dir"`   // Root of the analysis, memory, knowledge and archive directories
+	Encryption string `yaml:"encryption"` // none, local or the name of a registered key provider plugin
+	KeyFile    string `yaml:"key_file"`   // Key file of the local key provider
+	KeyURI     string `yaml:"key_uri"`    // Key reference passed to key provider plugins, e.g. a KMS key ID
+}
+
+// AnalysisDir returns the directory analyst agents save their meeting analyses in
+func (c StorageConfig) AnalysisDir() string {
+	return filepath.Join(c.DataDir, "analysis")
 }
 
 // DatabaseConfig represents database configuration (for future use)
@@ -63,7 +150,7 @@ func DefaultConfig() *Config {
 			WriteTimeout: 30 * time.Second,
 			CORS: CORSConfig{
 				AllowedOrigins: []string{"http://localhost:3000"},
-				AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+				AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 				AllowedHeaders: []string{"*"},
 			},
 		},
@@ -72,14 +159,52 @@ func DefaultConfig() *Config {
 			Format: "json",
 		},
 		Joinly: JoinlyConfig{
-			DefaultURL:     "http://135.235.237.143:8000/mcp/",
-			DefaultTimeout: 30 * time.Second,
-			MaxAgents:      10,
+			DefaultURL:          "http://135.235.237.143:8000/mcp/",
+			DefaultTimeout:      30 * time.Second,
+			MaxAgents:           10,
+			HealthCheckInterval: 30 * time.Second,
+			QueueWhenBusy:       false,
+			QueueTimeout:        5 * time.Minute,
 		},
 		Database: DatabaseConfig{
 			Type: "memory",
 			URL:  "",
 		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "http://localhost:4318",
+			ServiceName:  "joinly-manager",
+			SampleRatio:  1.0,
+		},
+		LLM: LLMConfig{
+			MaxAttempts:      3,
+			RetryBaseDelay:   500 * time.Millisecond,
+			RetryMaxDelay:    8 * time.Second,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+			Providers:        make(map[string]LLMProviderConfig),
+			Record:           "off",
+			Cassette:         "data/llm_cassette.jsonl",
+		},
+		Memory: MemoryConfig{
+			Dir: "data/memory",
+		},
+		Knowledge: KnowledgeConfig{
+			Dir:  "data/knowledge",
+			TopK: 3,
+		},
+		Archive: ArchiveConfig{
+			Dir:       "data/archive",
+			Retention: 90 * 24 * time.Hour,
+		},
+		Storage: StorageConfig{
+			DataDir:    "data",
+			Encryption: "none",
+		},
+		Audit: AuditConfig{
+			Dir:             "data/audit",
+			PrincipalHeader: "X-Forwarded-User",
+		},
 	}
 }
 
@@ -107,6 +232,16 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	// The data directory moves every directory below it that is not configured explicitly
+	if dir := os.Getenv("DATA_DIR"); dir != "" {
+		cfg.Storage.DataDir = dir
+		cfg.LLM.Cassette = filepath.Join(dir, "llm_cassette.jsonl")
+		cfg.Memory.Dir = filepath.Join(dir, "memory")
+		cfg.Knowledge.Dir = filepath.Join(dir, "knowledge")
+		cfg.Archive.Dir = filepath.Join(dir, "archive")
+		cfg.Audit.Dir = filepath.Join(dir, "audit")
+	}
+
 	// Override with environment variables
 	if host := os.Getenv("SERVER_HOST"); host != "" {
 		cfg.Server.Host = host
@@ -136,6 +271,171 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if urls := os.Getenv("JOINLY_BACKEND_URLS"); urls != "" {
+		cfg.Joinly.BackendURLs = splitList(urls)
+	}
+
+	if interval := os.Getenv("JOINLY_HEALTH_CHECK_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			cfg.Joinly.HealthCheckInterval = d
+		}
+	}
+
+	if queue := os.Getenv("JOINLY_QUEUE_WHEN_BUSY"); queue != "" {
+		if q, err := strconv.ParseBool(queue); err == nil {
+			cfg.Joinly.QueueWhenBusy = q
+		}
+	}
+
+	if timeout := os.Getenv("JOINLY_QUEUE_TIMEOUT"); timeout != "" {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Joinly.QueueTimeout = d
+		}
+	}
+
+	if exporter := os.Getenv("TRACING_EXPORTER"); exporter != "" {
+		cfg.Tracing.Exporter = exporter
+	}
+
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		cfg.Tracing.OTLPEndpoint = endpoint
+	}
+
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		cfg.Tracing.ServiceName = name
+	}
+
+	if ratio := os.Getenv("TRACING_SAMPLE_RATIO"); ratio != "" {
+		if r, err := strconv.ParseFloat(ratio, 64); err == nil {
+			cfg.Tracing.SampleRatio = r
+		}
+	}
+
+	if priceFile := os.Getenv("LLM_PRICE_FILE"); priceFile != "" {
+		cfg.Billing.PriceFile = priceFile
+	}
+
+	if attempts := os.Getenv("LLM_MAX_ATTEMPTS"); attempts != "" {
+		if a, err := strconv.Atoi(attempts); err == nil {
+			cfg.LLM.MaxAttempts = a
+		}
+	}
+
+	if delay := os.Getenv("LLM_RETRY_BASE_DELAY"); delay != "" {
+		if d, err := time.ParseDuration(delay); err == nil {
+			cfg.LLM.RetryBaseDelay = d
+		}
+	}
+
+	if delay := os.Getenv("LLM_RETRY_MAX_DELAY"); delay != "" {
+		if d, err := time.ParseDuration(delay); err == nil {
+			cfg.LLM.RetryMaxDelay = d
+		}
+	}
+
+	if threshold := os.Getenv("LLM_BREAKER_THRESHOLD"); threshold != "" {
+		if t, err := strconv.Atoi(threshold); err == nil {
+			cfg.LLM.BreakerThreshold = t
+		}
+	}
+
+	if cooldown := os.Getenv("LLM_BREAKER_COOLDOWN"); cooldown != "" {
+		if d, err := time.ParseDuration(cooldown); err == nil {
+			cfg.LLM.BreakerCooldown = d
+		}
+	}
+
+	// Per-provider endpoints: <PROVIDER>_BASE_URL and <PROVIDER>_TIMEOUT (Ollama keeps OLLAMA_URL)
+	for _, provider := range []string{"openai", "anthropic", "google", "ollama", "openai_compatible"} {
+		prefix := strings.ToUpper(provider)
+		settings := cfg.LLM.Providers[provider]
+
+		if baseURL := os.Getenv(prefix + "_BASE_URL"); baseURL != "" {
+			settings.BaseURL = baseURL
+		}
+		if timeout := os.Getenv(prefix + "_TIMEOUT"); timeout != "" {
+			if d, err := time.ParseDuration(timeout); err == nil {
+				settings.Timeout = d
+			}
+		}
+
+		if settings != (LLMProviderConfig{}) {
+			cfg.LLM.Providers[provider] = settings
+		}
+	}
+
+	if capture := os.Getenv("LLM_DEBUG_CAPTURE"); capture != "" {
+		if c, err := strconv.ParseBool(capture); err == nil {
+			cfg.LLM.DebugCapture = c
+		}
+	}
+
+	if record := os.Getenv("LLM_RECORD"); record != "" {
+		cfg.LLM.Record = record
+	}
+
+	if cassette := os.Getenv("LLM_CASSETTE"); cassette != "" {
+		cfg.LLM.Cassette = cassette
+	}
+
+	if dir := os.Getenv("MEMORY_DIR"); dir != "" {
+		cfg.Memory.Dir = dir
+	}
+
+	if dir := os.Getenv("KNOWLEDGE_DIR"); dir != "" {
+		cfg.Knowledge.Dir = dir
+	}
+
+	if topK := os.Getenv("KNOWLEDGE_TOP_K"); topK != "" {
+		if k, err := strconv.Atoi(topK); err == nil {
+			cfg.Knowledge.TopK = k
+		}
+	}
+
+	if model := os.Getenv("KNOWLEDGE_EMBEDDING_MODEL"); model != "" {
+		cfg.Knowledge.EmbeddingModel = model
+	}
+
+	if dir := os.Getenv("ARCHIVE_DIR"); dir != "" {
+		cfg.Archive.Dir = dir
+	}
+
+	if retention := os.Getenv("ARCHIVE_RETENTION"); retention != "" {
+		if d, err := time.ParseDuration(retention); err == nil {
+			cfg.Archive.Retention = d
+		}
+	}
+
+	if maxRecords := os.Getenv("ARCHIVE_MAX_RECORDS"); maxRecords != "" {
+		if n, err := strconv.Atoi(maxRecords); err == nil {
+			cfg.Archive.MaxRecords = n
+		}
+	}
+
+	if dir := os.Getenv("AUDIT_DIR"); dir != "" {
+		cfg.Audit.Dir = dir
+	}
+
+	if header := os.Getenv("AUDIT_PRINCIPAL_HEADER"); header != "" {
+		cfg.Audit.PrincipalHeader = header
+	}
+
+	if encryption := os.Getenv("ENCRYPTION_PROVIDER"); encryption != "" {
+		cfg.Storage.Encryption = encryption
+	}
+
+	if keyFile := os.Getenv("ENCRYPTION_KEY_FILE"); keyFile != "" {
+		cfg.Storage.KeyFile = keyFile
+	}
+
+	if keyURI := os.Getenv("ENCRYPTION_KEY_URI"); keyURI != "" {
+		cfg.Storage.KeyURI = keyURI
+	}
+
+	if cfg.Storage.KeyFile == "" {
+		cfg.Storage.KeyFile = filepath.Join(cfg.Storage.DataDir, "keys", "master.json")
+	}
+
 	return cfg, nil
 }
 
@@ -163,3 +463,14 @@ func SetupLogging(cfg *LoggingConfig) error {
 
 	return nil
 }
+
+// splitList splits a comma-separated environment value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
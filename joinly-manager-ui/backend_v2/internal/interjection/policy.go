@@ -0,0 +1,214 @@
+package interjection
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"joinly-manager/internal/models"
+)
+
+// Policy defaults, used for zero values of models.InterjectionConfig
+const (
+	DefaultCooldown        = 30 * time.Second
+	DefaultMaxPer10Minutes = 5
+)
+
+// RateWindow is the window MaxPer10Minutes counts interjections in
+const RateWindow = 10 * time.Minute
+
+// minSharedTerms is how many content words an utterance must share with an action item to be related
+const minSharedTerms = 2
+
+// questionStarts are words that open a question even when STT drops the question mark
+var questionStarts = map[string]bool{
+	"who": true, "what": true, "when": true, "where": true, "why": true, "how": true, "which": true,
+	"can": true, "could": true, "would": true, "should": true, "is": true, "are": true, "do": true,
+	"does": true, "did": true, "will": true, "has": true, "have": true, "anyone": true, "any": true,
+}
+
+// fillers are skipped before the first word of a question
+var fillers = map[string]bool{"so": true, "ok": true, "okay": true, "and": true, "um": true, "uh": true, "hey": true, "well": true}
+
+// commonWords do not make an utterance related to an action item
+var commonWords = map[string]bool{
+	"about": true, "after": true, "again": true, "also": true, "been": true, "before": true, "could": true,
+	"does": true, "from": true, "have": true, "just": true, "know": true, "like": true, "make": true,
+	"need": true, "next": true, "should": true, "that": true, "their": true, "them": true, "then": true,
+	"there": true, "they": true, "this": true, "week": true, "what": true, "when": true, "where": true,
+	"which": true, "will": true, "with": true, "would": true, "your": true,
+}
+
+// Decision represents whether an agent speaks up about an utterance and why
+type Decision struct {
+	Speak    bool
+	Rule     models.InterjectionRule // Rule that made the agent speak, if any
+	Reason   string                  // Explanation logged for tuning
+	Guidance string                  // What the reply should bring up
+}
+
+// Signals are what is known about an utterance when deciding
+type Signals struct {
+	Question   bool
+	ActionItem *models.MemoryItem // Related open action item
+	Correction string             // Factual error found in the utterance, if checked and found
+	Checked    bool               // Whether the utterance was checked for factual errors
+}
+
+// RuleEnabled reports whether a policy speaks up for the given rule
+func RuleEnabled(config *models.InterjectionConfig, rule models.InterjectionRule) bool {
+	if config == nil || len(config.Rules) == 0 {
+		return true
+	}
+	for _, enabled := range config.Rules {
+		if enabled == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidRule reports whether rule is a known interjection rule
+func ValidRule(rule models.InterjectionRule) bool {
+	switch rule {
+	case models.InterjectionDirectQuestion, models.InterjectionFactualCorrection, models.InterjectionActionItem:
+		return true
+	}
+	return false
+}
+
+// Throttled returns why the agent may not speak up now given its recent interjections,
+// oldest first, or "" if it may
+func Throttled(config *models.InterjectionConfig, recent []time.Time, now time.Time) string {
+	cooldown, limit := DefaultCooldown, DefaultMaxPer10Minutes
+	if config != nil {
+		if config.CooldownSeconds > 0 {
+			cooldown = time.Duration(config.CooldownSeconds) * time.Second
+		}
+		if config.MaxPer10Minutes > 0 {
+			limit = config.MaxPer10Minutes
+		}
+	}
+
+	if len(recent) > 0 {
+		if since := now.Sub(recent[len(recent)-1]); since < cooldown {
+			return fmt.Sprintf("cooling down: last interjection %s ago (cooldown %s)", since.Round(time.Second), cooldown)
+		}
+	}
+
+	count := 0
+	for _, at := range recent {
+		if now.Sub(at) < RateWindow {
+			count++
+		}
+	}
+	if count >= limit {
+		return fmt.Sprintf("limit reached: %d interjections in the last 10 minutes", count)
+	}
+	return ""
+}
+
+// Decide applies the policy's rules to the signals of an utterance
+func Decide(config *models.InterjectionConfig, signals Signals) Decision {
+	if signals.Question && RuleEnabled(config, models.InterjectionDirectQuestion) {
+		return Decision{Speak: true, Rule: models.InterjectionDirectQuestion, Reason: "direct question"}
+	}
+	if signals.Correction != "" && RuleEnabled(config, models.InterjectionFactualCorrection) {
+		return Decision{
+			Speak:    true,
+			Rule:     models.InterjectionFactualCorrection,
+			Reason:   "factual error: " + signals.Correction,
+			Guidance: "Politely correct this factual error: " + signals.Correction,
+		}
+	}
+	if item := signals.ActionItem; item != nil && RuleEnabled(config, models.InterjectionActionItem) {
+		owner := ""
+		if item.Owner != "" {
+			owner = fmt.Sprintf(" (owner: %s)", item.Owner)
+		}
+		return Decision{
+			Speak:    true,
+			Rule:     models.InterjectionActionItem,
+			Reason:   fmt.Sprintf("related open action item %q%s", item.Content, owner),
+			Guidance: fmt.Sprintf("Briefly point out the related open action item: %s%s", item.Content, owner),
+		}
+	}
+
+	var why []string
+	if RuleEnabled(config, models.InterjectionDirectQuestion) {
+		why = append(why, "not a question")
+	}
+	if RuleEnabled(config, models.InterjectionFactualCorrection) {
+		if signals.Checked {
+			why = append(why, "no factual error")
+		} else {
+			why = append(why, "not checked for factual errors")
+		}
+	}
+	if RuleEnabled(config, models.InterjectionActionItem) {
+		why = append(why, "no related action item")
+	}
+	return Decision{Reason: strings.Join(why, ", ")}
+}
+
+// IsDirectQuestion reports whether an utterance asks a question, by its question mark or, since
+// STT often drops it, by its first word
+func IsDirectQuestion(text string) bool {
+	text = strings.TrimSpace(text)
+	if strings.HasSuffix(text, "?") {
+		return true
+	}
+	for _, word := range words(text) {
+		if fillers[word] {
+			continue
+		}
+		return questionStarts[word]
+	}
+	return false
+}
+
+// RelatedActionItem returns the open action item sharing the most content words with the
+// utterance, if it shares at least minSharedTerms
+func RelatedActionItem(text string, memories []models.MemoryItem) *models.MemoryItem {
+	terms := contentWords(text)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var best *models.MemoryItem
+	bestShared := minSharedTerms - 1
+	for i, item := range memories {
+		if item.Kind != models.MemoryKindActionItem || item.Done {
+			continue
+		}
+		shared := 0
+		for term := range contentWords(item.Content) {
+			if terms[term] {
+				shared++
+			}
+		}
+		if shared > bestShared {
+			best, bestShared = &memories[i], shared
+		}
+	}
+	return best
+}
+
+// words returns the lowercase words of text
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// contentWords returns the distinct words of text that carry meaning
+func contentWords(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, word := range words(text) {
+		if len(word) >= 4 && !commonWords[word] {
+			terms[strings.TrimSuffix(word, "s")] = true
+		}
+	}
+	return terms
+}
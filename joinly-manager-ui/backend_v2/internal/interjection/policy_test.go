@@ -0,0 +1,77 @@
+package interjection
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+)
+
+func TestIsDirectQuestion(t *testing.T) {
+	questions := []string{"When is the release?", "so how do we roll back", "Can someone share the doc"}
+	for _, text := range questions {
+		if !IsDirectQuestion(text) {
+			t.Errorf("expected %q to be a question", text)
+		}
+	}
+	statements := []string{"The release is on Friday.", "Okay let's move on", ""}
+	for _, text := range statements {
+		if IsDirectQuestion(text) {
+			t.Errorf("expected %q not to be a question", text)
+		}
+	}
+}
+
+func TestRelatedActionItem(t *testing.T) {
+	memories := []models.MemoryItem{
+		{Kind: models.MemoryKindFact, Content: "The staging database runs Postgres 15"},
+		{Kind: models.MemoryKindActionItem, Content: "Migrate the staging database to Postgres 16", Owner: "Bob"},
+		{Kind: models.MemoryKindActionItem, Content: "Rotate the staging database credentials", Done: true},
+	}
+
+	item := RelatedActionItem("We still need to look at the staging database upgrade", memories)
+	if item == nil || item.Owner != "Bob" {
+		t.Fatalf("expected the open migration item, got %+v", item)
+	}
+	if item := RelatedActionItem("The weather is nice today", memories); item != nil {
+		t.Errorf("unrelated utterance matched %+v", item)
+	}
+}
+
+func TestThrottled(t *testing.T) {
+	now := time.Now()
+	if reason := Throttled(nil, []time.Time{now.Add(-10 * time.Second)}, now); !strings.HasPrefix(reason, "cooling down") {
+		t.Errorf("expected cooldown, got %q", reason)
+	}
+
+	config := &models.InterjectionConfig{CooldownSeconds: 5, MaxPer10Minutes: 2}
+	recent := []time.Time{now.Add(-5 * time.Minute), now.Add(-time.Minute)}
+	if reason := Throttled(config, recent, now); !strings.HasPrefix(reason, "limit reached") {
+		t.Errorf("expected rate limit, got %q", reason)
+	}
+	if reason := Throttled(config, recent[1:], now); reason != "" {
+		t.Errorf("expected no throttling, got %q", reason)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	item := &models.MemoryItem{Kind: models.MemoryKindActionItem, Content: "Update the runbook", Owner: "Ann"}
+
+	if decision := Decide(nil, Signals{Question: true, ActionItem: item}); decision.Rule != models.InterjectionDirectQuestion {
+		t.Errorf("question should win, got %+v", decision)
+	}
+	if decision := Decide(nil, Signals{Correction: "Postgres 16 was released in 2023", Checked: true}); decision.Rule != models.InterjectionFactualCorrection || decision.Guidance == "" {
+		t.Errorf("expected correction, got %+v", decision)
+	}
+
+	onlyActionItems := &models.InterjectionConfig{Rules: []models.InterjectionRule{models.InterjectionActionItem}}
+	if decision := Decide(onlyActionItems, Signals{Question: true}); decision.Speak {
+		t.Errorf("disabled rule made the agent speak: %+v", decision)
+	} else if decision.Reason != "no related action item" {
+		t.Errorf("unexpected explanation %q", decision.Reason)
+	}
+	if decision := Decide(onlyActionItems, Signals{ActionItem: item}); !decision.Speak || !strings.Contains(decision.Guidance, "Ann") {
+		t.Errorf("expected action item interjection, got %+v", decision)
+	}
+}
@@ -0,0 +1,467 @@
+// Package joinlytest provides an in-process fake of the joinly MCP server, so that agents can
+// be tested end to end without a browser, meeting platform or speech services. Tests script
+// the meeting (participants speaking, chat messages, interruptions, tool failures) and inspect
+// what the agent said and sent. Transcript replays use it to stand in for the recorded meeting.
+package joinlytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Resource URIs served by joinly
+const (
+	SegmentsURI   = "transcript://live/segments"
+	TranscriptURI = "transcript://live"
+	UsageURI      = "usage://current"
+)
+
+// Segment is a transcript segment as joinly reports it
+type Segment struct {
+	Text    string  `json:"text"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker"`
+	Role    string  `json:"role"` // participant or assistant
+}
+
+// ChatMessage is a meeting chat message
+type ChatMessage struct {
+	Text      string  `json:"text"`
+	Timestamp float64 `json:"timestamp"`
+	Sender    string  `json:"sender"`
+}
+
+// Join records the join_meeting call of the agent
+type Join struct {
+	MeetingURL      string
+	ParticipantName string
+}
+
+// Server is a fake joinly MCP server listening on a local port
+type Server struct {
+	URL string // MCP endpoint to configure as the joinly backend
+
+	mcp  *server.MCPServer
+	http *httptest.Server
+
+	mu           sync.Mutex
+	start        time.Time
+	settings     map[string]interface{} // Last joinly-settings header
+	join         *Join
+	segments     []Segment
+	spoken       []string
+	chat         []ChatMessage
+	participants []string
+	speakDelay   time.Duration
+	interrupt    bool              // Interrupt the next speak_text call
+	muted        bool              // Set by mute_yourself until unmute_yourself
+	stopSpeech   chan struct{}     // Closed when muting cuts the current speech short
+	failures     map[string]string // Tool name -> error returned by it
+	calls        map[string]int    // Tool name -> number of calls
+}
+
+// NewServer starts a fake joinly server; Close it when done
+func NewServer() *Server {
+	s := &Server{
+		start:    time.Now(),
+		failures: make(map[string]string),
+		calls:    make(map[string]int),
+	}
+
+	s.mcp = server.NewMCPServer("joinly-fake", "0.0.0",
+		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(true, false),
+	)
+	s.addTools()
+	s.addResources()
+
+	s.http = server.NewTestStreamableHTTPServer(s.mcp, server.WithHTTPContextFunc(s.captureSettings))
+	s.URL = s.http.URL + "/mcp"
+	return s
+}
+
+// Close shuts the server down, dropping connections of clients still listening
+func (s *Server) Close() {
+	s.http.CloseClientConnections()
+	s.http.Close()
+}
+
+// Say adds a participant segment to the live transcript and notifies listening clients
+func (s *Server) Say(speaker, text string) {
+	s.addSegment(speaker, "participant", text)
+}
+
+// PostChat adds a chat message from a participant
+func (s *Server) PostChat(sender, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chat = append(s.chat, ChatMessage{Text: text, Timestamp: s.elapsedUnsafe(), Sender: sender})
+}
+
+// AddParticipant adds a participant to the meeting
+func (s *Server) AddParticipant(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.participants = append(s.participants, name)
+}
+
+// SetSpeakDelay makes speak_text take the given time, as speaking does in a real meeting
+func (s *Server) SetSpeakDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speakDelay = delay
+}
+
+// InterruptNextSpeech makes the next speak_text call report that speech was detected after half
+// of its text, like joinly does when a participant talks over the agent
+func (s *Server) InterruptNextSpeech() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interrupt = true
+}
+
+// Muted reports whether the agent is muted
+func (s *Server) Muted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.muted
+}
+
+// FailTool makes every call to a tool return message as an error; an empty message clears it
+func (s *Server) FailTool(name, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if message == "" {
+		delete(s.failures, name)
+		return
+	}
+	s.failures[name] = message
+}
+
+// Joined returns how the agent joined the meeting, if it did
+func (s *Server) Joined() (Join, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.join == nil {
+		return Join{}, false
+	}
+	return *s.join, true
+}
+
+// Settings returns the joinly-settings header of the last request
+func (s *Server) Settings() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings
+}
+
+// Spoken returns everything the agent said, in order
+func (s *Server) Spoken() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.spoken...)
+}
+
+// ChatMessages returns the chat history, including messages the agent sent
+func (s *Server) ChatMessages() []ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ChatMessage(nil), s.chat...)
+}
+
+// Segments returns the live transcript
+func (s *Server) Segments() []Segment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Segment(nil), s.segments...)
+}
+
+// Calls returns how often a tool was called
+func (s *Server) Calls(tool string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[tool]
+}
+
+// WaitFor polls until condition holds or the timeout passes, and reports whether it held
+func (s *Server) WaitFor(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if condition() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// WaitForSpeech waits until the agent said at least n things and returns what it said
+func (s *Server) WaitForSpeech(n int, timeout time.Duration) ([]string, error) {
+	if !s.WaitFor(timeout, func() bool { return len(s.Spoken()) >= n }) {
+		spoken := s.Spoken()
+		return spoken, fmt.Errorf("agent said %d things within %s, expected %d: %q", len(spoken), timeout, n, spoken)
+	}
+	return s.Spoken(), nil
+}
+
+// captureSettings records the joinly-settings header the client connects with
+func (s *Server) captureSettings(ctx context.Context, r *http.Request) context.Context {
+	if header := r.Header.Get("joinly-settings"); header != "" {
+		var settings map[string]interface{}
+		if err := json.Unmarshal([]byte(header), &settings); err == nil {
+			s.mu.Lock()
+			s.settings = settings
+			s.mu.Unlock()
+		}
+	}
+	return ctx
+}
+
+// addSegment appends a segment to the transcript and notifies clients listening for updates
+func (s *Server) addSegment(speaker, role, text string) {
+	s.mu.Lock()
+	start := s.elapsedUnsafe()
+	if n := len(s.segments); n > 0 && start <= s.segments[n-1].Start {
+		start = s.segments[n-1].Start + 0.001
+	}
+	// Roughly three words per second
+	end := start + float64(len(strings.Fields(text)))/3
+	s.segments = append(s.segments, Segment{Text: text, Start: start, End: end, Speaker: speaker, Role: role})
+	s.mu.Unlock()
+
+	for _, uri := range []string{SegmentsURI, TranscriptURI} {
+		s.mcp.SendNotificationToAllClients(string(mcp.MethodNotificationResourceUpdated), map[string]any{"uri": uri})
+	}
+}
+
+// elapsedUnsafe returns the meeting time in seconds (caller must hold lock)
+func (s *Server) elapsedUnsafe() float64 {
+	return time.Since(s.start).Seconds()
+}
+
+// tool wraps a tool handler with call counting and scripted failures
+func (s *Server) tool(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.mu.Lock()
+		s.calls[name]++
+		failure, failing := s.failures[name]
+		s.mu.Unlock()
+
+		if failing {
+			return mcp.NewToolResultError(failure), nil
+		}
+		return handler(ctx, request)
+	}
+}
+
+// addTools registers the joinly tools the manager uses
+func (s *Server) addTools() {
+	s.mcp.AddTool(mcp.NewTool("join_meeting",
+		mcp.WithString("meeting_url"),
+		mcp.WithString("participant_name"),
+	), s.tool("join_meeting", s.joinMeeting))
+
+	s.mcp.AddTool(mcp.NewTool("leave_meeting"), s.tool("leave_meeting", s.leaveMeeting))
+
+	s.mcp.AddTool(mcp.NewTool("speak_text",
+		mcp.WithString("text", mcp.Required()),
+	), s.tool("speak_text", s.speakText))
+
+	s.mcp.AddTool(mcp.NewTool("send_chat_message",
+		mcp.WithString("message", mcp.Required()),
+	), s.tool("send_chat_message", s.sendChatMessage))
+
+	s.mcp.AddTool(mcp.NewTool("mute_yourself"), s.tool("mute_yourself", s.muteYourself))
+	s.mcp.AddTool(mcp.NewTool("unmute_yourself"), s.tool("unmute_yourself", s.unmuteYourself))
+
+	s.mcp.AddTool(mcp.NewTool("get_chat_history"), s.tool("get_chat_history", s.getChatHistory))
+	s.mcp.AddTool(mcp.NewTool("get_participants"), s.tool("get_participants", s.getParticipants))
+	s.mcp.AddTool(mcp.NewTool("get_transcript"), s.tool("get_transcript", s.getTranscript))
+}
+
+// addResources registers the live transcript and usage resources
+func (s *Server) addResources() {
+	for _, uri := range []string{SegmentsURI, TranscriptURI} {
+		s.mcp.AddResource(mcp.NewResource(uri, "Live transcript", mcp.WithMIMEType("application/json")),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return jsonResource(request.Params.URI, s.transcript())
+			})
+	}
+
+	s.mcp.AddResource(mcp.NewResource(UsageURI, "Usage", mcp.WithMIMEType("application/json")),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return jsonResource(UsageURI, map[string]interface{}{"usage": map[string]interface{}{}})
+		})
+}
+
+// joinMeeting records the meeting the agent joined
+func (s *Server) joinMeeting(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.join != nil {
+		return mcp.NewToolResultError("Already joined a meeting"), nil
+	}
+	s.join = &Join{
+		MeetingURL:      request.GetString("meeting_url", ""),
+		ParticipantName: request.GetString("participant_name", ""),
+	}
+	return mcp.NewToolResultText("Joined meeting."), nil
+}
+
+// leaveMeeting leaves the meeting
+func (s *Server) leaveMeeting(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.join == nil {
+		return mcp.NewToolResultError("Not in a meeting"), nil
+	}
+	s.join = nil
+	return mcp.NewToolResultText("Left the meeting."), nil
+}
+
+// speakText "speaks" the text: it waits for the speak delay, honours a scripted interruption
+// and adds what was spoken to the transcript as an assistant segment. Like joinly, it keeps
+// speaking when the client gives up on the call; muting the agent cuts the speech short.
+func (s *Server) speakText(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	text, err := request.RequireString("text")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	s.mu.Lock()
+	if s.join == nil {
+		s.mu.Unlock()
+		return mcp.NewToolResultError("Not in a meeting"), nil
+	}
+	delay, interrupt := s.speakDelay, s.interrupt
+	s.interrupt = false
+	name := s.join.ParticipantName
+	stop := make(chan struct{})
+	s.stopSpeech = stop
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-stop:
+			interrupt = true
+		}
+	}
+
+	spoken := text
+	if interrupt {
+		words := strings.Fields(text)
+		spoken = strings.Join(words[:len(words)/2], " ")
+	}
+
+	s.mu.Lock()
+	if s.stopSpeech == stop {
+		s.stopSpeech = nil
+	}
+	s.spoken = append(s.spoken, spoken)
+	s.mu.Unlock()
+	if spoken != "" {
+		s.addSegment(name, "assistant", spoken)
+	}
+
+	if interrupt {
+		return mcp.NewToolResultText(fmt.Sprintf("Interrupted by detected speech. Spoken until now: %q", spoken+"...")), nil
+	}
+	return mcp.NewToolResultText("Finished."), nil
+}
+
+// muteYourself mutes the agent, stopping the speech in progress
+func (s *Server) muteYourself(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.muted = true
+	if s.stopSpeech != nil {
+		close(s.stopSpeech)
+		s.stopSpeech = nil
+	}
+	return mcp.NewToolResultText("Muted."), nil
+}
+
+// unmuteYourself unmutes the agent
+func (s *Server) unmuteYourself(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.muted = false
+	return mcp.NewToolResultText("Unmuted."), nil
+}
+
+// sendChatMessage adds a chat message from the agent
+func (s *Server) sendChatMessage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	message, err := request.RequireString("message")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.join == nil {
+		return mcp.NewToolResultError("Not in a meeting"), nil
+	}
+	s.chat = append(s.chat, ChatMessage{Text: message, Timestamp: s.elapsedUnsafe(), Sender: s.join.ParticipantName})
+	return mcp.NewToolResultText("Sent message."), nil
+}
+
+// getChatHistory returns the chat messages
+func (s *Server) getChatHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return jsonResult(map[string]interface{}{"messages": s.ChatMessages()})
+}
+
+// getParticipants returns the participants added with AddParticipant
+func (s *Server) getParticipants(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.mu.Lock()
+	participants := make([]map[string]interface{}, 0, len(s.participants))
+	for _, name := range s.participants {
+		participants = append(participants, map[string]interface{}{"name": name, "email": nil, "infos": []string{}})
+	}
+	s.mu.Unlock()
+	return jsonResult(participants)
+}
+
+// getTranscript returns the live transcript
+func (s *Server) getTranscript(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return jsonResult(s.transcript())
+}
+
+// transcript returns the live transcript as joinly serialises it
+func (s *Server) transcript() map[string]interface{} {
+	return map[string]interface{}{"segments": s.Segments()}
+}
+
+// jsonResult returns v as the JSON text result of a tool
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// jsonResource returns v as the JSON contents of a resource
+func jsonResource(uri string, v interface{}) ([]mcp.ResourceContents, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)}}, nil
+}
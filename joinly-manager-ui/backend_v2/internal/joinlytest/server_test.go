@@ -0,0 +1,145 @@
+package joinlytest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// connect returns an initialised MCP client listening for server notifications
+func connect(t *testing.T, s *Server) *client.Client {
+	ctx := context.Background()
+	c, err := client.NewStreamableHttpClient(s.URL, transport.WithContinuousListening(),
+		transport.WithHTTPHeaders(map[string]string{"joinly-settings": `{"name":"Ada"}`}))
+	if err != nil {
+		t.Fatalf("NewStreamableHttpClient: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := c.Initialize(ctx, mcp.InitializeRequest{Params: mcp.InitializeParams{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION}}); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	return c
+}
+
+// callText calls a tool and returns its text result
+func callText(t *testing.T, c *client.Client, name string, args map[string]interface{}) (string, bool) {
+	result, err := c.CallTool(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	text, _ := mcp.AsTextContent(result.Content[0])
+	return text.Text, result.IsError
+}
+
+func TestServerToolsAndTranscript(t *testing.T) {
+	s := NewServer()
+	t.Cleanup(s.Close)
+	c := connect(t, s)
+
+	if _, isError := callText(t, c, "speak_text", map[string]interface{}{"text": "Hello"}); !isError {
+		t.Error("expected speaking outside a meeting to fail")
+	}
+	callText(t, c, "join_meeting", map[string]interface{}{"meeting_url": "https://meet.google.com/abc", "participant_name": "Ada"})
+	if s.Settings()["name"] != "Ada" {
+		t.Errorf("settings header not captured: %v", s.Settings())
+	}
+
+	s.Say("Bob", "Hi Ada")
+	s.InterruptNextSpeech()
+	if text, _ := callText(t, c, "speak_text", map[string]interface{}{"text": "Hello Bob, good to see you"}); !strings.HasPrefix(text, "Interrupted by detected speech") {
+		t.Errorf("expected interruption, got %q", text)
+	}
+	callText(t, c, "send_chat_message", map[string]interface{}{"message": "Agenda attached"})
+
+	segments := s.Segments()
+	if len(segments) != 2 || segments[1].Role != "assistant" || segments[1].Text != "Hello Bob, good" || segments[1].Start <= segments[0].Start {
+		t.Errorf("unexpected segments: %+v", segments)
+	}
+	if chat := s.ChatMessages(); len(chat) != 1 || chat[0].Sender != "Ada" {
+		t.Errorf("unexpected chat: %+v", chat)
+	}
+
+	result, err := c.ReadResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: SegmentsURI}})
+	if err != nil {
+		t.Fatalf("ReadResource: %v", err)
+	}
+	if contents, _ := mcp.AsTextResourceContents(result.Contents[0]); !strings.Contains(contents.Text, `"speaker":"Bob"`) {
+		t.Errorf("unexpected transcript: %s", contents.Text)
+	}
+
+	s.FailTool("get_participants", "Browser crashed")
+	if text, isError := callText(t, c, "get_participants", nil); !isError || text != "Browser crashed" {
+		t.Errorf("expected scripted failure, got %q", text)
+	}
+}
+
+func TestServerNotifiesTranscriptUpdates(t *testing.T) {
+	s := NewServer()
+	t.Cleanup(s.Close)
+	c := connect(t, s)
+
+	updates := make(chan string, 10)
+	c.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == string(mcp.MethodNotificationResourceUpdated) {
+			uri, _ := notification.Params.AdditionalFields["uri"].(string)
+			updates <- uri
+		}
+	})
+
+	// Give the listening stream a moment to connect before speaking
+	deadline := time.After(5 * time.Second)
+	for {
+		s.Say("Bob", "Are you there?")
+		select {
+		case uri := <-updates:
+			if uri != SegmentsURI && uri != TranscriptURI {
+				t.Errorf("unexpected resource %q", uri)
+			}
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no resource update notification received")
+		}
+	}
+}
+
+func TestServerMuteStopsSpeech(t *testing.T) {
+	s := NewServer()
+	t.Cleanup(s.Close)
+	c := connect(t, s)
+	callText(t, c, "join_meeting", map[string]interface{}{"meeting_url": "https://meet.google.com/abc", "participant_name": "Ada"})
+	s.SetSpeakDelay(5 * time.Second)
+
+	result := make(chan string, 1)
+	go func() {
+		text, _ := callText(t, c, "speak_text", map[string]interface{}{"text": "We ship on Friday after QA"})
+		result <- text
+	}()
+	s.WaitFor(time.Second, func() bool { return s.Calls("speak_text") == 1 })
+	callText(t, c, "mute_yourself", nil)
+
+	select {
+	case text := <-result:
+		if text != `Interrupted by detected speech. Spoken until now: "We ship on..."` {
+			t.Errorf("unexpected result %q", text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("muting did not stop the speech")
+	}
+	if !s.Muted() {
+		t.Error("expected the agent to be muted")
+	}
+	callText(t, c, "unmute_yourself", nil)
+	if s.Muted() {
+		t.Error("expected the agent to be unmuted")
+	}
+}
@@ -0,0 +1,121 @@
+package knowledge
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// stopwords are left out of the index; they match nearly every chunk
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "can": true, "do": true, "does": true, "for": true, "from": true, "how": true, "i": true,
+	"if": true, "in": true, "is": true, "it": true, "me": true, "of": true, "on": true, "or": true,
+	"our": true, "so": true, "that": true, "the": true, "this": true, "to": true, "was": true, "we": true,
+	"what": true, "when": true, "where": true, "which": true, "who": true, "why": true, "will": true,
+	"with": true, "you": true, "your": true,
+}
+
+// tokenize lowercases text and splits it into indexable terms
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := fields[:0]
+	for _, field := range fields {
+		if !stopwords[field] {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+// bm25Index is an inverted index over the chunks of a knowledge base
+type bm25Index struct {
+	postings  map[string]map[int]int // term -> chunk -> term frequency
+	lengths   []int                  // Terms per chunk
+	avgLength float64
+}
+
+// newBM25Index indexes the given texts; search results refer to them by position
+func newBM25Index(texts []string) *bm25Index {
+	index := &bm25Index{
+		postings: make(map[string]map[int]int),
+		lengths:  make([]int, len(texts)),
+	}
+
+	total := 0
+	for i, text := range texts {
+		terms := tokenize(text)
+		index.lengths[i] = len(terms)
+		total += len(terms)
+		for _, term := range terms {
+			if index.postings[term] == nil {
+				index.postings[term] = make(map[int]int)
+			}
+			index.postings[term][i]++
+		}
+	}
+	if len(texts) > 0 {
+		index.avgLength = float64(total) / float64(len(texts))
+	}
+
+	return index
+}
+
+// score returns the BM25 score of every chunk matching at least one query term
+func (idx *bm25Index) score(query string) map[int]float64 {
+	scores := make(map[int]float64)
+	n := float64(len(idx.lengths))
+	if n == 0 || idx.avgLength == 0 {
+		return scores
+	}
+
+	seen := make(map[string]bool)
+	for _, term := range tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for i, tf := range postings {
+			frequency := float64(tf)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(idx.lengths[i])/idx.avgLength)
+			scores[i] += idf * frequency * (bm25K1 + 1) / (frequency + norm)
+		}
+	}
+
+	return scores
+}
+
+// cosine returns the cosine similarity of two embeddings, or 0 when they cannot be compared
+func cosine(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
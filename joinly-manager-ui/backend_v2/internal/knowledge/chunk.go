@@ -0,0 +1,173 @@
+package knowledge
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// targetChunkChars is the size chunks are filled up to before a new one is started
+	targetChunkChars = 800
+	// maxChunkChars is the size at which a single paragraph is split
+	maxChunkChars = 1500
+)
+
+// Document formats
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+	FormatPDFText  = "pdf_text" // Text extracted from a PDF, with hard line breaks and hyphenation
+)
+
+var (
+	headingPattern    = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*$`)
+	paragraphBreak    = regexp.MustCompile(`\n\s*\n`)
+	hyphenatedBreak   = regexp.MustCompile(`(\p{L})-\n(\p{Ll})`)
+	sentenceBoundary  = regexp.MustCompile(`[.!?]\s+`)
+	repeatedBlankLine = regexp.MustCompile(`\n{3,}`)
+)
+
+// chunk is an indexed piece of a document
+type chunk struct {
+	Section   string    `json:"section,omitempty"` // Heading path, e.g. "Deploys > Rollback"
+	Text      string    `json:"text"`
+	Embedding []float64 `json:"embedding,omitempty"`
+}
+
+// formatForSource infers a document format from its file name
+func formatForSource(source string) string {
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasSuffix(lower, ".md"), strings.HasSuffix(lower, ".markdown"):
+		return FormatMarkdown
+	case strings.HasSuffix(lower, ".pdf"), strings.HasSuffix(lower, ".pdf.txt"):
+		return FormatPDFText
+	default:
+		return FormatText
+	}
+}
+
+// normalizePDFText rejoins words hyphenated across lines and turns page breaks into paragraph breaks
+func normalizePDFText(content string) string {
+	content = strings.ReplaceAll(content, "\f", "\n\n")
+	content = hyphenatedBreak.ReplaceAllString(content, "$1$2")
+	return repeatedBlankLine.ReplaceAllString(content, "\n\n")
+}
+
+// chunkDocument splits a document into chunks of about targetChunkChars. Markdown headings
+// always start a new chunk and become the section of the chunks below them.
+func chunkDocument(format, content string) []chunk {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if format == FormatPDFText {
+		content = normalizePDFText(content)
+	}
+
+	var chunks []chunk
+	var headings []string
+	var current strings.Builder
+	section := ""
+
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			chunks = append(chunks, chunk{Section: section, Text: text})
+		}
+		current.Reset()
+	}
+
+	for _, paragraph := range paragraphBreak.Split(content, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		if format == FormatMarkdown {
+			// A heading may share its paragraph with the text below it
+			lines := strings.SplitN(paragraph, "\n", 2)
+			if match := headingPattern.FindStringSubmatch(lines[0]); match != nil {
+				flush()
+				level := len(match[1])
+				if len(headings) >= level {
+					headings = headings[:level-1]
+				}
+				for len(headings) < level-1 {
+					headings = append(headings, "")
+				}
+				headings = append(headings, match[2])
+				section = joinHeadings(headings)
+
+				if len(lines) == 1 {
+					continue
+				}
+				paragraph = strings.TrimSpace(lines[1])
+			}
+		} else {
+			// Plain text wraps lines inside paragraphs
+			paragraph = strings.Join(strings.Fields(paragraph), " ")
+		}
+
+		for _, piece := range splitLong(paragraph) {
+			if current.Len() > 0 && current.Len()+len(piece) > targetChunkChars {
+				flush()
+			}
+			if current.Len() > 0 {
+				current.WriteString("\n\n")
+			}
+			current.WriteString(piece)
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// joinHeadings renders the non-empty headings of a path as "A > B"
+func joinHeadings(headings []string) string {
+	var path []string
+	for _, heading := range headings {
+		if heading != "" {
+			path = append(path, heading)
+		}
+	}
+	return strings.Join(path, " > ")
+}
+
+// splitLong splits a paragraph longer than maxChunkChars at sentence boundaries, or at
+// word boundaries when a single sentence is too long
+func splitLong(paragraph string) []string {
+	if len(paragraph) <= maxChunkChars {
+		return []string{paragraph}
+	}
+
+	var pieces []string
+	var current strings.Builder
+	add := func(part string) {
+		if current.Len() > 0 && current.Len()+len(part) > maxChunkChars {
+			pieces = append(pieces, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+		current.WriteString(part)
+	}
+
+	last := 0
+	for _, bounds := range sentenceBoundary.FindAllStringIndex(paragraph, -1) {
+		sentence := paragraph[last:bounds[1]]
+		last = bounds[1]
+		if len(sentence) <= maxChunkChars {
+			add(sentence)
+			continue
+		}
+		for _, word := range strings.Fields(sentence) {
+			add(word + " ")
+		}
+	}
+	if rest := paragraph[last:]; rest != "" {
+		for _, word := range strings.Fields(rest) {
+			add(word + " ")
+		}
+	}
+	if text := strings.TrimSpace(current.String()); text != "" {
+		pieces = append(pieces, text)
+	}
+
+	return pieces
+}
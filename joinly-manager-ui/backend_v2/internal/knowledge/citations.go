@@ -0,0 +1,55 @@
+package knowledge
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"joinly-manager/internal/models"
+)
+
+var (
+	citationPattern = regexp.MustCompile(`\s*\[(\d+(?:\s*,\s*\d+)*)\]`)
+	spaceBeforeMark = regexp.MustCompile(`\s+([.,;:!?])`)
+)
+
+// FormatPassages renders passages for the prompt, each under its citation number
+func FormatPassages(passages []models.KnowledgePassage) string {
+	var b strings.Builder
+	for i, passage := range passages {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		source := passage.Title
+		if passage.Section != "" {
+			source += " > " + passage.Section
+		}
+		fmt.Fprintf(&b, "[%d] %s\n%s", passage.Citation, source, passage.Text)
+	}
+	return b.String()
+}
+
+// Citations returns the passages a reply cites as [n] or [n, m], in citation order, and the
+// reply with the markers removed so they are not spoken
+func Citations(reply string, passages []models.KnowledgePassage) ([]models.KnowledgePassage, string) {
+	cited := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(reply, -1) {
+		for _, number := range strings.Split(match[1], ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(number)); err == nil {
+				cited[n] = true
+			}
+		}
+	}
+
+	var used []models.KnowledgePassage
+	for _, passage := range passages {
+		if cited[passage.Citation] {
+			used = append(used, passage)
+		}
+	}
+
+	spoken := citationPattern.ReplaceAllString(reply, "")
+	spoken = spaceBeforeMark.ReplaceAllString(spoken, "$1")
+	return used, strings.TrimSpace(spoken)
+}
@@ -0,0 +1,110 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/models"
+)
+
+const runbook = `# Deploys
+
+Deploys run from the release pipeline every weekday.
+
+## Rollback
+
+To roll back a bad deploy, run the rollback job with the previous release tag.
+Page the on-call engineer if the rollback fails.
+
+# Databases
+
+Postgres backups are taken nightly and kept for 30 days.`
+
+func TestChunkDocumentTracksMarkdownSections(t *testing.T) {
+	chunks := chunkDocument(FormatMarkdown, runbook)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks: %+v", len(chunks), chunks)
+	}
+	if chunks[1].Section != "Deploys > Rollback" || !strings.Contains(chunks[1].Text, "rollback job") {
+		t.Fatalf("unexpected rollback chunk: %+v", chunks[1])
+	}
+	if chunks[2].Section != "Databases" {
+		t.Fatalf("heading path not reset: %+v", chunks[2])
+	}
+}
+
+func TestChunkDocumentNormalizesPDFText(t *testing.T) {
+	chunks := chunkDocument(FormatPDFText, "The incident com-\nmander owns\ncommunication.\fPage two.")
+	if len(chunks) != 1 || !strings.HasPrefix(chunks[0].Text, "The incident commander owns communication.") {
+		t.Fatalf("unexpected chunks: %+v", chunks)
+	}
+}
+
+func TestStoreSearchRanksByBM25(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, nil, nil)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := store.AddDocument(ctx, "runbooks", "", "operations.md", "", runbook); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	if _, err := store.AddDocument(ctx, "runbooks", "Holidays", "", FormatText, "The office is closed on public holidays."); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	if _, err := store.AddDocument(ctx, "../x", "", "", FormatText, "x"); err == nil {
+		t.Fatal("expected invalid name to be rejected")
+	}
+
+	passages := store.Search(ctx, []string{"runbooks", "missing"}, "How do I roll back a failed deploy?", 2)
+	if len(passages) == 0 || passages[0].Section != "Deploys > Rollback" || passages[0].Title != "operations" {
+		t.Fatalf("unexpected passages: %+v", passages)
+	}
+	if passages[0].Citation != 1 {
+		t.Fatalf("passages not numbered: %+v", passages)
+	}
+	if none := store.Search(ctx, []string{"runbooks"}, "what is it", 3); len(none) != 0 {
+		t.Fatalf("stopword-only query matched: %+v", none)
+	}
+
+	reloaded, _ := NewStore(dir, nil, nil)
+	if bases := reloaded.Bases(); len(bases) != 1 || bases[0].Documents != 2 {
+		t.Fatalf("reloaded bases = %+v", bases)
+	}
+}
+
+func TestStoreSearchFusesEmbeddings(t *testing.T) {
+	// Texts mentioning "cat" point one way, everything else the other
+	embed := func(ctx context.Context, text string) ([]float64, error) {
+		if strings.Contains(strings.ToLower(text), "cat") || strings.Contains(strings.ToLower(text), "kitten") {
+			return []float64{1, 0}, nil
+		}
+		return []float64{0, 1}, nil
+	}
+	store, _ := NewStore(t.TempDir(), embed, nil)
+	ctx := context.Background()
+
+	store.AddDocument(ctx, "pets", "Cats", "", FormatText, "Cats sleep most of the day.")
+	store.AddDocument(ctx, "pets", "Dogs", "", FormatText, "Dogs need a walk every day.")
+
+	// No keyword overlap with the cat document; only the embedding finds it
+	passages := store.Search(ctx, []string{"pets"}, "kitten naps", 1)
+	if len(passages) != 1 || passages[0].Title != "Cats" {
+		t.Fatalf("unexpected passages: %+v", passages)
+	}
+}
+
+func TestCitations(t *testing.T) {
+	passages := []models.KnowledgePassage{{Citation: 1, Title: "A"}, {Citation: 2, Title: "B"}, {Citation: 3, Title: "C"}}
+
+	used, spoken := Citations("Run the rollback job [1]. Page on-call if it fails [1, 3].", passages)
+	if len(used) != 2 || used[0].Title != "A" || used[1].Title != "C" {
+		t.Fatalf("used = %+v", used)
+	}
+	if spoken != "Run the rollback job. Page on-call if it fails." {
+		t.Fatalf("spoken = %q", spoken)
+	}
+}
@@ -0,0 +1,447 @@
+// Package knowledge indexes documents that conversational agents answer from. Documents are
+// chunked and ranked with BM25, optionally fused with embedding similarity, and each knowledge
+// base is stored as one JSON file.
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/storage"
+)
+
+const (
+	// maxDocumentBytes caps the size of an uploaded document
+	maxDocumentBytes = 2 << 20
+	// maxDocumentsPerBase caps the documents of one knowledge base
+	maxDocumentsPerBase = 1000
+	// rrfK dampens the rank fusion of BM25 and embedding results
+	rrfK = 60
+)
+
+// namePattern restricts knowledge base names to names that are safe as file names
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidName reports whether name can be used as a knowledge base name
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Embedder returns the embedding of a text for semantic search
+type Embedder func(ctx context.Context, text string) ([]float64, error)
+
+// storedDocument is a document as saved in its knowledge base file
+type storedDocument struct {
+	Document models.KnowledgeDocument `json:"document"`
+	Chunks   []chunk                  `json:"chunks"`
+}
+
+// chunkRef locates an indexed chunk
+type chunkRef struct {
+	doc   int
+	chunk int
+}
+
+// base is one knowledge base with its search index
+type base struct {
+	docs  []storedDocument
+	refs  []chunkRef // Index position -> chunk
+	index *bm25Index
+}
+
+// reindex rebuilds the search index after documents changed
+func (b *base) reindex() {
+	b.refs = b.refs[:0]
+	var texts []string
+	for d, doc := range b.docs {
+		for c, ch := range doc.Chunks {
+			b.refs = append(b.refs, chunkRef{doc: d, chunk: c})
+			texts = append(texts, doc.Document.Title+" "+ch.Section+" "+ch.Text)
+		}
+	}
+	b.index = newBM25Index(texts)
+}
+
+// Store holds every knowledge base and mirrors each one to <dir>/<name>.json
+type Store struct {
+	dir   string
+	vault *storage.Vault
+	embed Embedder // nil when semantic search is disabled
+	bases map[string]*base
+	mu    sync.RWMutex
+}
+
+// NewStore creates a store backed by dir and loads the knowledge bases already saved there.
+// embed may be nil to rank by BM25 only, vault may be nil to store plaintext. The store is usable
+// even when loading fails.
+func NewStore(dir string, embed Embedder, vault *storage.Vault) (*Store, error) {
+	s := &Store{
+		dir:   dir,
+		vault: vault,
+		embed: embed,
+		bases: make(map[string]*base),
+	}
+
+	if err := vault.MkdirAll(dir); err != nil {
+		return s, fmt.Errorf("failed to create knowledge directory: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return s, fmt.Errorf("failed to list knowledge files: %w", err)
+	}
+
+	var loadErrs []string
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		if !ValidName(name) {
+			continue
+		}
+
+		data, err := vault.ReadFile(file)
+		if err != nil {
+			loadErrs = append(loadErrs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		var docs []storedDocument
+		if err := json.Unmarshal(data, &docs); err != nil {
+			loadErrs = append(loadErrs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if len(docs) > 0 {
+			b := &base{docs: docs}
+			b.reindex()
+			s.bases[name] = b
+		}
+	}
+
+	if len(loadErrs) > 0 {
+		return s, fmt.Errorf("failed to load knowledge bases: %s", strings.Join(loadErrs, "; "))
+	}
+	return s, nil
+}
+
+// Bases returns every knowledge base, sorted by name
+func (s *Store) Bases() []models.KnowledgeBaseInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]models.KnowledgeBaseInfo, 0, len(s.bases))
+	for name, b := range s.bases {
+		info := models.KnowledgeBaseInfo{Name: name, Documents: len(b.docs), Chunks: len(b.refs)}
+		for _, doc := range b.docs {
+			if doc.Document.CreatedAt.After(info.UpdatedAt) {
+				info.UpdatedAt = doc.Document.CreatedAt
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+// Documents returns the documents of a knowledge base, oldest first
+func (s *Store) Documents(name string) ([]models.KnowledgeDocument, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, exists := s.bases[name]
+	if !exists {
+		return nil, fmt.Errorf("knowledge base not found")
+	}
+
+	docs := make([]models.KnowledgeDocument, len(b.docs))
+	for i, doc := range b.docs {
+		docs[i] = doc.Document
+	}
+	return docs, nil
+}
+
+// Document returns one document and its chunks
+func (s *Store) Document(name, docID string) (models.KnowledgeDocument, []models.KnowledgeChunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index := s.documentIndexUnsafe(name, docID)
+	if index < 0 {
+		return models.KnowledgeDocument{}, nil, fmt.Errorf("document not found")
+	}
+
+	doc := s.bases[name].docs[index]
+	chunks := make([]models.KnowledgeChunk, len(doc.Chunks))
+	for i, ch := range doc.Chunks {
+		chunks[i] = models.KnowledgeChunk{Section: ch.Section, Text: ch.Text}
+	}
+	return doc.Document, chunks, nil
+}
+
+// AddDocument chunks, embeds (when semantic search is enabled) and indexes a document.
+// The format is inferred from the source file name when empty.
+func (s *Store) AddDocument(ctx context.Context, name, title, source, format, content string) (models.KnowledgeDocument, error) {
+	if !ValidName(name) {
+		return models.KnowledgeDocument{}, fmt.Errorf("invalid knowledge base name")
+	}
+	if format == "" {
+		format = formatForSource(source)
+	}
+	if format != FormatMarkdown && format != FormatText && format != FormatPDFText {
+		return models.KnowledgeDocument{}, fmt.Errorf("unsupported document format")
+	}
+	if len(content) > maxDocumentBytes {
+		return models.KnowledgeDocument{}, fmt.Errorf("document too large")
+	}
+
+	chunks := chunkDocument(format, content)
+	if len(chunks) == 0 {
+		return models.KnowledgeDocument{}, fmt.Errorf("document content is required")
+	}
+
+	if title = strings.TrimSpace(title); title == "" {
+		title = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	}
+	if title == "" || title == "." {
+		title = "Untitled"
+	}
+
+	doc := models.KnowledgeDocument{
+		ID:            fmt.Sprintf("doc_%s", uuid.New().String()[:8]),
+		KnowledgeBase: name,
+		Title:         title,
+		Source:        source,
+		Format:        format,
+		Characters:    len(content),
+		Chunks:        len(chunks),
+		CreatedAt:     time.Now(),
+	}
+
+	// Embedding can take a while, so it happens before the store is locked
+	if s.embed != nil {
+		doc.Embedded = true
+		for i := range chunks {
+			embedding, err := s.embed(ctx, title+"\n"+chunks[i].Section+"\n"+chunks[i].Text)
+			if err != nil {
+				logrus.Warnf("Failed to embed document %q, indexing it for keyword search only: %v", title, err)
+				doc.Embedded = false
+				for j := range chunks {
+					chunks[j].Embedding = nil
+				}
+				break
+			}
+			chunks[i].Embedding = embedding
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.bases[name]
+	if b == nil {
+		b = &base{}
+		s.bases[name] = b
+	}
+	if len(b.docs) >= maxDocumentsPerBase {
+		return models.KnowledgeDocument{}, fmt.Errorf("knowledge base full")
+	}
+
+	b.docs = append(b.docs, storedDocument{Document: doc, Chunks: chunks})
+	b.reindex()
+
+	if err := s.saveUnsafe(name); err != nil {
+		return models.KnowledgeDocument{}, err
+	}
+	return doc, nil
+}
+
+// DeleteDocument removes a document from a knowledge base
+func (s *Store) DeleteDocument(name, docID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := s.documentIndexUnsafe(name, docID)
+	if index < 0 {
+		return fmt.Errorf("document not found")
+	}
+
+	b := s.bases[name]
+	b.docs = append(b.docs[:index:index], b.docs[index+1:]...)
+	if len(b.docs) == 0 {
+		delete(s.bases, name)
+	} else {
+		b.reindex()
+	}
+	return s.saveUnsafe(name)
+}
+
+// DeleteBase removes a knowledge base and its file
+func (s *Store) DeleteBase(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.bases[name]; !exists {
+		return fmt.Errorf("knowledge base not found")
+	}
+
+	delete(s.bases, name)
+	return s.saveUnsafe(name)
+}
+
+// Search returns the k passages of the named knowledge bases that best match the query,
+// numbered for citation. With semantic search enabled, BM25 and embedding rankings are
+// fused by reciprocal rank; otherwise passages are ranked by BM25 alone. Unknown knowledge
+// bases are skipped.
+func (s *Store) Search(ctx context.Context, names []string, query string, k int) []models.KnowledgePassage {
+	if k <= 0 || len(tokenize(query)) == 0 {
+		return nil
+	}
+
+	var queryEmbedding []float64
+	if s.embed != nil {
+		embedding, err := s.embed(ctx, query)
+		if err != nil {
+			logrus.Debugf("Failed to embed knowledge query, using keyword search only: %v", err)
+		} else {
+			queryEmbedding = embedding
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var passages []models.KnowledgePassage
+	for _, name := range names {
+		b := s.bases[name]
+		if b == nil {
+			continue
+		}
+
+		for position, score := range b.rank(query, queryEmbedding, k) {
+			ref := b.refs[position]
+			doc := b.docs[ref.doc]
+			ch := doc.Chunks[ref.chunk]
+			passages = append(passages, models.KnowledgePassage{
+				KnowledgeBase: name,
+				DocumentID:    doc.Document.ID,
+				Title:         doc.Document.Title,
+				Section:       ch.Section,
+				Text:          ch.Text,
+				Score:         score,
+			})
+		}
+	}
+
+	sort.SliceStable(passages, func(i, j int) bool {
+		return passages[i].Score > passages[j].Score
+	})
+	if len(passages) > k {
+		passages = passages[:k]
+	}
+	for i := range passages {
+		passages[i].Citation = i + 1
+	}
+
+	return passages
+}
+
+// rank scores the chunks of a knowledge base for a query, keeping about k candidates
+func (b *base) rank(query string, queryEmbedding []float64, k int) map[int]float64 {
+	keywordScores := b.index.score(query)
+	if queryEmbedding == nil {
+		scores := make(map[int]float64, k)
+		for _, position := range topPositions(keywordScores, k) {
+			scores[position] = keywordScores[position]
+		}
+		return scores
+	}
+	keyword := topPositions(keywordScores, k*2)
+
+	similarities := make(map[int]float64)
+	for position, ref := range b.refs {
+		if embedding := b.docs[ref.doc].Chunks[ref.chunk].Embedding; embedding != nil {
+			if similarity := cosine(queryEmbedding, embedding); similarity > 0 {
+				similarities[position] = similarity
+			}
+		}
+	}
+	semantic := topPositions(similarities, k)
+
+	scores := make(map[int]float64)
+	for rank, position := range keyword {
+		scores[position] += 1 / float64(rrfK+rank+1)
+	}
+	for rank, position := range semantic {
+		scores[position] += 1 / float64(rrfK+rank+1)
+	}
+	return scores
+}
+
+// topPositions returns the n highest scoring positions, best first
+func topPositions(scores map[int]float64, n int) []int {
+	positions := make([]int, 0, len(scores))
+	for position := range scores {
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if scores[positions[i]] != scores[positions[j]] {
+			return scores[positions[i]] > scores[positions[j]]
+		}
+		return positions[i] < positions[j]
+	})
+
+	if len(positions) > n {
+		positions = positions[:n]
+	}
+	return positions
+}
+
+// documentIndexUnsafe returns the position of a document, or -1 (caller must hold mu)
+func (s *Store) documentIndexUnsafe(name, docID string) int {
+	b := s.bases[name]
+	if b == nil {
+		return -1
+	}
+	for i, doc := range b.docs {
+		if doc.Document.ID == docID {
+			return i
+		}
+	}
+	return -1
+}
+
+// saveUnsafe writes a knowledge base to disk, removing the file once it is empty (caller must
+// hold mu). The file is replaced atomically so a crash never leaves it truncated.
+func (s *Store) saveUnsafe(name string) error {
+	path := filepath.Join(s.dir, name+".json")
+
+	b := s.bases[name]
+	if b == nil || len(b.docs) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove knowledge file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(b.docs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal knowledge base: %w", err)
+	}
+
+	if err := s.vault.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write knowledge file: %w", err)
+	}
+	return nil
+}
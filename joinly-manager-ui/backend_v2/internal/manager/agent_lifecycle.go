@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"joinly-manager/internal/client"
+	"joinly-manager/internal/metrics"
 	"joinly-manager/internal/models"
 )
 
@@ -39,20 +40,15 @@ func (m *AgentManager) CreateAgent(config models.AgentConfig) (*models.Agent, er
 	}
 
 	m.agents[agentID] = agent
+	m.logMu.Lock()
 	m.logBuffers[agentID] = make([]models.LogEntry, 0, m.logBufferSize)
+	m.logMu.Unlock()
 
 	// Update meeting info
 	meetingURL := config.MeetingURL
-	if m.meetings[meetingURL] == nil {
-		m.meetings[meetingURL] = &models.MeetingInfo{
-			URL:       meetingURL,
-			CreatedAt: now,
-		}
-	}
-	m.meetings[meetingURL].AgentIDs = append(m.meetings[meetingURL].AgentIDs, agentID)
-	m.meetings[meetingURL].AgentCount++
+	m.addAgentToMeetingUnsafe(meetingURL, agentID)
 
-	m.addLogEntryUnsafe(agentID, models.LogEntry{
+	m.appendLog(agentID, models.LogEntry{
 		Timestamp: time.Now(),
 		Level:     "info",
 		Message:   fmt.Sprintf("Agent created for meeting: %s", meetingURL),
@@ -60,7 +56,7 @@ func (m *AgentManager) CreateAgent(config models.AgentConfig) (*models.Agent, er
 
 	logrus.Infof("Created agent %s for meeting %s", agentID, meetingURL)
 
-	return agent, nil
+	return m.agentCopyUnsafe(agent), nil
 }
 
 // DeleteAgent deletes an agent
@@ -80,29 +76,33 @@ func (m *AgentManager) DeleteAgent(agentID string) error {
 		}
 	}
 
+	// An errored agent is not stopped but still leaves its meeting session
+	m.leaveSessionUnsafe(agentID, m.clients[agentID])
+
 	// Update meeting info
-	meetingURL := agent.Config.MeetingURL
-	if meeting := m.meetings[meetingURL]; meeting != nil {
-		// Remove agent ID from meeting
-		for i, id := range meeting.AgentIDs {
-			if id == agentID {
-				meeting.AgentIDs = append(meeting.AgentIDs[:i], meeting.AgentIDs[i+1:]...)
-				break
-			}
-		}
-		meeting.AgentCount--
+	m.removeAgentFromMeetingUnsafe(m.listedMeetingUnsafe(agentID), agentID)
 
-		// Remove meeting if no agents left
-		if meeting.AgentCount == 0 {
-			delete(m.meetings, meetingURL)
-		}
-	}
+	// Make sure an errored or queued agent does not keep its backend
+	m.backends.Release(agentID)
 
 	// Clean up
 	delete(m.agents, agentID)
 	delete(m.clients, agentID)
 	delete(m.analysts, agentID) // Clean up analyst agent if exists
+	m.logMu.Lock()
 	delete(m.logBuffers, agentID)
+	m.logMu.Unlock()
+	delete(m.latencies, agentID)
+	delete(m.conversationHistory, agentID)
+	delete(m.summaries, agentID)
+	delete(m.remembered, agentID)
+	delete(m.interjections, agentID)
+	delete(m.transcripts, agentID)
+	if session := m.replays[agentID]; session != nil {
+		session.cancel()
+		delete(m.replays, agentID)
+	}
+	metrics.ForgetAgent(agentID)
 
 	logrus.Infof("Deleted agent %s", agentID)
 	return nil
@@ -113,6 +113,12 @@ func (m *AgentManager) StartAgent(agentID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	return m.startAgentUnsafe(agentID, "")
+}
+
+// startAgentUnsafe starts an agent against a joinly backend leased from the pool, or against
+// backendURL when it is set (caller must hold lock)
+func (m *AgentManager) startAgentUnsafe(agentID, backendURL string) error {
 	agent, exists := m.agents[agentID]
 	if !exists {
 		return fmt.Errorf("agent not found")
@@ -122,6 +128,22 @@ func (m *AgentManager) StartAgent(agentID string) error {
 		return nil
 	}
 
+	// Lease a joinly backend up front so callers fail fast when the pool is exhausted
+	var backend *models.JoinlyBackend
+	if backendURL != "" {
+		backend = &models.JoinlyBackend{ID: "dedicated", URL: backendURL}
+	} else {
+		var err error
+		backend, err = m.backends.TryAcquire(agentID)
+		if err != nil && !m.config.Joinly.QueueWhenBusy {
+			m.addLogEntry(agentID, "warn", "No free joinly backend available")
+			return err
+		}
+	}
+
+	// A meeting URL changed while the agent was running takes effect now
+	m.syncAgentMeetingUnsafe(agentID, agent)
+
 	// Update status and start time while holding lock
 	now := time.Now()
 	agent.StartedAt = &now
@@ -129,29 +151,6 @@ func (m *AgentManager) StartAgent(agentID string) error {
 
 	m.addLogEntry(agentID, "info", "Starting agent")
 
-	// Create client
-	joinlyClient := client.NewJoinlyClient(agentID, agent.Config, m.config.Joinly.DefaultURL)
-
-	// Create analyst agent if in analyst mode
-	if agent.Config.ConversationMode == models.ConversationModeAnalyst {
-		analystAgent := client.NewAnalystAgent(agentID, agent.Config, joinlyClient)
-		m.analysts[agentID] = analystAgent
-		m.addLogEntry(agentID, "info", "Analyst agent created for meeting analysis")
-	}
-
-	// Set up callbacks
-	// Remove the status change callback - manager will control status directly
-	// This prevents double status broadcasts and UI spam
-
-	joinlyClient.SetLogCallback(func(level, message string) {
-		m.addLogEntry(agentID, level, message)
-	})
-
-	// Add utterance callback for LLM processing (like Python client)
-	joinlyClient.AddUtteranceCallback(func(segments []map[string]interface{}) {
-		m.handleUtterance(agentID, segments)
-	})
-
 	// Update status to starting (while lock is held)
 	m.updateAgentStatusUnsafe(agentID, models.AgentStatusStarting)
 
@@ -174,10 +173,53 @@ func (m *AgentManager) StartAgent(agentID string) error {
 					cancelFunc()
 					delete(m.agentContexts, agentID)
 				}
+				m.backends.Release(agentID)
 				m.mu.Unlock()
 			}
 		}()
 
+		// Wait in the queue for a free backend if none could be leased immediately
+		if backend == nil {
+			m.addLogEntry(agentID, "info", "Waiting for a free joinly backend")
+
+			queueCtx := agentCtx
+			if m.config.Joinly.QueueTimeout > 0 {
+				var cancel context.CancelFunc
+				queueCtx, cancel = context.WithTimeout(agentCtx, m.config.Joinly.QueueTimeout)
+				defer cancel()
+			}
+
+			leased, err := m.backends.Acquire(queueCtx, agentID)
+			if err != nil {
+				if agentCtx.Err() != nil {
+					// Agent was stopped while queued
+					return
+				}
+				m.mu.Lock()
+				m.handleAgentErrorUnsafe(agentID, err)
+				if cancelFunc, exists := m.agentContexts[agentID]; exists {
+					cancelFunc()
+					delete(m.agentContexts, agentID)
+				}
+				m.mu.Unlock()
+				return
+			}
+			backend = leased
+		}
+
+		m.mu.Lock()
+		if agentCtx.Err() != nil {
+			// Agent was stopped while the backend was being leased
+			m.backends.Release(agentID)
+			m.mu.Unlock()
+			return
+		}
+		agent.BackendURL = backend.URL
+		joinlyClient := m.newAgentClientUnsafe(agentID, agent, backend.URL)
+		m.mu.Unlock()
+
+		m.addLogEntry(agentID, "info", fmt.Sprintf("Leased joinly backend %s (%s)", backend.ID, backend.URL))
+
 		// Start the client
 		if err := joinlyClient.Start(); err != nil {
 			// Handle error without acquiring lock (we're in a goroutine, but need to be careful)
@@ -188,12 +230,14 @@ func (m *AgentManager) StartAgent(agentID string) error {
 				cancelFunc()
 				delete(m.agentContexts, agentID)
 			}
+			m.backends.Release(agentID)
 			m.mu.Unlock()
 			return
 		}
 
 		m.mu.Lock()
 		m.clients[agentID] = joinlyClient
+		m.joinSessionUnsafe(agentID, agent)
 		agent.GoroutineID = &[]int{runtime.NumGoroutine()}[0]
 		// Update status while holding lock to avoid deadlock
 		agent.Status = models.AgentStatusRunning
@@ -211,6 +255,7 @@ func (m *AgentManager) StartAgent(agentID string) error {
 				return
 			}
 			m.addLogEntry(agentID, "info", "Joined meeting successfully")
+			m.discloseRecording(agentID, joinlyClient)
 		}
 
 		// Keep running until agent context is cancelled
@@ -223,6 +268,53 @@ func (m *AgentManager) StartAgent(agentID string) error {
 	return nil
 }
 
+// newAgentClientUnsafe creates the joinly client (and analyst) for an agent (caller must hold lock)
+func (m *AgentManager) newAgentClientUnsafe(agentID string, agent *models.Agent, serverURL string) *client.JoinlyClient {
+	// Create client
+	joinlyClient := client.NewJoinlyClient(agentID, agent.Config, serverURL)
+
+	// Create analyst agent if in analyst mode
+	if agent.Config.ConversationMode == models.ConversationModeAnalyst {
+		analystAgent := client.NewAnalystAgent(agentID, agent.Config, joinlyClient)
+		m.analysts[agentID] = analystAgent
+		m.addLogEntry(agentID, "info", "Analyst agent created for meeting analysis")
+	}
+
+	// Set up callbacks
+	// Remove the status change callback - manager will control status directly
+	// This prevents double status broadcasts and UI spam
+
+	joinlyClient.SetLogCallback(func(level, message string) {
+		m.addLogEntry(agentID, level, message)
+	})
+
+	// Add utterance callback for LLM processing (like Python client)
+	joinlyClient.AddUtteranceCallback(func(ctx context.Context, segments []map[string]interface{}) {
+		m.handleUtterance(ctx, agentID, segments)
+	})
+
+	joinlyClient.SetUsageCallback(func(usage models.LLMUsage) {
+		m.recordUsage(agentID, usage)
+	})
+
+	joinlyClient.SetSilenceCheck(func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		agent, exists := m.agents[agentID]
+		return exists && isSilencedUnsafe(agent)
+	})
+
+	joinlyClient.SetLatencyCallback(func(latency models.UtteranceLatency) {
+		m.recordLatency(agentID, latency)
+	})
+
+	joinlyClient.SetObjectionCallback(func(objection models.ConsentObjection) {
+		m.handleObjection(agentID, objection)
+	})
+
+	return joinlyClient
+}
+
 // StopAgent stops an agent
 func (m *AgentManager) StopAgent(agentID string) error {
 	m.mu.Lock()
@@ -251,6 +343,11 @@ func (m *AgentManager) stopAgent(agentID string) error {
 	agent.StoppedAt = &now
 	m.updateAgentStatusUnsafe(agentID, models.AgentStatusStopping)
 
+	// Stopping an agent ends the transcript replay driving it
+	if session := m.replays[agentID]; session != nil {
+		session.cancel()
+	}
+
 	// Cancel the agent's context (blocking call to avoid race conditions)
 	if agentCancel, exists := m.agentContexts[agentID]; exists {
 		logrus.Debugf("Cancelling context for agent %s", agentID)
@@ -265,8 +362,24 @@ func (m *AgentManager) stopAgent(agentID string) error {
 			logrus.Errorf("Failed to stop client %s: %v", agentID, err)
 		}
 		delete(m.clients, agentID)
+
+		// Keep what the meeting decided for the identity's next meeting
+		m.rememberMeetingUnsafe(agentID, client)
+
+		// Keep the transcript exportable after the meeting
+		m.transcripts[agentID] = client.MeetingTranscript()
+
+		// Archive the meeting once the last agent has left it
+		m.leaveSessionUnsafe(agentID, client)
 	}
 
+	// Return the joinly backend to the pool
+	m.backends.Release(agentID)
+	agent.BackendURL = ""
+
+	// A stopped agent is listed under its configured meeting
+	m.syncAgentMeetingUnsafe(agentID, agent)
+
 	// Update status to stopped while holding lock
 	agent.Status = models.AgentStatusStopped
 	m.updateAgentStatusUnsafe(agentID, models.AgentStatusStopped)
@@ -286,11 +399,7 @@ func (m *AgentManager) GetAgent(agentID string) (*models.Agent, bool) {
 	}
 
 	// Return a copy to prevent external modifications
-	agentCopy := *agent
-	agentCopy.Logs = make([]models.LogEntry, len(agent.Logs))
-	copy(agentCopy.Logs, agent.Logs)
-
-	return &agentCopy, true
+	return m.agentCopyUnsafe(agent), true
 }
 
 // ListAgents lists all agents
@@ -301,10 +410,7 @@ func (m *AgentManager) ListAgents() []*models.Agent {
 	agents := make([]*models.Agent, 0, len(m.agents))
 	for _, agent := range m.agents {
 		// Return copies to prevent external modifications
-		agentCopy := *agent
-		agentCopy.Logs = make([]models.LogEntry, len(agent.Logs))
-		copy(agentCopy.Logs, agent.Logs)
-		agents = append(agents, &agentCopy)
+		agents = append(agents, m.agentCopyUnsafe(agent))
 	}
 
 	return agents
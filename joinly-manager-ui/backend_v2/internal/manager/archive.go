@@ -0,0 +1,212 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/archive"
+	"joinly-manager/internal/billing"
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/models"
+)
+
+// archivePruneInterval is how often the retention policy is applied to the meeting archive
+const archivePruneInterval = time.Hour
+
+// meetingSession is a meeting with agents in it, archived when the last agent leaves
+type meetingSession struct {
+	record models.MeetingRecord
+	active map[string]models.CostTotals // Agents in the meeting -> their cost when they joined
+}
+
+// joinSessionUnsafe adds a running agent to the session of its meeting, opening one when it is
+// the first agent there (caller must hold lock). Replays are not meetings and are not archived.
+func (m *AgentManager) joinSessionUnsafe(agentID string, agent *models.Agent) {
+	if replay := m.replays[agentID]; replay != nil && replay.status.State == models.ReplayStateRunning {
+		return
+	}
+
+	now := time.Now()
+	session := m.sessions[agent.Config.MeetingURL]
+	if session == nil {
+		session = &meetingSession{
+			record: models.MeetingRecord{MeetingURL: agent.Config.MeetingURL, StartedAt: now},
+			active: make(map[string]models.CostTotals),
+		}
+		m.sessions[agent.Config.MeetingURL] = session
+	}
+
+	session.active[agentID] = agent.Cost
+	session.record.Agents = append(session.record.Agents, models.MeetingRecordAgent{
+		ID:               agentID,
+		Name:             agent.Config.Name,
+		ConversationMode: agent.Config.ConversationMode,
+		JoinedAt:         now,
+	})
+}
+
+// leaveSessionUnsafe takes what an agent leaves behind into its meeting session and archives the
+// session when no agent is left (caller must hold lock). It does nothing for agents in no session.
+func (m *AgentManager) leaveSessionUnsafe(agentID string, joinlyClient *client.JoinlyClient) {
+	var meetingURL string
+	var session *meetingSession
+	for url, candidate := range m.sessions {
+		if _, active := candidate.active[agentID]; active {
+			meetingURL, session = url, candidate
+			break
+		}
+	}
+	if session == nil {
+		return
+	}
+
+	var cost models.CostTotals
+	if agent := m.agents[agentID]; agent != nil {
+		cost = billing.Subtract(agent.Cost, session.active[agentID])
+	}
+	delete(session.active, agentID)
+
+	record := &session.record
+	for i := range record.Agents {
+		entry := &record.Agents[i]
+		if entry.ID != agentID || !entry.LeftAt.IsZero() {
+			continue
+		}
+		entry.LeftAt = time.Now()
+		entry.Cost = cost
+		if analyst := m.analysts[agentID]; analyst != nil {
+			if analysis, err := json.Marshal(analyst.StoredAnalysis()); err == nil {
+				entry.Analysis = analysis
+			}
+			entry.AnalysisFile = analyst.FilePath()
+		}
+	}
+	record.Cost = billing.Add(record.Cost, cost)
+
+	// Agents in the same meeting hear the same conversation; keep the most complete transcript
+	if joinlyClient != nil {
+		segments := storedSegments(joinlyClient)
+		record.Participants = mergeParticipants(record.Participants, segments)
+		if len(segments) > len(record.Transcript) {
+			record.Transcript = segments
+		}
+	}
+
+	if len(session.active) > 0 {
+		return
+	}
+	delete(m.sessions, meetingURL)
+	m.archiveSession(session.record)
+}
+
+// archiveSession saves a finished meeting session. Sessions in which nothing was said or
+// analysed are dropped.
+func (m *AgentManager) archiveSession(record models.MeetingRecord) {
+	record.EndedAt = time.Now()
+	if record.Participants == nil {
+		record.Participants = []string{}
+	}
+
+	analysed := false
+	for _, agent := range record.Agents {
+		analysed = analysed || agent.AnalysisFile != ""
+	}
+	if len(record.Transcript) == 0 && !analysed {
+		logrus.Debugf("Not archiving meeting %s: nothing was said", record.MeetingURL)
+		return
+	}
+
+	saved, err := m.archive.Save(record)
+	if err != nil {
+		logrus.Errorf("Failed to archive meeting %s: %v", record.MeetingURL, err)
+		return
+	}
+	logrus.Infof("Archived meeting %s as %s (%d segments, %d agents)", record.MeetingURL, saved.ID, saved.Segments, len(saved.Agents))
+}
+
+// closeSessionsUnsafe archives every open meeting session, e.g. on shutdown (caller must hold lock)
+func (m *AgentManager) closeSessionsUnsafe() {
+	for _, session := range m.sessions {
+		for agentID := range session.active {
+			m.leaveSessionUnsafe(agentID, m.clients[agentID])
+		}
+	}
+}
+
+// runArchiveRetention applies the archive retention policy until ctx is cancelled
+func (m *AgentManager) runArchiveRetention(ctx context.Context) {
+	retention, maxRecords := m.config.Archive.Retention, m.config.Archive.MaxRecords
+	if retention <= 0 && maxRecords <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(archivePruneInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := m.archive.Prune(retention, maxRecords)
+		if err != nil {
+			logrus.Errorf("Failed to apply meeting archive retention: %v", err)
+		}
+		if deleted > 0 {
+			logrus.Infof("Deleted %d archived meetings past retention", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListMeetingHistory returns one page of archived meetings, newest first
+func (m *AgentManager) ListMeetingHistory(query archive.Query) models.MeetingHistory {
+	return m.archive.List(query)
+}
+
+// GetMeetingRecord returns an archived meeting with its transcript and analyses
+func (m *AgentManager) GetMeetingRecord(id string) (models.MeetingRecord, error) {
+	return m.archive.Get(id)
+}
+
+// DeleteMeetingRecord deletes an archived meeting and its analysis files
+func (m *AgentManager) DeleteMeetingRecord(id string) error {
+	return m.archive.Delete(id)
+}
+
+// storedSegments returns a client's meeting transcript as it may be archived: redacted, unless
+// the agent's policy persists raw text
+func storedSegments(joinlyClient *client.JoinlyClient) []models.MeetingSegment {
+	segments := joinlyClient.MeetingTranscript()
+	redactor := joinlyClient.Redactor()
+	if redactor.PersistsRaw() {
+		return segments
+	}
+	for i := range segments {
+		segments[i].Speaker = redactor.ForStorage(segments[i].Speaker)
+		segments[i].Text = redactor.ForStorage(segments[i].Text)
+	}
+	return segments
+}
+
+// mergeParticipants adds the human speakers of a transcript to participants
+func mergeParticipants(participants []string, segments []models.MeetingSegment) []string {
+	seen := make(map[string]bool, len(participants))
+	for _, participant := range participants {
+		seen[strings.ToLower(participant)] = true
+	}
+	for _, segment := range segments {
+		key := strings.ToLower(segment.Speaker)
+		if segment.IsAgent || segment.Speaker == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		participants = append(participants, segment.Speaker)
+	}
+	return participants
+}
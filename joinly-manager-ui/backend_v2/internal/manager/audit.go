@@ -0,0 +1,28 @@
+package manager
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/audit"
+	"joinly-manager/internal/models"
+)
+
+// RecordAudit appends a mutating action to the audit trail. Failures are logged; the action
+// has already happened.
+func (m *AgentManager) RecordAudit(entry models.AuditEntry) {
+	if _, err := m.audit.Append(entry); err != nil {
+		logrus.Errorf("Failed to record audit entry %s %s: %v", entry.Action, entry.Target, err)
+	}
+}
+
+// QueryAudit returns one page of the audit trail, newest first
+func (m *AgentManager) QueryAudit(query audit.Query) models.AuditPage {
+	return m.audit.Query(query)
+}
+
+// ExportAudit writes the matching audit entries to w as JSON lines, oldest first
+func (m *AgentManager) ExportAudit(query audit.Query, w io.Writer) (int, error) {
+	return m.audit.Export(query, w)
+}
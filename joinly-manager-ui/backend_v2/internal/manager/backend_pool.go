@@ -0,0 +1,367 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/models"
+)
+
+// BackendPool leases joinly MCP servers to agents. A joinly server can only sit
+// in one meeting at a time, so each running agent holds exactly one backend.
+type BackendPool struct {
+	backends   map[string]*models.JoinlyBackend
+	order      []string      // Registration order, used for deterministic leasing
+	released   chan struct{} // Closed and replaced whenever a backend becomes free
+	queued     int
+	interval   time.Duration
+	httpClient *http.Client
+	mu         sync.Mutex
+}
+
+// NewBackendPool creates a backend pool with the given static members
+func NewBackendPool(urls []string, interval time.Duration) *BackendPool {
+	pool := &BackendPool{
+		backends:   make(map[string]*models.JoinlyBackend),
+		released:   make(chan struct{}),
+		interval:   interval,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	for _, backendURL := range urls {
+		if _, err := pool.register(backendURL, true); err != nil {
+			logrus.Warnf("Skipping joinly backend %s: %v", backendURL, err)
+		}
+	}
+
+	return pool
+}
+
+// Run health-checks the pool until the context is cancelled
+func (p *BackendPool) Run(ctx context.Context) {
+	p.checkAll(ctx)
+
+	if p.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkAll(ctx)
+		}
+	}
+}
+
+// Register adds a joinly server to the pool
+func (p *BackendPool) Register(backendURL string) (*models.JoinlyBackend, error) {
+	backend, err := p.register(backendURL, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Probe the new member right away so it shows up with a real status
+	go p.check(context.Background(), backend.ID)
+
+	return backend, nil
+}
+
+// register adds a backend without probing it
+func (p *BackendPool) register(backendURL string, static bool) (*models.JoinlyBackend, error) {
+	parsed, err := url.Parse(backendURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid backend url")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, backend := range p.backends {
+		if backend.URL == backendURL {
+			return nil, fmt.Errorf("backend already registered")
+		}
+	}
+
+	backend := &models.JoinlyBackend{
+		ID:           fmt.Sprintf("backend_%s", uuid.New().String()[:8]),
+		URL:          backendURL,
+		Status:       models.BackendStatusUnknown,
+		Static:       static,
+		RegisteredAt: time.Now(),
+	}
+	p.backends[backend.ID] = backend
+	p.order = append(p.order, backend.ID)
+
+	// A new member may unblock queued agents
+	p.notifyReleasedUnsafe()
+
+	logrus.Infof("Registered joinly backend %s (%s)", backend.ID, backendURL)
+
+	backendCopy := *backend
+	return &backendCopy, nil
+}
+
+// Deregister removes a joinly server from the pool
+func (p *BackendPool) Deregister(backendID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	backend, exists := p.backends[backendID]
+	if !exists {
+		return fmt.Errorf("backend not found")
+	}
+
+	if backend.AgentID != "" {
+		return fmt.Errorf("backend in use")
+	}
+
+	delete(p.backends, backendID)
+	for i, id := range p.order {
+		if id == backendID {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+
+	logrus.Infof("Deregistered joinly backend %s (%s)", backendID, backend.URL)
+	return nil
+}
+
+// TryAcquire leases a free backend to the agent without waiting
+func (p *BackendPool) TryAcquire(agentID string) (*models.JoinlyBackend, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.acquireUnsafe(agentID)
+}
+
+// Acquire leases a free backend to the agent, waiting until one is released or the context ends
+func (p *BackendPool) Acquire(ctx context.Context, agentID string) (*models.JoinlyBackend, error) {
+	p.mu.Lock()
+	p.queued++
+	defer func() {
+		p.mu.Lock()
+		p.queued--
+		p.mu.Unlock()
+	}()
+
+	for {
+		backend, err := p.acquireUnsafe(agentID)
+		if err == nil {
+			p.mu.Unlock()
+			return backend, nil
+		}
+		released := p.released
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no joinly backend available")
+		case <-released:
+		}
+
+		p.mu.Lock()
+	}
+}
+
+// acquireUnsafe leases the first free, non-failing backend (caller must hold mutex)
+func (p *BackendPool) acquireUnsafe(agentID string) (*models.JoinlyBackend, error) {
+	for _, id := range p.order {
+		backend := p.backends[id]
+		if backend.AgentID == agentID {
+			backendCopy := *backend
+			return &backendCopy, nil
+		}
+	}
+
+	for _, id := range p.order {
+		backend := p.backends[id]
+		if backend.AgentID != "" || backend.Status == models.BackendStatusUnhealthy {
+			continue
+		}
+
+		now := time.Now()
+		backend.AgentID = agentID
+		backend.LeasedAt = &now
+
+		backendCopy := *backend
+		return &backendCopy, nil
+	}
+
+	return nil, fmt.Errorf("no joinly backend available")
+}
+
+// Release returns the agent's backend to the pool
+func (p *BackendPool) Release(agentID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, backend := range p.backends {
+		if backend.AgentID == agentID {
+			backend.AgentID = ""
+			backend.LeasedAt = nil
+			p.notifyReleasedUnsafe()
+			logrus.Debugf("Released joinly backend %s from agent %s", backend.ID, agentID)
+		}
+	}
+}
+
+// notifyReleasedUnsafe wakes up every queued Acquire call (caller must hold mutex)
+func (p *BackendPool) notifyReleasedUnsafe() {
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+// List returns copies of all backends in registration order
+func (p *BackendPool) List() []models.JoinlyBackend {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	backends := make([]models.JoinlyBackend, 0, len(p.order))
+	for _, id := range p.order {
+		backends = append(backends, *p.backends[id])
+	}
+	return backends
+}
+
+// Usage returns per-backend occupancy of the pool
+func (p *BackendPool) Usage() models.BackendPoolUsage {
+	backends := p.List()
+
+	p.mu.Lock()
+	queued := p.queued
+	p.mu.Unlock()
+
+	usage := models.BackendPoolUsage{
+		Total:    len(backends),
+		Queued:   queued,
+		Backends: backends,
+	}
+	for _, backend := range backends {
+		if backend.Status == models.BackendStatusHealthy {
+			usage.Healthy++
+		}
+		if backend.AgentID != "" {
+			usage.Leased++
+		} else if backend.Status != models.BackendStatusUnhealthy {
+			usage.Free++
+		}
+	}
+
+	return usage
+}
+
+// checkAll probes every backend in the pool
+func (p *BackendPool) checkAll(ctx context.Context) {
+	p.mu.Lock()
+	ids := make([]string, len(p.order))
+	copy(ids, p.order)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			p.check(ctx, id)
+		}(id)
+	}
+	wg.Wait()
+}
+
+// check probes the /health route of a single backend
+func (p *BackendPool) check(ctx context.Context, backendID string) {
+	p.mu.Lock()
+	backend, exists := p.backends[backendID]
+	if !exists {
+		p.mu.Unlock()
+		return
+	}
+	healthURL := healthURLFor(backend.URL)
+	p.mu.Unlock()
+
+	var checkErr error
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		checkErr = err
+	} else if resp, err := p.httpClient.Do(req); err != nil {
+		checkErr = err
+	} else {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			checkErr = fmt.Errorf("health check returned status %d", resp.StatusCode)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	backend, exists = p.backends[backendID]
+	if !exists {
+		return
+	}
+
+	now := time.Now()
+	backend.LastChecked = &now
+	previous := backend.Status
+
+	if checkErr != nil {
+		errorMsg := checkErr.Error()
+		backend.Status = models.BackendStatusUnhealthy
+		backend.LastError = &errorMsg
+		if previous != models.BackendStatusUnhealthy {
+			logrus.Warnf("Joinly backend %s (%s) is unhealthy: %v", backend.ID, backend.URL, checkErr)
+		}
+		return
+	}
+
+	backend.Status = models.BackendStatusHealthy
+	backend.LastError = nil
+	if previous == models.BackendStatusUnhealthy {
+		logrus.Infof("Joinly backend %s (%s) recovered", backend.ID, backend.URL)
+		p.notifyReleasedUnsafe()
+	}
+}
+
+// healthURLFor derives the joinly /health route from an MCP endpoint URL
+func healthURLFor(mcpURL string) string {
+	parsed, err := url.Parse(mcpURL)
+	if err != nil {
+		return mcpURL
+	}
+
+	path := strings.TrimSuffix(parsed.Path, "/")
+	path = strings.TrimSuffix(path, "/mcp")
+	parsed.Path = path + "/health"
+	parsed.RawQuery = ""
+
+	return parsed.String()
+}
+
+// ListBackends lists all joinly backends in the pool
+func (m *AgentManager) ListBackends() []models.JoinlyBackend {
+	return m.backends.List()
+}
+
+// RegisterBackend adds a joinly server to the backend pool
+func (m *AgentManager) RegisterBackend(backendURL string) (*models.JoinlyBackend, error) {
+	return m.backends.Register(backendURL)
+}
+
+// DeregisterBackend removes a free joinly server from the backend pool
+func (m *AgentManager) DeregisterBackend(backendID string) error {
+	return m.backends.Deregister(backendID)
+}
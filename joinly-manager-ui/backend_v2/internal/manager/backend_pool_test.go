@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+)
+
+func TestBackendPool_LeaseAndRelease(t *testing.T) {
+	pool := NewBackendPool([]string{"http://joinly-a:8000/mcp/", "http://joinly-b:8000/mcp/"}, 0)
+
+	first, err := pool.TryAcquire("agent_1")
+	if err != nil {
+		t.Fatalf("Expected a backend for agent_1, got error: %v", err)
+	}
+	second, err := pool.TryAcquire("agent_2")
+	if err != nil {
+		t.Fatalf("Expected a backend for agent_2, got error: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("Expected distinct backends, both agents got %s", first.ID)
+	}
+
+	// Leasing again for the same agent returns its existing backend
+	again, err := pool.TryAcquire("agent_1")
+	if err != nil || again.ID != first.ID {
+		t.Fatalf("Expected agent_1 to keep backend %s, got %v (%v)", first.ID, again, err)
+	}
+
+	if _, err := pool.TryAcquire("agent_3"); err == nil || err.Error() != "no joinly backend available" {
+		t.Fatalf("Expected pool exhaustion error, got %v", err)
+	}
+
+	usage := pool.Usage()
+	if usage.Total != 2 || usage.Leased != 2 || usage.Free != 0 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	pool.Release("agent_1")
+	third, err := pool.TryAcquire("agent_3")
+	if err != nil {
+		t.Fatalf("Expected released backend for agent_3, got error: %v", err)
+	}
+	if third.ID != first.ID {
+		t.Errorf("Expected agent_3 to get released backend %s, got %s", first.ID, third.ID)
+	}
+}
+
+func TestBackendPool_AcquireWaitsForRelease(t *testing.T) {
+	pool := NewBackendPool([]string{"http://joinly-a:8000/mcp/"}, 0)
+
+	if _, err := pool.TryAcquire("agent_1"); err != nil {
+		t.Fatalf("Expected a backend for agent_1, got error: %v", err)
+	}
+
+	acquired := make(chan *models.JoinlyBackend, 1)
+	go func() {
+		backend, err := pool.Acquire(context.Background(), "agent_2")
+		if err != nil {
+			t.Errorf("Queued acquire failed: %v", err)
+		}
+		acquired <- backend
+	}()
+
+	// Give the queued agent time to start waiting
+	time.Sleep(50 * time.Millisecond)
+	if queued := pool.Usage().Queued; queued != 1 {
+		t.Errorf("Expected 1 queued agent, got %d", queued)
+	}
+
+	pool.Release("agent_1")
+
+	select {
+	case backend := <-acquired:
+		if backend == nil || backend.AgentID != "agent_2" {
+			t.Errorf("Expected backend leased to agent_2, got %+v", backend)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Queued acquire was not woken up by release")
+	}
+}
+
+func TestBackendPool_AcquireTimesOut(t *testing.T) {
+	pool := NewBackendPool([]string{"http://joinly-a:8000/mcp/"}, 0)
+	if _, err := pool.TryAcquire("agent_1"); err != nil {
+		t.Fatalf("Expected a backend for agent_1, got error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := pool.Acquire(ctx, "agent_2"); err == nil {
+		t.Fatal("Expected queued acquire to time out")
+	}
+	if queued := pool.Usage().Queued; queued != 0 {
+		t.Errorf("Expected empty queue after timeout, got %d", queued)
+	}
+}
+
+func TestBackendPool_HealthCheckSkipsUnhealthyBackends(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("Expected health probe on /health, got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	pool := NewBackendPool([]string{unhealthy.URL + "/mcp/", healthy.URL + "/mcp/"}, 0)
+	pool.checkAll(context.Background())
+
+	backend, err := pool.TryAcquire("agent_1")
+	if err != nil {
+		t.Fatalf("Expected the healthy backend, got error: %v", err)
+	}
+	if backend.URL != healthy.URL+"/mcp/" {
+		t.Errorf("Expected healthy backend %s, got %s", healthy.URL, backend.URL)
+	}
+
+	usage := pool.Usage()
+	if usage.Healthy != 1 || usage.Free != 0 {
+		t.Errorf("Unexpected usage after health check: %+v", usage)
+	}
+}
+
+func TestBackendPool_RegisterAndDeregister(t *testing.T) {
+	pool := NewBackendPool(nil, 0)
+
+	if _, err := pool.Register("not a url"); err == nil {
+		t.Error("Expected invalid URL to be rejected")
+	}
+
+	backend, err := pool.Register("http://joinly-a:8000/mcp/")
+	if err != nil {
+		t.Fatalf("Failed to register backend: %v", err)
+	}
+	if _, err := pool.Register("http://joinly-a:8000/mcp/"); err == nil {
+		t.Error("Expected duplicate registration to be rejected")
+	}
+
+	if _, err := pool.TryAcquire("agent_1"); err != nil {
+		t.Fatalf("Expected registered backend to be leasable: %v", err)
+	}
+	if err := pool.Deregister(backend.ID); err == nil || err.Error() != "backend in use" {
+		t.Errorf("Expected leased backend to be protected, got %v", err)
+	}
+
+	pool.Release("agent_1")
+	if err := pool.Deregister(backend.ID); err != nil {
+		t.Errorf("Failed to deregister free backend: %v", err)
+	}
+	if len(pool.List()) != 0 {
+		t.Error("Expected empty pool after deregistration")
+	}
+}
+
+func TestHealthURLFor(t *testing.T) {
+	cases := map[string]string{
+		"http://joinly:8000/mcp/":    "http://joinly:8000/health",
+		"http://joinly:8000/mcp":     "http://joinly:8000/health",
+		"https://joinly.example.com": "https://joinly.example.com/health",
+	}
+	for input, expected := range cases {
+		if got := healthURLFor(input); got != expected {
+			t.Errorf("healthURLFor(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/metrics"
+	"joinly-manager/internal/models"
+)
+
+// recordUsage prices an LLM call made by an agent and enforces its budget
+func (m *AgentManager) recordUsage(agentID string, usage models.LLMUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, exists := m.agents[agentID]
+	if !exists {
+		return
+	}
+
+	before := agent.Cost.CostUSD
+	agent.Cost = m.ledger.Record(agentID, agent.Config.MeetingURL, agent.Config.Tenant, usage)
+	metrics.AddLLMCost(string(usage.Provider), usage.Model, agent.Cost.CostUSD-before)
+
+	m.enforceBudgetUnsafe(agentID, agent)
+}
+
+// enforceBudgetUnsafe applies the budget action once an agent has spent its budget (caller must hold lock)
+func (m *AgentManager) enforceBudgetUnsafe(agentID string, agent *models.Agent) {
+	budget := agent.Config.Budget
+	if budget == nil || budget.MaxCostUSD <= 0 || agent.BudgetExceeded || agent.Cost.CostUSD < budget.MaxCostUSD {
+		return
+	}
+	agent.BudgetExceeded = true
+
+	message := fmt.Sprintf("LLM budget of $%.2f exceeded ($%.4f spent)", budget.MaxCostUSD, agent.Cost.CostUSD)
+
+	switch budget.Action {
+	case models.BudgetActionDowngrade:
+		config := agent.Config
+		if budget.FallbackProvider != "" {
+			config.LLMProvider = budget.FallbackProvider
+		}
+		config.LLMModel = budget.FallbackModel
+		m.updateAgentConfigUnsafe(agentID, agent, config)
+		message += fmt.Sprintf(", switched to %s/%s", config.LLMProvider, config.LLMModel)
+	default:
+		message += ", agent silenced"
+	}
+
+	m.addLogEntry(agentID, "warn", message)
+	m.broadcastUpdate(agentID, "budget_exceeded", map[string]interface{}{
+		"action":   budget.Action,
+		"cost_usd": agent.Cost.CostUSD,
+	})
+	logrus.Warnf("Agent %s: %s", agentID, message)
+}
+
+// isSilencedUnsafe reports whether an agent's spent budget forbids further LLM calls (caller must hold lock)
+func isSilencedUnsafe(agent *models.Agent) bool {
+	return agent.BudgetExceeded && agent.Config.Budget != nil && agent.Config.Budget.Action == models.BudgetActionSilence
+}
@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/config"
+	"joinly-manager/internal/models"
+)
+
+// newTestManager returns a manager that accepts agents without starting background work
+func newTestManager() *AgentManager {
+	m := NewAgentManager(config.DefaultConfig())
+	m.running = true
+	return m
+}
+
+func TestRecordUsage_DowngradesWhenBudgetExceeded(t *testing.T) {
+	m := newTestManager()
+	agent, err := m.CreateAgent(models.AgentConfig{
+		Name:        "Assistant",
+		MeetingURL:  "https://meet.google.com/abc-defg-hij",
+		LLMProvider: models.LLMProviderOpenAI,
+		LLMModel:    "gpt-4o",
+		Tenant:      "acme",
+		Budget: &models.AgentBudget{
+			MaxCostUSD:    1.0,
+			Action:        models.BudgetActionDowngrade,
+			FallbackModel: "gpt-4o-mini",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	// $0.25 per call: under budget after the first call
+	usage := models.LLMUsage{Provider: models.LLMProviderOpenAI, Model: "gpt-4o", PromptTokens: 100_000}
+	m.recordUsage(agent.ID, usage)
+	if current, _ := m.GetAgent(agent.ID); current.BudgetExceeded || current.Config.LLMModel != "gpt-4o" {
+		t.Fatalf("Expected agent to stay on gpt-4o, got %+v", current.Config)
+	}
+
+	for i := 0; i < 3; i++ {
+		m.recordUsage(agent.ID, usage)
+	}
+
+	current, _ := m.GetAgent(agent.ID)
+	if !current.BudgetExceeded || current.Config.LLMModel != "gpt-4o-mini" {
+		t.Errorf("Expected downgrade to gpt-4o-mini, got exceeded=%v model=%s", current.BudgetExceeded, current.Config.LLMModel)
+	}
+	if current.Cost.Calls != 4 || current.Cost.CostUSD < 1.0 {
+		t.Errorf("Unexpected agent cost: %+v", current.Cost)
+	}
+	if m.GetUsageStats().Costs.ByTenant["acme"].Calls != 4 {
+		t.Errorf("Expected tenant breakdown in usage stats")
+	}
+}
+
+func TestRecordUsage_SilencesWhenBudgetExceeded(t *testing.T) {
+	m := newTestManager()
+	agent, err := m.CreateAgent(models.AgentConfig{
+		Name:        "Assistant",
+		MeetingURL:  "https://meet.google.com/abc-defg-hij",
+		LLMProvider: models.LLMProviderOpenAI,
+		LLMModel:    "gpt-4o",
+		Budget:      &models.AgentBudget{MaxCostUSD: 0.01, Action: models.BudgetActionSilence},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	m.recordUsage(agent.ID, models.LLMUsage{Provider: models.LLMProviderOpenAI, Model: "gpt-4o", PromptTokens: 10_000})
+
+	m.mu.RLock()
+	silenced := isSilencedUnsafe(m.agents[agent.ID])
+	m.mu.RUnlock()
+	if !silenced {
+		t.Error("Expected agent to be silenced")
+	}
+
+	// A silenced agent keeps recording the conversation; it makes no LLM calls to answer
+	m.mu.Lock()
+	m.clients[agent.ID] = &client.JoinlyClient{}
+	m.mu.Unlock()
+	m.processUtteranceTask(context.Background(), agent.ID, []map[string]interface{}{{"speaker": "Bob", "text": "When is the release?"}})
+	m.mu.RLock()
+	history := m.conversationHistory[agent.ID]
+	m.mu.RUnlock()
+	if len(history) != 1 || history[0].Speaker != "Bob" || history[0].IsAgent {
+		t.Errorf("Expected the utterance in the conversation history, got %+v", history)
+	}
+
+	// Raising the budget lifts the silence
+	config := agent.Config
+	config.Budget = &models.AgentBudget{MaxCostUSD: 10, Action: models.BudgetActionSilence}
+	update, err := m.UpdateAgentConfig(agent.ID, config)
+	if err != nil {
+		t.Fatalf("Failed to update budget: %v", err)
+	}
+	if update.Agent.BudgetExceeded {
+		t.Error("Expected raised budget to lift the silence")
+	}
+}
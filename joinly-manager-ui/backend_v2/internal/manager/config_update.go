@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/models"
+)
+
+// hotConfigFields are configuration fields a running agent picks up without a restart.
+// Everything else (meeting, speech and transcription settings) is fixed when joinly starts.
+// auto_join, env_vars, redaction and consent also need a restart: auto_join and env_vars are
+// startup settings, the consent policy is read when the agent joins its meeting, and the
+// redactor is built once when the client is created.
+var hotConfigFields = map[string]bool{
+	"custom_prompt":        true,
+	"personality_prompt":   true,
+	"system_prompt":        true,
+	"context_token_budget": true,
+	"llm_provider":         true,
+	"llm_model":            true,
+	"llm_fallbacks":        true,
+	"openai_compatible":    true,
+	"mock_llm":             true,
+	"canned_fallback":      true,
+	"name_trigger":         true,
+	"conversation_mode":    true,
+	"tenant":               true,
+	"budget":               true,
+	"identity":             true,
+	"meeting_series":       true,
+	"knowledge_bases":      true,
+	"turn_taking":          true,
+	"addressing":           true,
+	"interjection":         true,
+}
+
+// UpdateAgentConfig replaces an agent's configuration, applying hot fields to a running agent
+func (m *AgentManager) UpdateAgentConfig(agentID string, config models.AgentConfig) (*models.AgentConfigUpdate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, exists := m.agents[agentID]
+	if !exists {
+		return nil, fmt.Errorf("agent not found")
+	}
+
+	update := m.updateAgentConfigUnsafe(agentID, agent, config)
+
+	// A changed budget is re-evaluated against what the agent has already spent
+	for _, field := range update.Changed {
+		if field == "budget" {
+			agent.BudgetExceeded = false
+			m.enforceBudgetUnsafe(agentID, agent)
+			break
+		}
+	}
+
+	update.Agent = m.agentCopyUnsafe(agent)
+
+	return update, nil
+}
+
+// updateAgentConfigUnsafe replaces an agent's configuration and pushes hot fields to the
+// running client (caller must hold lock). The returned update has no Agent set.
+func (m *AgentManager) updateAgentConfigUnsafe(agentID string, agent *models.Agent, config models.AgentConfig) *models.AgentConfigUpdate {
+	oldConfig := agent.Config
+	changed := models.ChangedAgentConfigFields(oldConfig, config)
+
+	update := &models.AgentConfigUpdate{
+		Changed:         changed,
+		Applied:         []string{},
+		RestartRequired: []string{},
+	}
+
+	if len(changed) > 0 {
+		agent.Config = config
+
+		if joinlyClient := m.clients[agentID]; joinlyClient != nil {
+			m.applyRunningConfigUnsafe(agentID, joinlyClient, oldConfig, config)
+
+			for _, field := range changed {
+				if hotConfigFields[field] {
+					update.Applied = append(update.Applied, field)
+				} else {
+					update.RestartRequired = append(update.RestartRequired, field)
+				}
+			}
+		} else {
+			// A stopped agent uses its whole configuration when it starts; a running agent
+			// moves to a new meeting URL only when it is restarted
+			update.Applied = append(update.Applied, changed...)
+			m.syncAgentMeetingUnsafe(agentID, agent)
+		}
+
+		m.addLogEntry(agentID, "info", fmt.Sprintf("Configuration updated: %s", strings.Join(changed, ", ")))
+		if len(update.RestartRequired) > 0 {
+			m.addLogEntry(agentID, "warn", fmt.Sprintf("Restart required to apply: %s", strings.Join(update.RestartRequired, ", ")))
+		}
+		logrus.Infof("Updated configuration of agent %s (%s)", agentID, strings.Join(changed, ", "))
+	}
+
+	return update
+}
+
+// applyRunningConfigUnsafe pushes a new configuration to a running agent (caller must hold lock)
+func (m *AgentManager) applyRunningConfigUnsafe(agentID string, joinlyClient *client.JoinlyClient, oldConfig, config models.AgentConfig) {
+	joinlyClient.UpdateConfig(config)
+
+	// Switching conversation mode creates or drops the analyst
+	switch {
+	case config.ConversationMode == models.ConversationModeAnalyst && oldConfig.ConversationMode != models.ConversationModeAnalyst:
+		m.analysts[agentID] = client.NewAnalystAgent(agentID, config, joinlyClient)
+		m.addLogEntry(agentID, "info", "Analyst agent created for meeting analysis")
+	case config.ConversationMode != models.ConversationModeAnalyst && oldConfig.ConversationMode == models.ConversationModeAnalyst:
+		delete(m.analysts, agentID)
+		m.addLogEntry(agentID, "info", "Analyst agent removed")
+	default:
+		if analyst := m.analysts[agentID]; analyst != nil {
+			analyst.UpdateConfig(config)
+		}
+	}
+}
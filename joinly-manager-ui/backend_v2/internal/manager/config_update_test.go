@@ -0,0 +1,65 @@
+package manager
+
+import (
+	"reflect"
+	"testing"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/models"
+)
+
+func TestUpdateAgentConfig_MeetingURLMovesOnRestart(t *testing.T) {
+	m := newTestManager()
+	agent, err := m.CreateAgent(models.AgentConfig{
+		Name:        "Assistant",
+		MeetingURL:  "https://meet.google.com/abc-defg-hij",
+		LLMProvider: models.LLMProviderOpenAI,
+		LLMModel:    "gpt-4o",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	// A running agent stays listed under the meeting it joined
+	m.mu.Lock()
+	m.clients[agent.ID] = client.NewJoinlyClient(agent.ID, agent.Config, "")
+	m.mu.Unlock()
+
+	config := agent.Config
+	config.MeetingURL = "https://meet.google.com/xyz-uvwx-rst"
+	config.LLMModel = "gpt-4o-mini"
+	config.AutoJoin = !config.AutoJoin
+	update, err := m.UpdateAgentConfig(agent.ID, config)
+	if err != nil {
+		t.Fatalf("Failed to update agent: %v", err)
+	}
+	if !reflect.DeepEqual(update.Applied, []string{"llm_model"}) || !reflect.DeepEqual(update.RestartRequired, []string{"auto_join", "meeting_url"}) {
+		t.Errorf("Unexpected update of a running agent: applied %v, restart required %v", update.Applied, update.RestartRequired)
+	}
+	m.mu.RLock()
+	listed := m.listedMeetingUnsafe(agent.ID)
+	m.mu.RUnlock()
+	if listed != "https://meet.google.com/abc-defg-hij" {
+		t.Errorf("Expected the running agent to stay in its meeting, listed under %q", listed)
+	}
+
+	// Once stopped, the agent moves to the configured meeting and every change applies
+	m.mu.Lock()
+	delete(m.clients, agent.ID)
+	m.syncAgentMeetingUnsafe(agent.ID, m.agents[agent.ID])
+	m.mu.Unlock()
+
+	config.LLMModel = "gpt-4o"
+	config.EnvVars = map[string]string{"TEAM": "voice"}
+	update, err = m.UpdateAgentConfig(agent.ID, config)
+	if err != nil {
+		t.Fatalf("Failed to update agent: %v", err)
+	}
+	if !reflect.DeepEqual(update.Applied, []string{"env_vars", "llm_model"}) || len(update.RestartRequired) != 0 {
+		t.Errorf("Unexpected update of a stopped agent: applied %v, restart required %v", update.Applied, update.RestartRequired)
+	}
+	meetings := m.ListMeetings()
+	if len(meetings) != 1 || meetings[0].URL != config.MeetingURL || meetings[0].AgentCount != 1 {
+		t.Errorf("Expected the agent in the new meeting only, got %+v", meetings)
+	}
+}
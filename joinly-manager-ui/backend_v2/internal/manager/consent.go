@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/models"
+)
+
+// consentPrincipal is the audit principal of consent events the manager records by itself
+const consentPrincipal = "system"
+
+// discloseRecording announces an agent's disclosure after it joined its meeting. An agent that
+// cannot deliver it must not listen: it purges what it captured and leaves. Replays are not
+// meetings and get no disclosure.
+func (m *AgentManager) discloseRecording(agentID string, joinlyClient *client.JoinlyClient) {
+	m.mu.Lock()
+	agent, exists := m.agents[agentID]
+	replay := m.replays[agentID]
+	if !exists || agent.Config.Consent == nil || (replay != nil && replay.status.State == models.ReplayStateRunning) {
+		m.mu.Unlock()
+		return
+	}
+	agent.ConsentObjection = nil
+	config := agent.Config
+	m.mu.Unlock()
+
+	entry := models.AuditEntry{
+		Action:    "consent.disclosure",
+		Target:    agentID,
+		Params:    map[string]string{"agent_id": agentID, "meeting_url": config.MeetingURL},
+		Principal: consentPrincipal,
+		Outcome:   models.AuditSuccess,
+	}
+	if err := joinlyClient.Disclose(); err != nil {
+		entry.Outcome, entry.Error = models.AuditFailure, err.Error()
+		m.RecordAudit(entry)
+		m.addLogEntry(agentID, "error", fmt.Sprintf("Leaving the meeting without disclosure: %v", err))
+		m.withdrawFromMeeting(agentID, joinlyClient)
+		return
+	}
+	m.RecordAudit(entry)
+	m.addLogEntry(agentID, "info", fmt.Sprintf("Disclosed recording: %s", client.DisclosureMessage(config)))
+}
+
+// handleObjection makes an agent whose meeting objected to it purge what it captured and leave
+func (m *AgentManager) handleObjection(agentID string, objection models.ConsentObjection) {
+	m.mu.Lock()
+	agent, exists := m.agents[agentID]
+	joinlyClient := m.clients[agentID]
+	if !exists || joinlyClient == nil {
+		m.mu.Unlock()
+		return
+	}
+	agent.ConsentObjection = &objection
+	meetingURL := agent.Config.MeetingURL
+	m.mu.Unlock()
+
+	m.addLogEntry(agentID, "warn", fmt.Sprintf("%s objected via %s (%q): purging the transcript and leaving the meeting",
+		objection.Participant, objection.Source, objection.Phrase))
+	m.RecordAudit(models.AuditEntry{
+		Action: "consent.objection",
+		Target: agentID,
+		Params: map[string]string{
+			"agent_id":    agentID,
+			"meeting_url": meetingURL,
+			"source":      objection.Source,
+			"phrase":      objection.Phrase,
+		},
+		Principal: objection.Participant,
+		Outcome:   models.AuditSuccess,
+	})
+	m.broadcastUpdate(agentID, "consent_objection", map[string]interface{}{
+		"participant": objection.Participant,
+		"source":      objection.Source,
+		"phrase":      objection.Phrase,
+	})
+
+	m.withdrawFromMeeting(agentID, joinlyClient)
+}
+
+// withdrawFromMeeting stops an agent's analysis, purges everything it captured in the meeting
+// and makes it leave and stop. Nothing of the meeting is archived or remembered.
+func (m *AgentManager) withdrawFromMeeting(agentID string, joinlyClient *client.JoinlyClient) {
+	m.mu.Lock()
+	m.purgeMeetingDataUnsafe(agentID, joinlyClient)
+	m.mu.Unlock()
+
+	if err := joinlyClient.LeaveMeeting(); err != nil {
+		m.addLogEntry(agentID, "warn", fmt.Sprintf("Failed to leave meeting: %v", err))
+	}
+
+	m.mu.Lock()
+	// Replies that were in flight during the leave may have added turns
+	m.purgeMeetingDataUnsafe(agentID, joinlyClient)
+	err := m.stopAgent(agentID)
+	m.mu.Unlock()
+
+	if err != nil {
+		logrus.Errorf("Failed to stop agent %s after withdrawing from its meeting: %v", agentID, err)
+	}
+	m.addLogEntry(agentID, "info", "Purged the meeting transcript and left the meeting")
+}
+
+// purgeMeetingDataUnsafe discards the analysis, transcript and conversation an agent captured
+// in its current meeting (caller must hold lock)
+func (m *AgentManager) purgeMeetingDataUnsafe(agentID string, joinlyClient *client.JoinlyClient) {
+	if cancel, exists := m.utteranceTasks[agentID]; exists {
+		cancel()
+		delete(m.utteranceTasks, agentID)
+	}
+	if analyst := m.analysts[agentID]; analyst != nil {
+		// Discarding waits for a running analysis pass
+		go analyst.Discard()
+		delete(m.analysts, agentID)
+	}
+	joinlyClient.PurgeTranscript()
+
+	delete(m.transcripts, agentID)
+	delete(m.conversationHistory, agentID)
+	delete(m.summaries, agentID)
+	delete(m.interjections, agentID)
+}
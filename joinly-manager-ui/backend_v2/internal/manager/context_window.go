@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/client/llm"
+	"joinly-manager/internal/models"
+)
+
+const (
+	// defaultContextTokenBudget is the history budget of agents that do not set one
+	defaultContextTokenBudget = 2000
+	// maxConversationEntries caps the raw history kept per agent
+	maxConversationEntries = 200
+)
+
+// contextTokenBudget returns the number of history tokens sent with each reply request
+func contextTokenBudget(config models.AgentConfig) int {
+	if config.ContextTokenBudget > 0 {
+		return config.ContextTokenBudget
+	}
+	return defaultContextTokenBudget
+}
+
+// entryTokens estimates the tokens a conversation entry takes in the prompt
+func entryTokens(entry models.ConversationEntry) int {
+	return llm.EstimateTokens(entry.Speaker) + llm.EstimateTokens(entry.Message) + 4
+}
+
+// windowStart returns the index of the oldest entry that still fits the budget when
+// filling it with the newest entries first
+func windowStart(history []models.ConversationEntry, budget int) int {
+	used := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		used += entryTokens(history[i])
+		if used > budget {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// conversationWindow returns the rolling summary, what the agent's identity remembers about the
+// meeting and the current speaker, the retrieved knowledge passages and the newest turns that
+// fit the agent's token budget once the rest is accounted for
+func (m *AgentManager) conversationWindow(agentID, speaker string, passages []models.KnowledgePassage) models.ConversationWindow {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	window := models.ConversationWindow{Summary: m.summaries[agentID], Passages: passages}
+	agent, exists := m.agents[agentID]
+	if !exists {
+		return window
+	}
+
+	budget := contextTokenBudget(agent.Config) - llm.EstimateTokens(window.Summary)
+	window.Memories = m.recallMemoriesUnsafe(agentID, agent.Config, speaker)
+	for _, item := range window.Memories {
+		budget -= llm.EstimateTokens(item.Content) + 8
+	}
+	for _, passage := range passages {
+		budget -= llm.EstimateTokens(passage.Title+passage.Section+passage.Text) + 8
+	}
+
+	history := m.conversationHistory[agentID]
+	start := windowStart(history, budget)
+
+	window.Turns = make([]models.ConversationEntry, len(history)-start)
+	copy(window.Turns, history[start:])
+	return window
+}
+
+// compactConversation folds the turns that no longer fit the context window into the agent's
+// rolling summary. If summarisation fails the turns stay in the history (outside the window)
+// and are retried after the next reply.
+func (m *AgentManager) compactConversation(agentID string, joinlyClient *client.JoinlyClient) {
+	m.mu.Lock()
+	agent, exists := m.agents[agentID]
+	if !exists || m.compacting[agentID] {
+		m.mu.Unlock()
+		return
+	}
+
+	summary := m.summaries[agentID]
+	history := m.conversationHistory[agentID]
+	start := windowStart(history, contextTokenBudget(agent.Config)-llm.EstimateTokens(summary))
+	if start == 0 {
+		m.mu.Unlock()
+		return
+	}
+
+	overflow := make([]models.ConversationEntry, start)
+	copy(overflow, history[:start])
+	m.compacting[agentID] = true
+	m.mu.Unlock()
+
+	newSummary, err := joinlyClient.SummarizeConversation(m.ctx, summary, overflow)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.compacting, agentID)
+
+	if err != nil {
+		logrus.Warnf("Failed to summarise conversation of agent %s: %v", agentID, err)
+		return
+	}
+
+	// The history may have been capped meanwhile; only drop it if the summarised turns still lead it
+	current := m.conversationHistory[agentID]
+	last := overflow[len(overflow)-1]
+	if len(current) < len(overflow) || !current[len(overflow)-1].Timestamp.Equal(last.Timestamp) {
+		return
+	}
+
+	m.conversationHistory[agentID] = append([]models.ConversationEntry(nil), current[len(overflow):]...)
+	m.summaries[agentID] = newSummary
+	m.appendLog(agentID, models.LogEntry{
+		Timestamp: time.Now(),
+		Level:     "debug",
+		Message:   fmt.Sprintf("Summarised %d earlier conversation turns", len(overflow)),
+	})
+}
@@ -0,0 +1,39 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/models"
+)
+
+func TestConversationWindow_FitsTokenBudget(t *testing.T) {
+	m := newTestManager()
+	agent, err := m.CreateAgent(models.AgentConfig{
+		Name:               "Assistant",
+		MeetingURL:         "https://meet.google.com/abc-defg-hij",
+		LLMProvider:        models.LLMProviderOpenAI,
+		LLMModel:           "gpt-4o",
+		ContextTokenBudget: 100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	// Each turn is ~30 tokens, so only the newest three fit the budget
+	message := strings.Repeat("word ", 20)
+	for i := 0; i < 6; i++ {
+		m.updateConversationContext(agent.ID, "Alice", message, i%2 == 1)
+	}
+
+	window := m.conversationWindow(agent.ID, "Alice", nil)
+	if len(window.Turns) != 3 || !window.Turns[2].IsAgent {
+		t.Fatalf("Expected the 3 newest turns ending with the agent's, got %+v", window.Turns)
+	}
+
+	// A summary takes its share of the budget
+	m.summaries[agent.ID] = strings.Repeat("summary ", 25)
+	if window := m.conversationWindow(agent.ID, "Alice", nil); len(window.Turns) != 1 || window.Summary == "" {
+		t.Errorf("Expected the summary and 1 turn, got %d turns", len(window.Turns))
+	}
+}
@@ -6,19 +6,25 @@ import (
 	"strings"
 	"time"
 
+	"joinly-manager/internal/knowledge"
+	"joinly-manager/internal/metrics"
 	"joinly-manager/internal/models"
+	"joinly-manager/internal/tracing"
 )
 
-// handleUtterance processes utterances and generates LLM responses with task cancellation (like Python client)
-func (m *AgentManager) handleUtterance(agentID string, segments []map[string]interface{}) {
+// handleUtterance processes utterances and generates LLM responses with task cancellation (like Python client).
+// ctx carries the utterance trace started by the client.
+func (m *AgentManager) handleUtterance(ctx context.Context, agentID string, segments []map[string]interface{}) {
+	utterance := tracing.UtteranceFromContext(ctx)
 	if len(segments) == 0 {
+		utterance.Finish("skipped")
 		return
 	}
 
 	// Cancel any existing utterance processing task for this agent
 	m.mu.Lock()
 	if cancelFunc, exists := m.utteranceTasks[agentID]; exists {
-		m.addLogEntryUnsafe(agentID, models.LogEntry{
+		m.appendLog(agentID, models.LogEntry{
 			Timestamp: time.Now(),
 			Level:     "debug",
 			Message:   "Cancelling previous utterance processing task",
@@ -28,8 +34,8 @@ func (m *AgentManager) handleUtterance(agentID string, segments []map[string]int
 	}
 	m.mu.Unlock()
 
-	// Create context for this utterance processing task
-	utteranceCtx, cancelFunc := context.WithCancel(m.ctx)
+	// Create context for this utterance processing task, keeping the client's trace
+	utteranceCtx, cancelFunc := context.WithCancel(tracing.ContextWithUtterance(m.ctx, utterance))
 
 	// Store the cancel function
 	m.mu.Lock()
@@ -52,6 +58,12 @@ func (m *AgentManager) handleUtterance(agentID string, segments []map[string]int
 
 // processUtteranceTask handles the actual utterance processing (like Python _run_loop)
 func (m *AgentManager) processUtteranceTask(ctx context.Context, agentID string, segments []map[string]interface{}) {
+	// Every return path below finishes the trace; cancellation means a newer utterance superseded this one
+	outcome := "cancelled"
+	defer func() {
+		tracing.UtteranceFromContext(ctx).Finish(outcome)
+	}()
+
 	// Check if context was cancelled before starting
 	select {
 	case <-ctx.Done():
@@ -75,6 +87,7 @@ func (m *AgentManager) processUtteranceTask(ctx context.Context, agentID string,
 	}
 
 	if fullTranscript == "" {
+		outcome = "skipped"
 		return
 	}
 
@@ -84,19 +97,38 @@ func (m *AgentManager) processUtteranceTask(ctx context.Context, agentID string,
 	analyst, isAnalyst := m.analysts[agentID]
 	conversationMode := models.ConversationModeConversational
 	agentName := "Assistant"
+	silenced := false
+	var knowledgeBases []string
+	var policy *models.InterjectionConfig
 	if agentExists {
 		conversationMode = agent.Config.ConversationMode
 		agentName = agent.Config.Name
+		silenced = isSilencedUnsafe(agent)
+		knowledgeBases = agent.Config.KnowledgeBases
+		policy = agent.Config.Interjection
 	}
 	m.mu.RUnlock()
 
 	if !clientExists || !agentExists {
+		outcome = "skipped"
 		return
 	}
 
+	// An agent that spent its budget keeps listening, recording the transcript and conversation,
+	// but makes no more LLM calls
+	if !silenced {
+		metrics.IncUtterancesProcessed(agentID)
+	}
+
 	// Handle analyst mode differently - no responses, just analysis
 	if conversationMode == models.ConversationModeAnalyst {
-		if isAnalyst {
+		outcome = "analyzed"
+		if silenced {
+			outcome = "silenced"
+			if isAnalyst {
+				analyst.RecordUtterance(segments)
+			}
+		} else if isAnalyst {
 			analyst.ProcessUtterance(segments)
 			m.addLogEntry(agentID, "info", fmt.Sprintf("📊 Analysis updated for %s", speaker))
 		}
@@ -113,11 +145,24 @@ func (m *AgentManager) processUtteranceTask(ctx context.Context, agentID string,
 	// Log only the unique utterance received - single log per speech
 	m.addLogEntry(agentID, "info", fmt.Sprintf("🎤 %s: \"%s\"", speaker, fullTranscript))
 
-	// Update conversation context
-	m.updateConversationContext(agentID, speaker, fullTranscript)
+	if silenced {
+		m.updateConversationContext(agentID, speaker, fullTranscript, false)
+		outcome = "silenced"
+		return
+	}
+
+	// Get knowledge base passages and conversation context for better LLM responses, then record the new utterance
+	passages := m.retrieveKnowledge(ctx, agentID, knowledgeBases, fullTranscript)
+	window := m.conversationWindow(agentID, speaker, passages)
+	m.updateConversationContext(agentID, speaker, fullTranscript, false)
 
-	// Get conversation context for better LLM responses
-	conversationContext := m.getConversationContext(agentID)
+	// The interjection policy may keep the agent silent; the utterance stays in the history
+	decision := m.decideInterjection(ctx, agentID, client, policy, speaker, fullTranscript, window)
+	if !decision.Speak {
+		outcome = "policy_silent"
+		return
+	}
+	window.Interjection = decision.Guidance
 
 	// Check for cancellation before LLM call
 	select {
@@ -127,7 +172,7 @@ func (m *AgentManager) processUtteranceTask(ctx context.Context, agentID string,
 	}
 
 	// Generate response using consolidated full transcript with conversation context
-	response := client.GenerateResponseWithContext(speaker, fullTranscript, conversationContext)
+	response := client.GenerateResponseWithContext(ctx, speaker, fullTranscript, window)
 
 	// Check for cancellation after LLM call
 	select {
@@ -137,20 +182,55 @@ func (m *AgentManager) processUtteranceTask(ctx context.Context, agentID string,
 	}
 
 	if response != "" {
+		if policy != nil {
+			m.recordInterjection(agentID)
+		}
+
+		// Citation markers are reported as sources rather than spoken
+		sources, spoken := knowledge.Citations(response, window.Passages)
+		response = spoken
+		m.broadcastUpdate(agentID, "agent_reply", map[string]interface{}{
+			"speaker":   speaker,
+			"utterance": fullTranscript,
+			"reply":     response,
+			"sources":   sources,
+		})
+
 		// Log only the agent's response - single log per response
 		m.addLogEntry(agentID, "info", fmt.Sprintf("🤖 %s: %s", agentName, response))
+		if len(sources) > 0 {
+			m.addLogEntry(agentID, "info", fmt.Sprintf("📚 Sources: %s", formatSources(sources)))
+		}
 		// Add assistant response to conversation context
-		m.updateConversationContext(agentID, "Assistant", response)
+		m.updateConversationContext(agentID, agentName, response, true)
+		// Fold turns that no longer fit the context window into the summary, off the reply path
+		go m.compactConversation(agentID, client)
 
 		// Speak the response
-		if err := client.SpeakText(response); err != nil {
+		speech, err := client.SpeakText(ctx, response)
+		if err != nil {
 			m.addLogEntry(agentID, "error", fmt.Sprintf("Failed to speak: %v", err))
+			outcome = "speak_failed"
+			return
+		}
+		if speech.Interrupted {
+			// Remember only what participants actually heard
+			m.reviseAgentTurn(agentID, response, interruptedTurn(speech.Spoken))
+			m.broadcastUpdate(agentID, "agent_interrupted", map[string]interface{}{
+				"reply":  response,
+				"spoken": speech.Spoken,
+			})
+			outcome = "interrupted"
+			return
 		}
+		outcome = "delivered"
+	} else {
+		outcome = "no_reply"
 	}
 }
 
 // updateConversationContext updates the conversation context for an agent
-func (m *AgentManager) updateConversationContext(agentID, speaker, message string) {
+func (m *AgentManager) updateConversationContext(agentID, speaker, message string, isAgent bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -163,42 +243,52 @@ func (m *AgentManager) updateConversationContext(agentID, speaker, message strin
 		Speaker:   speaker,
 		Message:   message,
 		Timestamp: time.Now(),
+		IsAgent:   isAgent,
 	}
 
 	// Add to conversation history
 	m.conversationHistory[agentID] = append(m.conversationHistory[agentID], entry)
 
-	// Keep only last 20 entries to prevent memory bloat
-	maxEntries := 20
-	if len(m.conversationHistory[agentID]) > maxEntries {
-		m.conversationHistory[agentID] = m.conversationHistory[agentID][len(m.conversationHistory[agentID])-maxEntries:]
+	// Older turns are normally folded into the summary; this cap only prevents memory bloat
+	// when summarisation keeps failing
+	if len(m.conversationHistory[agentID]) > maxConversationEntries {
+		m.conversationHistory[agentID] = m.conversationHistory[agentID][len(m.conversationHistory[agentID])-maxConversationEntries:]
 	}
 }
 
-// getConversationContext builds a context string for an agent
-func (m *AgentManager) getConversationContext(agentID string) string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if m.conversationHistory == nil {
-		return "No previous context."
-	}
+// reviseAgentTurn replaces the newest agent turn with the given message, unless it has
+// already been folded into the summary
+func (m *AgentManager) reviseAgentTurn(agentID, message, revised string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	history, exists := m.conversationHistory[agentID]
-	if !exists || len(history) == 0 {
-		return "No previous context."
+	history := m.conversationHistory[agentID]
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].IsAgent {
+			if history[i].Message == message {
+				history[i].Message = revised
+			}
+			return
+		}
 	}
+}
 
-	var contextLines []string
-	// Use last 10 entries for context to avoid token limits
-	startIdx := len(history) - 10
-	if startIdx < 0 {
-		startIdx = 0
+// interruptedTurn renders the part of a reply spoken before the agent was interrupted
+func interruptedTurn(spoken string) string {
+	if spoken == "" {
+		return "(interrupted before speaking)"
 	}
+	return spoken + " (interrupted)"
+}
 
-	for _, entry := range history[startIdx:] {
-		contextLines = append(contextLines, fmt.Sprintf("%s: %s", entry.Speaker, entry.Message))
+// formatSources lists cited passages as "[n] Title > Section"
+func formatSources(sources []models.KnowledgePassage) string {
+	labels := make([]string, len(sources))
+	for i, source := range sources {
+		labels[i] = fmt.Sprintf("[%d] %s", source.Citation, source.Title)
+		if source.Section != "" {
+			labels[i] += " > " + source.Section
+		}
 	}
-
-	return strings.Join(contextLines, "\n")
+	return strings.Join(labels, ", ")
 }
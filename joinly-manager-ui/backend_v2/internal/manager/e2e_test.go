@@ -0,0 +1,512 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/archive"
+	"joinly-manager/internal/audit"
+	"joinly-manager/internal/config"
+	"joinly-manager/internal/joinlytest"
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/replay"
+	"joinly-manager/internal/storage"
+)
+
+// fakeLLM is an OpenAI-compatible chat completions endpoint answering with scripted replies
+type fakeLLM struct {
+	*httptest.Server
+	mu       sync.Mutex
+	replies  []string
+	requests [][]map[string]interface{} // Messages of every request
+}
+
+// newFakeLLM starts an LLM endpoint that answers the given replies in order, repeating the last
+func newFakeLLM(t *testing.T, replies ...string) *fakeLLM {
+	f := &fakeLLM{replies: replies}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		reply := f.replies[0]
+		if len(f.replies) > 1 {
+			f.replies = f.replies[1:]
+		}
+		f.requests = append(f.requests, request.Messages)
+		f.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"content": reply}}},
+			"usage":   map[string]interface{}{"prompt_tokens": 100, "completion_tokens": 10},
+		})
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// lastRequest returns the messages of the newest LLM request
+func (f *fakeLLM) lastRequest() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return nil
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+// startFakeMeeting runs an agent against a fake joinly server and LLM and waits until it joined
+func startFakeMeeting(t *testing.T, llm *fakeLLM, configure func(*models.AgentConfig)) (*AgentManager, *joinlytest.Server, string) {
+	joinly := joinlytest.NewServer()
+	t.Cleanup(joinly.Close)
+
+	m, agentID := joinFakeMeeting(t, joinly, llm, configure)
+	return m, joinly, agentID
+}
+
+// joinFakeMeeting runs an agent against a prepared fake joinly server and waits until it joined
+func joinFakeMeeting(t *testing.T, joinly *joinlytest.Server, llm *fakeLLM, configure func(*models.AgentConfig)) (*AgentManager, string) {
+	cfg := config.DefaultConfig()
+	cfg.Joinly.DefaultURL = joinly.URL
+	cfg.Storage.DataDir = t.TempDir()
+	cfg.Memory.Dir = t.TempDir()
+	cfg.Knowledge.Dir = t.TempDir()
+	cfg.Archive.Dir = t.TempDir()
+	cfg.Audit.Dir = t.TempDir()
+	cfg.Storage.Encryption = storage.ProviderLocal
+	cfg.Storage.KeyFile = filepath.Join(t.TempDir(), "master.json")
+	m := NewAgentManager(cfg)
+	m.running = true
+	t.Cleanup(func() { m.Stop() })
+
+	agentConfig := models.AgentConfig{
+		Name:             "Ada",
+		MeetingURL:       "https://meet.google.com/abc-defg-hij",
+		LLMProvider:      models.LLMProviderOpenAICompatible,
+		LLMModel:         "test-model",
+		OpenAICompatible: &models.OpenAICompatibleConfig{BaseURL: llm.URL},
+		AutoJoin:         true,
+		TurnTaking:       &models.TurnTakingConfig{DebounceMs: 200, QuestionDebounceMs: 200, IncompleteDebounceMs: 200},
+	}
+	if configure != nil {
+		configure(&agentConfig)
+	}
+
+	agent, err := m.CreateAgent(agentConfig)
+	if err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	if err := m.StartAgent(agent.ID); err != nil {
+		t.Fatalf("StartAgent: %v", err)
+	}
+	if !joinly.WaitFor(5*time.Second, func() bool { _, joined := joinly.Joined(); return joined }) {
+		t.Fatal("agent did not join the meeting")
+	}
+	return m, agent.ID
+}
+
+func TestEndToEnd_AnswersParticipant(t *testing.T) {
+	llm := newFakeLLM(t, `{"assistant_reply": "The release is on Friday."}`)
+	m, joinly, agentID := startFakeMeeting(t, llm, nil)
+
+	if join, _ := joinly.Joined(); join.ParticipantName != "Ada" || join.MeetingURL != "https://meet.google.com/abc-defg-hij" {
+		t.Errorf("unexpected join: %+v", join)
+	}
+	if joinly.Settings()["name"] != "Ada" {
+		t.Errorf("unexpected joinly settings: %v", joinly.Settings())
+	}
+
+	joinly.Say("Bob", "When is the release?")
+	spoken, err := joinly.WaitForSpeech(1, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spoken[0] != "The release is on Friday." {
+		t.Errorf("agent said %q", spoken[0])
+	}
+
+	messages := llm.lastRequest()
+	if last := messages[len(messages)-1]; last["role"] != "user" || last["content"] != "Bob: When is the release?" {
+		t.Errorf("unexpected last prompt message: %v", last)
+	}
+
+	// Both sides of the exchange are in the conversation history
+	if !joinly.WaitFor(time.Second, func() bool { return len(m.conversationWindow(agentID, "Bob", nil).Turns) == 2 }) {
+		t.Errorf("unexpected history: %+v", m.conversationWindow(agentID, "Bob", nil).Turns)
+	}
+
+	if err := m.StopAgent(agentID); err != nil {
+		t.Fatalf("StopAgent: %v", err)
+	}
+
+	// The meeting outlives the agent in the archive
+	if err := m.DeleteAgent(agentID); err != nil {
+		t.Fatalf("DeleteAgent: %v", err)
+	}
+	history := m.ListMeetingHistory(archive.Query{AgentID: agentID})
+	if history.Total != 1 || history.Meetings[0].Transcript != nil {
+		t.Fatalf("unexpected meeting history: %+v", history)
+	}
+	record, err := m.GetMeetingRecord(history.Meetings[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.MeetingURL != "https://meet.google.com/abc-defg-hij" || len(record.Participants) != 1 || record.Participants[0] != "Bob" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+	if len(record.Agents) != 1 || record.Agents[0].Name != "Ada" || record.Agents[0].LeftAt.IsZero() || !record.EndedAt.After(record.StartedAt) {
+		t.Errorf("unexpected agents: %+v", record.Agents)
+	}
+	if len(record.Transcript) == 0 || record.Transcript[0].Text != "When is the release?" {
+		t.Errorf("unexpected transcript: %+v", record.Transcript)
+	}
+
+	// Only the manager can read the record on disk
+	data, err := os.ReadFile(filepath.Join(m.config.Archive.Dir, record.ID+".json"))
+	if err != nil || !storage.IsSealed(data) || strings.Contains(string(data), "release") {
+		t.Errorf("Expected an encrypted record file, got %.80s (%v)", data, err)
+	}
+}
+
+func TestEndToEnd_NameTrigger(t *testing.T) {
+	llm := newFakeLLM(t, `{"assistant_reply": "Noted."}`)
+	_, joinly, _ := startFakeMeeting(t, llm, func(config *models.AgentConfig) {
+		config.NameTrigger = true
+		config.Addressing = &models.AddressingConfig{FollowUpSeconds: -1}
+	})
+
+	joinly.Say("Bob", "Let's look at https://ada.example.com later.")
+	time.Sleep(1500 * time.Millisecond)
+	if spoken := joinly.Spoken(); len(spoken) != 0 {
+		t.Fatalf("agent answered speech not addressed to it: %q", spoken)
+	}
+
+	joinly.Say("Bob", "Ada, please note that down.")
+	if _, err := joinly.WaitForSpeech(1, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// The earlier segment is sent along as context
+	messages := llm.lastRequest()
+	if content, _ := messages[len(messages)-1]["content"].(string); !strings.Contains(content, "later") {
+		t.Errorf("expected pending speech in the prompt, got %q", content)
+	}
+}
+
+func TestEndToEnd_Interrupted(t *testing.T) {
+	llm := newFakeLLM(t, `{"assistant_reply": "We ship on Friday after the final QA pass and the release notes review."}`)
+	m, joinly, agentID := startFakeMeeting(t, llm, nil)
+
+	joinly.InterruptNextSpeech()
+	joinly.Say("Bob", "When do we ship?")
+	spoken, err := joinly.WaitForSpeech(1, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only what was actually said is remembered
+	if !joinly.WaitFor(time.Second, func() bool {
+		turns := m.conversationWindow(agentID, "Bob", nil).Turns
+		return len(turns) == 2 && turns[1].Message == spoken[0]+" (interrupted)"
+	}) {
+		t.Errorf("unexpected history: %+v", m.conversationWindow(agentID, "Bob", nil).Turns)
+	}
+}
+
+func TestEndToEnd_BargeIn(t *testing.T) {
+	reply := "We ship on Friday after the final QA pass and the release notes review. " +
+		"The QA pass covers the upgrade path, the new importer and the billing changes from last sprint."
+	llm := newFakeLLM(t, `{"assistant_reply": "`+reply+`"}`)
+	m, joinly, agentID := startFakeMeeting(t, llm, nil)
+	joinly.SetSpeakDelay(3 * time.Second)
+
+	joinly.Say("Bob", "When do we ship?")
+	if !joinly.WaitFor(10*time.Second, func() bool { return joinly.Calls("speak_text") == 1 }) {
+		t.Fatal("agent did not start speaking")
+	}
+	joinly.Say("Carol", "Sorry, quick question about QA")
+
+	// The agent is muted to stop joinly mid-chunk and unmuted once the chunk is over
+	if !joinly.WaitFor(5*time.Second, func() bool { return joinly.Calls("unmute_yourself") == 1 }) {
+		t.Fatalf("expected mute and unmute, got %d mute calls", joinly.Calls("mute_yourself"))
+	}
+	if joinly.Calls("mute_yourself") != 1 || joinly.Muted() {
+		t.Errorf("unexpected mute state: %d mute calls, muted %v", joinly.Calls("mute_yourself"), joinly.Muted())
+	}
+
+	spoken := joinly.Spoken()
+	if len(spoken) != 1 || len(spoken[0]) >= len(reply) || !strings.HasPrefix(reply, spoken[0]) {
+		t.Fatalf("expected the reply cut short, got %q", spoken)
+	}
+	if !joinly.WaitFor(time.Second, func() bool {
+		turns := m.conversationWindow(agentID, "Bob", nil).Turns
+		return len(turns) >= 2 && turns[1].Message == spoken[0]+" (interrupted)"
+	}) {
+		t.Errorf("unexpected history: %+v", m.conversationWindow(agentID, "Bob", nil).Turns)
+	}
+}
+
+func TestEndToEnd_ConsentWindow(t *testing.T) {
+	llm := newFakeLLM(t, `{"assistant_reply": "On Friday."}`)
+	_, joinly, _ := startFakeMeeting(t, llm, func(config *models.AgentConfig) {
+		config.Consent = &models.ConsentConfig{ObjectionWindowSeconds: 1}
+	})
+
+	// The disclosure is spoken and posted before anything else
+	if _, err := joinly.WaitForSpeech(1, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	disclosure := joinly.Spoken()[0]
+	if !strings.Contains(disclosure, "I'm Ada") || !strings.Contains(disclosure, `"stop recording"`) {
+		t.Errorf("unexpected disclosure %q", disclosure)
+	}
+	if chat := joinly.ChatMessages(); len(chat) != 1 || chat[0].Text != disclosure {
+		t.Errorf("expected the disclosure in the chat, got %+v", chat)
+	}
+
+	// Questions asked during the objection window are answered once it has passed
+	joinly.Say("Bob", "When is the release?")
+	time.Sleep(300 * time.Millisecond)
+	if spoken := joinly.Spoken(); len(spoken) != 1 {
+		t.Fatalf("agent answered during the objection window: %q", spoken)
+	}
+	spoken, err := joinly.WaitForSpeech(2, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spoken[1] != "On Friday." {
+		t.Errorf("agent said %q", spoken[1])
+	}
+}
+
+func TestEndToEnd_ConsentInterruptedDisclosure(t *testing.T) {
+	llm := newFakeLLM(t, `{"assistant_reply": "On Friday."}`)
+	joinly := joinlytest.NewServer()
+	t.Cleanup(joinly.Close)
+	joinly.InterruptNextSpeech()
+	m, agentID := joinFakeMeeting(t, joinly, llm, func(config *models.AgentConfig) {
+		config.Consent = &models.ConsentConfig{Delivery: models.ConsentDeliverySpeak}
+	})
+
+	// A participant talked over the disclosure, so it is posted to the chat
+	if !joinly.WaitFor(5*time.Second, func() bool { return len(joinly.ChatMessages()) == 1 }) {
+		t.Fatal("agent did not post the interrupted disclosure")
+	}
+	if chat := joinly.ChatMessages()[0].Text; !strings.Contains(chat, "I'm Ada") {
+		t.Errorf("unexpected disclosure %q", chat)
+	}
+	if spoken := joinly.Spoken(); len(spoken) != 1 || strings.Contains(spoken[0], "stop recording") {
+		t.Errorf("expected a cut-off disclosure, got %q", spoken)
+	}
+	if agent, _ := m.GetAgent(agentID); agent.Status != models.AgentStatusRunning {
+		t.Errorf("agent did not stay after disclosing in the chat: %s", agent.Status)
+	}
+}
+
+func TestEndToEnd_ConsentObjection(t *testing.T) {
+	llm := newFakeLLM(t, `{"assistant_reply": "On Friday."}`)
+	m, joinly, agentID := startFakeMeeting(t, llm, func(config *models.AgentConfig) {
+		config.Consent = &models.ConsentConfig{Delivery: models.ConsentDeliveryChat}
+	})
+	if !joinly.WaitFor(5*time.Second, func() bool { return len(joinly.ChatMessages()) == 1 }) {
+		t.Fatal("agent did not post the disclosure")
+	}
+
+	joinly.Say("Bob", "When is the release?")
+	if _, err := joinly.WaitForSpeech(1, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	joinly.PostChat("Carol", "Please stop recording, this is confidential.")
+	if !joinly.WaitFor(10*time.Second, func() bool {
+		agent, _ := m.GetAgent(agentID)
+		return agent.Status == models.AgentStatusStopped
+	}) {
+		t.Fatal("agent did not stop after the objection")
+	}
+	if _, joined := joinly.Joined(); joined {
+		t.Error("agent did not leave the meeting")
+	}
+
+	agent, _ := m.GetAgent(agentID)
+	if objection := agent.ConsentObjection; objection == nil || objection.Participant != "Carol" || objection.Source != "chat" || objection.Phrase != "stop recording" {
+		t.Errorf("unexpected objection: %+v", objection)
+	}
+
+	// Nothing captured in the meeting is kept
+	if segments, _ := m.GetAgentTranscript(agentID); len(segments) != 0 {
+		t.Errorf("expected a purged transcript, got %+v", segments)
+	}
+	if turns := m.conversationWindow(agentID, "Bob", nil).Turns; len(turns) != 0 {
+		t.Errorf("expected a purged conversation, got %+v", turns)
+	}
+	if history := m.ListMeetingHistory(archive.Query{}); history.Total != 0 {
+		t.Errorf("expected no archived meeting, got %+v", history)
+	}
+
+	page := m.QueryAudit(audit.Query{Action: "consent."})
+	if page.Total != 2 || page.Entries[0].Action != "consent.objection" || page.Entries[0].Principal != "Carol" || page.Entries[1].Action != "consent.disclosure" {
+		t.Errorf("unexpected audit trail: %+v", page.Entries)
+	}
+}
+
+func TestEndToEnd_MockProvider(t *testing.T) {
+	llm := newFakeLLM(t, `{"assistant_reply": "unused"}`)
+	_, joinly, _ := startFakeMeeting(t, llm, func(config *models.AgentConfig) {
+		config.LLMProvider = models.LLMProviderMock
+		config.LLMModel = "scripted"
+		config.MockLLM = &models.MockLLMConfig{Responses: []models.MockResponse{
+			{Pattern: `(?i)budget`, Response: `{"assistant_reply": "The budget is approved."}`},
+		}}
+	})
+
+	joinly.Say("Bob", "What about the budget?")
+	spoken, err := joinly.WaitForSpeech(1, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spoken[0] != "The budget is approved." {
+		t.Errorf("agent said %q", spoken[0])
+	}
+	if llm.lastRequest() != nil {
+		t.Error("mock agent called the LLM endpoint")
+	}
+}
+
+// replayAgent creates a stopped agent answering from the mock provider, for replays
+func replayAgent(t *testing.T, configure func(*models.AgentConfig)) (*AgentManager, string) {
+	cfg := config.DefaultConfig()
+	cfg.Storage.DataDir = t.TempDir()
+	cfg.Memory.Dir = t.TempDir()
+	cfg.Knowledge.Dir = t.TempDir()
+	cfg.Archive.Dir = t.TempDir()
+	cfg.Audit.Dir = t.TempDir()
+	m := NewAgentManager(cfg)
+	m.running = true
+	t.Cleanup(func() { m.Stop() })
+
+	agentConfig := models.AgentConfig{
+		Name:        "Ada",
+		MeetingURL:  "https://meet.google.com/abc-defg-hij",
+		LLMProvider: models.LLMProviderMock,
+		LLMModel:    "scripted",
+		TurnTaking:  &models.TurnTakingConfig{DebounceMs: 200, QuestionDebounceMs: 200, IncompleteDebounceMs: 200},
+	}
+	configure(&agentConfig)
+
+	agent, err := m.CreateAgent(agentConfig)
+	if err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	return m, agent.ID
+}
+
+func TestEndToEnd_ReplayConversation(t *testing.T) {
+	m, agentID := replayAgent(t, func(config *models.AgentConfig) {
+		config.MockLLM = &models.MockLLMConfig{Responses: []models.MockResponse{
+			// The prompt carries the history, so the newer question's rule comes first
+			{Pattern: `(?i)release`, Response: `{"assistant_reply": "The release is on Friday."}`},
+			{Pattern: `(?i)budget`, Response: `{"assistant_reply": "The budget is approved."}`},
+		}}
+	})
+
+	// The recorded agent reply is skipped, and the minute of silence is compressed
+	segments := []replay.Segment{
+		{Speaker: "Bob", Text: "What about the budget?", Start: 0, End: 2},
+		{Speaker: "Ada", Text: "It was approved yesterday.", Start: 3, End: 5, Assistant: true},
+		{Speaker: "Carol", Text: "And when is the release?", Start: 60, End: 62},
+	}
+	if _, err := m.StartReplay(agentID, "meeting.vtt", segments, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.StartReplay(agentID, "meeting.vtt", segments, 100); err == nil || err.Error() != "replay already running" {
+		t.Errorf("Expected a second replay to be refused, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	status, err := m.WaitReplay(ctx, agentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.State != models.ReplayStateCompleted || status.Replayed != 2 || status.Skipped != 1 {
+		t.Fatalf("unexpected replay status: %+v", status)
+	}
+	expected := []string{"The budget is approved.", "The release is on Friday."}
+	if strings.Join(status.Replies, "|") != strings.Join(expected, "|") {
+		t.Errorf("agent replied %q, expected %q", status.Replies, expected)
+	}
+	if agent, _ := m.GetAgent(agentID); agent.Status != models.AgentStatusStopped {
+		t.Errorf("Expected the agent to be stopped after the replay, got %s", agent.Status)
+	}
+
+	// The transcript of the finished meeting interleaves participants and flagged agent replies
+	transcript, err := m.GetAgentTranscript(agentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, segment := range transcript {
+		lines = append(lines, fmt.Sprintf("%s %t: %s", segment.Speaker, segment.IsAgent, segment.Text))
+	}
+	expectedLines := []string{
+		"Bob false: What about the budget?",
+		"Ada true: The budget is approved.",
+		"Carol false: And when is the release?",
+		"Ada true: The release is on Friday.",
+	}
+	if strings.Join(lines, "|") != strings.Join(expectedLines, "|") {
+		t.Errorf("unexpected transcript %q", lines)
+	}
+
+	// Replays are not meetings and stay out of the archive
+	if history := m.ListMeetingHistory(archive.Query{}); history.Total != 0 {
+		t.Errorf("Expected no archived meeting, got %+v", history)
+	}
+}
+
+func TestEndToEnd_ReplayAnalysis(t *testing.T) {
+	m, agentID := replayAgent(t, func(config *models.AgentConfig) {
+		config.ConversationMode = models.ConversationModeAnalyst
+		config.MockLLM = &models.MockLLMConfig{Responses: []models.MockResponse{
+			{Schema: "summary", Response: `{"summary": "The team approved the budget."}`},
+		}}
+	})
+
+	segments := []replay.Segment{
+		{Speaker: "Bob", Text: "Let's approve the budget.", Start: 0, End: 2},
+		{Speaker: "Carol", Text: "Agreed, approved.", Start: 5, End: 6},
+	}
+	if _, err := m.StartReplay(agentID, "segments.jsonl", segments, 10); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if status, err := m.WaitReplay(ctx, agentID); err != nil || status.State != models.ReplayStateCompleted {
+		t.Fatalf("replay did not complete: %+v, %v", status, err)
+	}
+
+	// Two utterances do not trigger a periodic pass; the final pass still analyses them
+	analysis := m.GetAnalystAgent(agentID).GetAnalysis()
+	if len(analysis.Transcript) != 2 || analysis.Transcript[1].Speaker != "Carol" {
+		t.Errorf("unexpected analysed transcript: %+v", analysis.Transcript)
+	}
+	if analysis.Summary != "The team approved the budget." {
+		t.Errorf("unexpected summary %q", analysis.Summary)
+	}
+}
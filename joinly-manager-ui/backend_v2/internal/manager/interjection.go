@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/interjection"
+	"joinly-manager/internal/metrics"
+	"joinly-manager/internal/models"
+)
+
+// decideInterjection applies an agent's interjection policy to an utterance and logs the
+// decision with its explanation. Agents without a policy answer every utterance.
+func (m *AgentManager) decideInterjection(ctx context.Context, agentID string, joinlyClient *client.JoinlyClient, policy *models.InterjectionConfig, speaker, text string, window models.ConversationWindow) interjection.Decision {
+	if policy == nil {
+		return interjection.Decision{Speak: true}
+	}
+
+	decision := m.evaluateInterjection(ctx, agentID, joinlyClient, policy, speaker, text, window)
+
+	if decision.Speak {
+		metrics.IncInterjectionDecisions("speak", string(decision.Rule))
+		m.addLogEntry(agentID, "info", fmt.Sprintf("💬 Speaking up (%s): %s", decision.Rule, decision.Reason))
+	} else {
+		metrics.IncInterjectionDecisions("silent", "")
+		m.addLogEntry(agentID, "info", fmt.Sprintf("🤐 Staying silent: %s", decision.Reason))
+	}
+	return decision
+}
+
+// evaluateInterjection checks the cooldown and rate limit, then the policy's rules from the
+// cheapest signal to the factual check, which needs an LLM call
+func (m *AgentManager) evaluateInterjection(ctx context.Context, agentID string, joinlyClient *client.JoinlyClient, policy *models.InterjectionConfig, speaker, text string, window models.ConversationWindow) interjection.Decision {
+	if reason := interjection.Throttled(policy, m.recentInterjections(agentID), time.Now()); reason != "" {
+		return interjection.Decision{Reason: reason}
+	}
+
+	signals := interjection.Signals{}
+	if interjection.RuleEnabled(policy, models.InterjectionDirectQuestion) {
+		signals.Question = interjection.IsDirectQuestion(text)
+	}
+	if interjection.RuleEnabled(policy, models.InterjectionActionItem) {
+		signals.ActionItem = interjection.RelatedActionItem(text, window.Memories)
+	}
+
+	if !signals.Question && signals.ActionItem == nil && interjection.RuleEnabled(policy, models.InterjectionFactualCorrection) {
+		correction, err := joinlyClient.CheckFactualError(ctx, speaker, text, window)
+		if err != nil {
+			m.addLogEntry(agentID, "warn", fmt.Sprintf("Factual check failed: %v", err))
+		} else {
+			signals.Checked = true
+			signals.Correction = correction
+		}
+	}
+
+	return interjection.Decide(policy, signals)
+}
+
+// recentInterjections returns when the agent spoke up within the rate window, oldest first
+func (m *AgentManager) recentInterjections(agentID string) []time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-interjection.RateWindow)
+	recent := m.interjections[agentID]
+	for len(recent) > 0 && recent[0].Before(cutoff) {
+		recent = recent[1:]
+	}
+	m.interjections[agentID] = recent
+
+	return append([]time.Time(nil), recent...)
+}
+
+// recordInterjection counts a reply towards the agent's cooldown and rate limit
+func (m *AgentManager) recordInterjection(agentID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.interjections[agentID] = append(m.interjections[agentID], time.Now())
+}
@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/tracing"
+)
+
+// retrieveKnowledge returns the passages of the agent's knowledge bases that best match an utterance
+func (m *AgentManager) retrieveKnowledge(ctx context.Context, agentID string, knowledgeBases []string, text string) []models.KnowledgePassage {
+	if len(knowledgeBases) == 0 || m.knowledge == nil {
+		return nil
+	}
+
+	_, endStage := tracing.StartStage(ctx, tracing.StageRetrieval,
+		attribute.Int("retrieval.knowledge_bases", len(knowledgeBases)),
+	)
+	passages := m.knowledge.Search(ctx, knowledgeBases, text, m.config.Knowledge.TopK)
+	endStage(nil)
+
+	if len(passages) > 0 {
+		m.addLogEntry(agentID, "debug", fmt.Sprintf("📚 Retrieved %d knowledge base passages", len(passages)))
+	}
+	return passages
+}
+
+// ListKnowledgeBases returns every knowledge base
+func (m *AgentManager) ListKnowledgeBases() []models.KnowledgeBaseInfo {
+	return m.knowledge.Bases()
+}
+
+// ListKnowledgeDocuments returns the documents of a knowledge base
+func (m *AgentManager) ListKnowledgeDocuments(name string) ([]models.KnowledgeDocument, error) {
+	return m.knowledge.Documents(name)
+}
+
+// GetKnowledgeDocument returns a document and the chunks it was indexed as
+func (m *AgentManager) GetKnowledgeDocument(name, docID string) (models.KnowledgeDocument, []models.KnowledgeChunk, error) {
+	return m.knowledge.Document(name, docID)
+}
+
+// AddKnowledgeDocument indexes a document in a knowledge base, creating the knowledge base if needed
+func (m *AgentManager) AddKnowledgeDocument(ctx context.Context, name, title, source, format, content string) (models.KnowledgeDocument, error) {
+	return m.knowledge.AddDocument(ctx, name, title, source, format, content)
+}
+
+// DeleteKnowledgeDocument removes a document from a knowledge base
+func (m *AgentManager) DeleteKnowledgeDocument(name, docID string) error {
+	return m.knowledge.DeleteDocument(name, docID)
+}
+
+// DeleteKnowledgeBase removes a knowledge base and all its documents
+func (m *AgentManager) DeleteKnowledgeBase(name string) error {
+	return m.knowledge.DeleteBase(name)
+}
+
+// SearchKnowledge returns the passages of a knowledge base that best match a query
+func (m *AgentManager) SearchKnowledge(ctx context.Context, name, query string, k int) ([]models.KnowledgePassage, error) {
+	if _, err := m.knowledge.Documents(name); err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		k = m.config.Knowledge.TopK
+	}
+	return m.knowledge.Search(ctx, []string{name}, query, k), nil
+}
@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/tracing"
+)
+
+// maxLatencySamples bounds the latency history kept per agent
+const maxLatencySamples = 500
+
+// recordLatency stores the latency breakdown of a finished utterance
+func (m *AgentManager) recordLatency(agentID string, latency models.UtteranceLatency) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.agents[agentID]; !exists {
+		return
+	}
+
+	samples := append(m.latencies[agentID], latency)
+	if len(samples) > maxLatencySamples {
+		samples = samples[len(samples)-maxLatencySamples:]
+	}
+	m.latencies[agentID] = samples
+}
+
+// GetAgentLatency returns per-stage latency percentiles over an agent's recent utterances
+func (m *AgentManager) GetAgentLatency(agentID string, recent int) (*models.AgentLatencyReport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.agents[agentID]; !exists {
+		return nil, fmt.Errorf("agent not found")
+	}
+
+	samples := m.latencies[agentID]
+	report := &models.AgentLatencyReport{
+		AgentID: agentID,
+		Samples: len(samples),
+		Stages:  latencyStages(samples),
+		Recent:  []models.UtteranceLatency{},
+	}
+
+	if recent > len(samples) {
+		recent = len(samples)
+	}
+	if recent > 0 {
+		report.Recent = append(report.Recent, samples[len(samples)-recent:]...)
+	}
+
+	return report, nil
+}
+
+// latencyStages computes the percentiles of each stage. A stage only counts samples
+// that reached it, so cancelled or silent utterances do not skew LLM and speak times.
+func latencyStages(samples []models.UtteranceLatency) map[string]models.LatencyPercentiles {
+	values := map[string][]float64{}
+	for _, sample := range samples {
+		values[tracing.StageDebounce] = append(values[tracing.StageDebounce], sample.DebounceMs)
+		if sample.LLMMs > 0 {
+			values[tracing.StageQueue] = append(values[tracing.StageQueue], sample.QueueMs)
+			values[tracing.StageLLM] = append(values[tracing.StageLLM], sample.LLMMs)
+		}
+		if sample.SpeakMs > 0 {
+			values[tracing.StageSpeak] = append(values[tracing.StageSpeak], sample.SpeakMs)
+		}
+		// End-to-end latency is only meaningful for replies that were spoken
+		if sample.Outcome == "delivered" {
+			values[tracing.StageTotal] = append(values[tracing.StageTotal], sample.TotalMs)
+		}
+	}
+
+	stages := make(map[string]models.LatencyPercentiles, len(values))
+	for stage, stageValues := range values {
+		stages[stage] = percentiles(stageValues)
+	}
+	return stages
+}
+
+// percentiles summarises a set of millisecond values using the nearest-rank method
+func percentiles(values []float64) models.LatencyPercentiles {
+	if len(values) == 0 {
+		return models.LatencyPercentiles{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := func(p float64) float64 {
+		index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		if index < 0 {
+			index = 0
+		}
+		return sorted[index]
+	}
+
+	return models.LatencyPercentiles{
+		Count: len(sorted),
+		P50:   rank(50),
+		P90:   rank(90),
+		P95:   rank(95),
+		P99:   rank(99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
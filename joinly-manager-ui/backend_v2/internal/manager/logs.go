@@ -7,10 +7,13 @@ import (
 	"joinly-manager/internal/models"
 )
 
+// agentLogLimit is how many recent log entries are returned with an agent
+const agentLogLimit = 100
+
 // GetAgentLogs gets logs for an agent with pagination support
 func (m *AgentManager) GetAgentLogs(agentID string, lines int) ([]models.LogEntry, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.logMu.Lock()
+	defer m.logMu.Unlock()
 
 	logs, exists := m.logBuffers[agentID]
 	if !exists {
@@ -45,20 +48,26 @@ func (m *AgentManager) GetAgentLogs(agentID string, lines int) ([]models.LogEntr
 
 // addLogEntry adds a log entry for an agent
 func (m *AgentManager) addLogEntry(agentID, level, message string) {
-	entry := models.LogEntry{
+	m.appendLog(agentID, models.LogEntry{
 		Timestamp: time.Now(),
 		Level:     level,
 		Message:   message,
-	}
-
-	m.addLogEntryUnsafe(agentID, entry)
+	})
 
 	// Note: Logs are now fetched via polling API, not WebSocket to avoid conflicts
 }
 
-// addLogEntryUnsafe adds a log entry without acquiring mutex (caller must hold mutex)
-func (m *AgentManager) addLogEntryUnsafe(agentID string, entry models.LogEntry) {
-	logs := m.logBuffers[agentID]
+// appendLog adds a log entry to an agent's log buffer. It only takes logMu, so it may be
+// called with or without m.mu held, e.g. from client callbacks. Entries for deleted agents are
+// dropped.
+func (m *AgentManager) appendLog(agentID string, entry models.LogEntry) {
+	m.logMu.Lock()
+	defer m.logMu.Unlock()
+
+	logs, exists := m.logBuffers[agentID]
+	if !exists {
+		return
+	}
 	logs = append(logs, entry)
 
 	// Keep only the last logBufferSize entries
@@ -67,14 +76,20 @@ func (m *AgentManager) addLogEntryUnsafe(agentID string, entry models.LogEntry)
 	}
 
 	m.logBuffers[agentID] = logs
+}
+
+// agentCopyUnsafe returns a copy of an agent with its recent log entries, safe to hand out
+// (caller must hold m.mu)
+func (m *AgentManager) agentCopyUnsafe(agent *models.Agent) *models.Agent {
+	agentCopy := *agent
 
-	// Also update the agent logs (keep last 100)
-	agent := m.agents[agentID]
-	if agent != nil {
-		agent.Logs = append(agent.Logs, entry)
-		if len(agent.Logs) > 100 {
-			agent.Logs = agent.Logs[len(agent.Logs)-100:]
-		}
+	m.logMu.Lock()
+	logs := m.logBuffers[agent.ID]
+	if len(logs) > agentLogLimit {
+		logs = logs[len(logs)-agentLogLimit:]
 	}
-}
+	agentCopy.Logs = append([]models.LogEntry{}, logs...)
+	m.logMu.Unlock()
 
+	return &agentCopy
+}
@@ -8,9 +8,18 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"joinly-manager/internal/archive"
+	"joinly-manager/internal/audit"
+	"joinly-manager/internal/billing"
 	"joinly-manager/internal/client"
+	"joinly-manager/internal/client/llm"
 	"joinly-manager/internal/config"
+	"joinly-manager/internal/knowledge"
+	"joinly-manager/internal/memory"
+	"joinly-manager/internal/metrics"
 	"joinly-manager/internal/models"
+	"joinly-manager/internal/search"
+	"joinly-manager/internal/storage"
 	"joinly-manager/internal/websocket"
 )
 
@@ -21,6 +30,7 @@ type AgentManager struct {
 	agents              map[string]*models.Agent
 	meetings            map[string]*models.MeetingInfo
 	analysts            map[string]*client.AnalystAgent // Analyst agents for analysis mode
+	backends            *BackendPool                    // Pool of joinly MCP servers leased to running agents
 	wsHub               *websocket.Hub
 	running             bool
 	startTime           time.Time
@@ -29,22 +39,84 @@ type AgentManager struct {
 	cancel              context.CancelFunc
 	wg                  sync.WaitGroup
 	agentContexts       map[string]context.CancelFunc
+	logMu               sync.Mutex // Guards logBuffers; taken after mu, never before
 	logBuffers          map[string][]models.LogEntry
 	logBufferSize       int
 	utteranceTasks      map[string]context.CancelFunc // Track active utterance processing tasks
 	conversationHistory map[string][]models.ConversationEntry
+	summaries           map[string]string                    // Rolling summary of turns folded out of the history
+	compacting          map[string]bool                      // Agents whose history is being summarised
+	latencies           map[string][]models.UtteranceLatency // Recent utterance latency breakdowns per agent
+	ledger              *billing.Ledger                      // LLM cost per agent, meeting and tenant
+	memory              *memory.Store                        // Long-term memory of agent identities
+	remembered          map[string]time.Time                 // Newest turn already handed to memory extraction
+	knowledge           *knowledge.Store                     // Documents conversational agents answer from
+	interjections       map[string][]time.Time               // When agents with an interjection policy last spoke up
+	replays             map[string]*replaySession            // Latest transcript replay per agent
+	transcripts         map[string][]models.MeetingSegment   // Transcript of each stopped agent's last meeting
+	search              *search.Index                        // Full-text index over saved meeting analyses
+	vault               *storage.Vault                       // Encryption of everything persisted
+	audit               *audit.Log                           // Append-only trail of mutating API actions
+	vaultErr            error                                // Why the encryption keys could not be opened
+	archive             *archive.Store                       // Durable records of finished meeting sessions
+	sessions            map[string]*meetingSession           // Meetings with running agents, by meeting URL
 }
 
 // NewAgentManager creates a new agent manager
 func NewAgentManager(cfg *config.Config) *AgentManager {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Fall back to the single default joinly server when no pool is configured
+	backendURLs := cfg.Joinly.BackendURLs
+	if len(backendURLs) == 0 {
+		backendURLs = []string{cfg.Joinly.DefaultURL}
+	}
+
+	prices, err := billing.LoadPrices(cfg.Billing.PriceFile)
+	if err != nil {
+		logrus.Errorf("Failed to load LLM prices, using defaults: %v", err)
+		prices = billing.DefaultPrices()
+	}
+
+	// Without its keys the manager must not fall back to plaintext; Start reports the error
+	vault, vaultErr := openVault(cfg.Storage)
+	client.ConfigureAnalysisStorage(cfg.Storage.AnalysisDir(), vault)
+	llm.ConfigureRecordingVault(vault)
+
+	memoryStore, err := memory.NewStore(cfg.Memory.Dir, vault)
+	if err != nil {
+		logrus.Errorf("Failed to load agent memory: %v", err)
+	}
+
+	// Semantic search is optional; without an embedding model passages are ranked by BM25
+	var embed knowledge.Embedder
+	if model := cfg.Knowledge.EmbeddingModel; model != "" {
+		embed = func(ctx context.Context, text string) ([]float64, error) {
+			return llm.OllamaEmbed(ctx, model, text)
+		}
+	}
+	knowledgeStore, err := knowledge.NewStore(cfg.Knowledge.Dir, embed, vault)
+	if err != nil {
+		logrus.Errorf("Failed to load knowledge bases: %v", err)
+	}
+
+	archiveStore, err := archive.NewStore(cfg.Archive.Dir, vault)
+	if err != nil {
+		logrus.Errorf("Failed to load meeting archive: %v", err)
+	}
+
+	auditLog, err := audit.Open(cfg.Audit.Dir, vault)
+	if err != nil {
+		logrus.Errorf("Failed to load audit log: %v", err)
+	}
+
 	return &AgentManager{
 		config:              cfg,
 		clients:             make(map[string]*client.JoinlyClient),
 		agents:              make(map[string]*models.Agent),
 		meetings:            make(map[string]*models.MeetingInfo),
 		analysts:            make(map[string]*client.AnalystAgent),
+		backends:            NewBackendPool(backendURLs, cfg.Joinly.HealthCheckInterval),
 		wsHub:               websocket.NewHub(),
 		running:             false,
 		startTime:           time.Now(),
@@ -55,7 +127,45 @@ func NewAgentManager(cfg *config.Config) *AgentManager {
 		logBufferSize:       1000,
 		utteranceTasks:      make(map[string]context.CancelFunc),
 		conversationHistory: make(map[string][]models.ConversationEntry),
+		summaries:           make(map[string]string),
+		compacting:          make(map[string]bool),
+		latencies:           make(map[string][]models.UtteranceLatency),
+		ledger:              billing.NewLedger(prices),
+		memory:              memoryStore,
+		remembered:          make(map[string]time.Time),
+		knowledge:           knowledgeStore,
+		interjections:       make(map[string][]time.Time),
+		replays:             make(map[string]*replaySession),
+		transcripts:         make(map[string][]models.MeetingSegment),
+		search:              search.NewIndex(cfg.Storage.AnalysisDir(), vault),
+		archive:             archiveStore,
+		sessions:            make(map[string]*meetingSession),
+		vault:               vault,
+		audit:               auditLog,
+		vaultErr:            vaultErr,
+	}
+}
+
+// openVault opens the configured encryption key provider. Data is stored in plaintext when no
+// provider is configured.
+func openVault(cfg config.StorageConfig) (*storage.Vault, error) {
+	keys, err := storage.NewKeyProvider(cfg.Encryption, storage.ProviderSettings{KeyFile: cfg.KeyFile, KeyURI: cfg.KeyURI})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open encryption keys: %w", err)
+	}
+	vault := storage.NewVault(keys)
+	if cfg.DataDir != "" {
+		if err := vault.MkdirAll(cfg.DataDir); err != nil {
+			logrus.Errorf("Failed to create data directory: %v", err)
+		}
+	}
+
+	if keys == nil {
+		logrus.Warn("Encryption at rest is disabled; meeting data is stored in plaintext")
+	} else {
+		logrus.Infof("Encrypting meeting data at rest with the %s key provider", cfg.Encryption)
 	}
+	return vault, nil
 }
 
 // Start starts the agent manager
@@ -66,6 +176,9 @@ func (m *AgentManager) Start() error {
 	if m.running {
 		return fmt.Errorf("agent manager already running")
 	}
+	if m.vaultErr != nil {
+		return m.vaultErr
+	}
 
 	logrus.Info("Starting agent manager")
 	m.running = true
@@ -74,6 +187,15 @@ func (m *AgentManager) Start() error {
 	// Start WebSocket hub
 	m.wsHub.Start()
 
+	// Start backend pool health checks
+	go m.backends.Run(m.ctx)
+
+	// Delete archived meetings past retention
+	go m.runArchiveRetention(m.ctx)
+
+	// Expose agent, meeting and WebSocket gauges on /metrics
+	metrics.SetStateFunc(m.metricsState)
+
 	logrus.Info("Agent manager started successfully")
 	return nil
 }
@@ -89,6 +211,9 @@ func (m *AgentManager) Stop() error {
 
 	logrus.Info("Stopping agent manager")
 	m.running = false
+
+	// Archive the meetings agents are still in
+	m.closeSessionsUnsafe()
 	m.cancel()
 
 	// Cancel all active utterance processing tasks
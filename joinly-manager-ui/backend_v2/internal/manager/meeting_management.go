@@ -2,6 +2,7 @@ package manager
 
 import (
 	"fmt"
+	"time"
 
 	"joinly-manager/internal/models"
 )
@@ -46,9 +47,67 @@ func (m *AgentManager) JoinMeeting(agentID string) error {
 			m.addLogEntry(agentID, "error", fmt.Sprintf("Failed to join meeting: %v", err))
 		} else {
 			m.addLogEntry(agentID, "info", "Successfully joined meeting")
+			m.discloseRecording(agentID, client)
 		}
 	}()
 
 	return nil
 }
 
+// addAgentToMeetingUnsafe registers an agent with a meeting entry (caller must hold lock)
+func (m *AgentManager) addAgentToMeetingUnsafe(meetingURL, agentID string) {
+	if m.meetings[meetingURL] == nil {
+		m.meetings[meetingURL] = &models.MeetingInfo{
+			URL:       meetingURL,
+			CreatedAt: time.Now(),
+		}
+	}
+	m.meetings[meetingURL].AgentIDs = append(m.meetings[meetingURL].AgentIDs, agentID)
+	m.meetings[meetingURL].AgentCount++
+}
+
+// removeAgentFromMeetingUnsafe removes an agent from a meeting entry (caller must hold lock)
+func (m *AgentManager) removeAgentFromMeetingUnsafe(meetingURL, agentID string) {
+	meeting := m.meetings[meetingURL]
+	if meeting == nil {
+		return
+	}
+
+	// Remove agent ID from meeting
+	for i, id := range meeting.AgentIDs {
+		if id == agentID {
+			meeting.AgentIDs = append(meeting.AgentIDs[:i], meeting.AgentIDs[i+1:]...)
+			break
+		}
+	}
+	meeting.AgentCount--
+
+	// Remove meeting if no agents left
+	if meeting.AgentCount == 0 {
+		delete(m.meetings, meetingURL)
+	}
+}
+
+// listedMeetingUnsafe returns the URL of the meeting entry an agent is listed under (caller
+// must hold lock)
+func (m *AgentManager) listedMeetingUnsafe(agentID string) string {
+	for url, meeting := range m.meetings {
+		for _, id := range meeting.AgentIDs {
+			if id == agentID {
+				return url
+			}
+		}
+	}
+	return ""
+}
+
+// syncAgentMeetingUnsafe lists an agent under its configured meeting URL. A running agent stays
+// listed under the meeting it joined until it is stopped or restarted (caller must hold lock).
+func (m *AgentManager) syncAgentMeetingUnsafe(agentID string, agent *models.Agent) {
+	listed := m.listedMeetingUnsafe(agentID)
+	if listed == agent.Config.MeetingURL {
+		return
+	}
+	m.removeAgentFromMeetingUnsafe(listed, agentID)
+	m.addAgentToMeetingUnsafe(agent.Config.MeetingURL, agentID)
+}
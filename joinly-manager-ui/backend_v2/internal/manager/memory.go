@@ -0,0 +1,186 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/memory"
+	"joinly-manager/internal/models"
+)
+
+const (
+	// memoryRecallLimit caps the memory items added to each reply request
+	memoryRecallLimit = 20
+	// memoryExtractionTimeout bounds the LLM call that extracts a finished meeting's memories
+	memoryExtractionTimeout = 2 * time.Minute
+)
+
+// meetingSeries returns the key that groups an agent's recurring meetings
+func meetingSeries(config models.AgentConfig) string {
+	if config.MeetingSeries != "" {
+		return config.MeetingSeries
+	}
+	return config.MeetingURL
+}
+
+// participantsUnsafe returns the distinct human speakers in an agent's history (caller must hold lock)
+func (m *AgentManager) participantsUnsafe(agentID string) []string {
+	var participants []string
+	seen := make(map[string]bool)
+	for _, entry := range m.conversationHistory[agentID] {
+		key := strings.ToLower(entry.Speaker)
+		if entry.IsAgent || entry.Speaker == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		participants = append(participants, entry.Speaker)
+	}
+	return participants
+}
+
+// recallMemoriesUnsafe returns what the agent's identity remembers about the current meeting
+// and its participants (caller must hold lock)
+func (m *AgentManager) recallMemoriesUnsafe(agentID string, config models.AgentConfig, speaker string) []models.MemoryItem {
+	if config.Identity == "" || m.memory == nil {
+		return nil
+	}
+	participants := append(m.participantsUnsafe(agentID), speaker)
+	return m.memory.Recall(config.Identity, meetingSeries(config), participants, memoryRecallLimit)
+}
+
+// rememberMeetingUnsafe hands the turns since the last extraction to memory extraction, which
+// runs in the background (caller must hold lock). It is called when an agent leaves its meeting;
+// a silenced agent makes no extraction call.
+func (m *AgentManager) rememberMeetingUnsafe(agentID string, joinlyClient *client.JoinlyClient) {
+	agent, exists := m.agents[agentID]
+	if !exists || agent.Config.Identity == "" || m.memory == nil || joinlyClient == nil || isSilencedUnsafe(agent) {
+		return
+	}
+
+	var turns []models.ConversationEntry
+	hasParticipant := false
+	for _, entry := range m.conversationHistory[agentID] {
+		if !entry.Timestamp.After(m.remembered[agentID]) {
+			continue
+		}
+		turns = append(turns, entry)
+		hasParticipant = hasParticipant || !entry.IsAgent
+	}
+	if !hasParticipant {
+		return
+	}
+	m.remembered[agentID] = turns[len(turns)-1].Timestamp
+
+	go m.extractMemories(agentID, joinlyClient, agent.Config, m.summaries[agentID], turns, m.participantsUnsafe(agentID))
+}
+
+// extractMemories stores the facts, decisions and action items of a finished meeting under
+// the agent's identity and closes the action items the meeting reported as done
+func (m *AgentManager) extractMemories(agentID string, joinlyClient *client.JoinlyClient, config models.AgentConfig, summary string, turns []models.ConversationEntry, participants []string) {
+	ctx, cancel := context.WithTimeout(m.ctx, memoryExtractionTimeout)
+	defer cancel()
+
+	series := meetingSeries(config)
+	known := m.memory.Recall(config.Identity, series, participants, 0)
+
+	extraction, err := joinlyClient.ExtractMemories(ctx, summary, turns, known)
+	if err != nil {
+		logrus.Warnf("Failed to extract memories of agent %s: %v", agentID, err)
+		m.logIfExists(agentID, "warn", fmt.Sprintf("Failed to update long-term memory: %v", err))
+		return
+	}
+
+	// Without raw persistence, memories keep no names; they are still recalled by meeting series
+	redactor := joinlyClient.Redactor()
+	if !redactor.PersistsRaw() {
+		participants = nil
+	}
+
+	added := 0
+	for _, item := range extraction.Items {
+		item.Content = redactor.ForStorage(item.Content)
+		item.Owner = redactor.ForStorage(item.Owner)
+		item.Series = series
+		item.MeetingURL = config.MeetingURL
+		item.Participants = participants
+		item.Source = memory.SourceExtracted
+		if _, err := m.memory.Add(config.Identity, item); err != nil {
+			logrus.Debugf("Skipped extracted memory of agent %s: %v", agentID, err)
+			continue
+		}
+		added++
+	}
+
+	completed := 0
+	done := true
+	for _, itemID := range extraction.Completed {
+		if _, err := m.memory.Update(config.Identity, itemID, models.MemoryItemUpdate{Done: &done}); err == nil {
+			completed++
+		}
+	}
+
+	logrus.Infof("Updated memory of identity %s from agent %s: %d items added, %d action items completed", config.Identity, agentID, added, completed)
+	m.logIfExists(agentID, "info", fmt.Sprintf("🧠 Remembered %d items for identity %s (%d action items completed)", added, config.Identity, completed))
+}
+
+// logIfExists adds a log entry for an agent that may have been deleted meanwhile
+func (m *AgentManager) logIfExists(agentID, level, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.agents[agentID]; exists {
+		m.addLogEntry(agentID, level, message)
+	}
+}
+
+// ListMemoryIdentities returns every identity with stored memory
+func (m *AgentManager) ListMemoryIdentities() []models.MemoryIdentity {
+	return m.memory.Identities()
+}
+
+// GetMemory returns what an identity remembers, optionally filtered by kind and series
+func (m *AgentManager) GetMemory(identity string, kind models.MemoryKind, series string) ([]models.MemoryItem, error) {
+	items, err := m.memory.List(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := items[:0]
+	for _, item := range items {
+		if (kind == "" || item.Kind == kind) && (series == "" || item.Series == series) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
+
+// AddMemoryItem stores a manually written memory item for an identity
+func (m *AgentManager) AddMemoryItem(identity string, item models.MemoryItem) (models.MemoryItem, error) {
+	item.Source = memory.SourceManual
+	return m.memory.Add(identity, item)
+}
+
+// UpdateMemoryItem edits one memory item
+func (m *AgentManager) UpdateMemoryItem(identity, itemID string, update models.MemoryItemUpdate) (models.MemoryItem, error) {
+	return m.memory.Update(identity, itemID, update)
+}
+
+// DeleteMemoryItem forgets one memory item
+func (m *AgentManager) DeleteMemoryItem(identity, itemID string) error {
+	return m.memory.Delete(identity, itemID)
+}
+
+// PurgeMemory forgets everything an identity remembers
+func (m *AgentManager) PurgeMemory(identity string) (int, error) {
+	return m.memory.Purge(identity)
+}
+
+// PurgeParticipantMemory forgets every memory item, across all identities, involving a participant
+func (m *AgentManager) PurgeParticipantMemory(participant string) (int, error) {
+	return m.memory.PurgeParticipant(participant)
+}
@@ -0,0 +1,307 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/client"
+	"joinly-manager/internal/joinlytest"
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/replay"
+)
+
+const (
+	// maxReplaySpeed bounds how much a replay can be accelerated
+	maxReplaySpeed = 100
+	// replayTurnMargin is added to the end-of-turn delay between turns, so that a turn is
+	// consolidated before the next one starts even at high speeds
+	replayTurnMargin = 500 * time.Millisecond
+	// replayStartTimeout bounds how long the agent may take to start and join the replayed meeting
+	replayStartTimeout = 30 * time.Second
+	// replayDrainTimeout bounds how long the last utterance may take to be processed
+	replayDrainTimeout = 2 * time.Minute
+)
+
+// replaySession is a transcript replay running or finished for an agent
+type replaySession struct {
+	status models.ReplayStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartReplay plays a recorded transcript into a stopped agent. The agent runs against an
+// in-process joinly server that speaks the participant segments at the given speed, so its
+// utterance pipeline, replies and analysis behave as in the recorded meeting.
+func (m *AgentManager) StartReplay(agentID, source string, segments []replay.Segment, speed float64) (*models.ReplayStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, exists := m.agents[agentID]
+	if !exists {
+		return nil, fmt.Errorf("agent not found")
+	}
+	if speed <= 0 || speed > maxReplaySpeed {
+		return nil, fmt.Errorf("invalid replay speed: must be above 0 and at most %d", maxReplaySpeed)
+	}
+	if session := m.replays[agentID]; session != nil && session.status.State == models.ReplayStateRunning {
+		return nil, fmt.Errorf("replay already running")
+	}
+	if agent.Status == models.AgentStatusRunning || agent.Status == models.AgentStatusStarting {
+		return nil, fmt.Errorf("agent is running")
+	}
+
+	participants := 0
+	for _, segment := range segments {
+		if !segment.Assistant {
+			participants++
+		}
+	}
+	if participants == 0 {
+		return nil, fmt.Errorf("transcript has no participant segments")
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	session := &replaySession{
+		status: models.ReplayStatus{
+			AgentID:   agentID,
+			State:     models.ReplayStateRunning,
+			Source:    source,
+			Speed:     speed,
+			Segments:  len(segments),
+			Replies:   []string{},
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.replays[agentID] = session
+
+	m.addLogEntry(agentID, "info", fmt.Sprintf("Replaying %d transcript segments at %gx speed", len(segments), speed))
+
+	m.wg.Add(1)
+	go m.runReplay(ctx, agentID, session, segments)
+
+	status := session.status
+	return &status, nil
+}
+
+// GetReplay returns the status of an agent's latest replay
+func (m *AgentManager) GetReplay(agentID string) (*models.ReplayStatus, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.agents[agentID]; !exists {
+		return nil, fmt.Errorf("agent not found")
+	}
+	session := m.replays[agentID]
+	if session == nil {
+		return nil, fmt.Errorf("no replay found")
+	}
+	return session.statusUnsafe(), nil
+}
+
+// WaitReplay waits until an agent's latest replay finished and returns its status
+func (m *AgentManager) WaitReplay(ctx context.Context, agentID string) (*models.ReplayStatus, error) {
+	m.mu.RLock()
+	session := m.replays[agentID]
+	m.mu.RUnlock()
+
+	if session == nil {
+		return nil, fmt.Errorf("no replay found")
+	}
+
+	select {
+	case <-session.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return m.GetReplay(agentID)
+}
+
+// statusUnsafe returns a copy of the session status (caller must hold lock)
+func (s *replaySession) statusUnsafe() *models.ReplayStatus {
+	status := s.status
+	status.Replies = append([]string{}, s.status.Replies...)
+	return &status
+}
+
+// runReplay plays the transcript, then stops the agent and records the outcome
+func (m *AgentManager) runReplay(ctx context.Context, agentID string, session *replaySession, segments []replay.Segment) {
+	defer m.wg.Done()
+	defer close(session.done)
+
+	joinly := joinlytest.NewServer()
+	err := m.replayMeeting(ctx, agentID, joinly, session, segments)
+
+	m.mu.Lock()
+	session.cancel()
+
+	now := time.Now()
+	session.status.Replies = joinly.Spoken()
+	session.status.FinishedAt = &now
+	level, message := "info", ""
+	switch {
+	case err == nil:
+		session.status.State = models.ReplayStateCompleted
+		message = fmt.Sprintf("Replay finished: %d segments replayed, %d replies", session.status.Replayed, len(session.status.Replies))
+	case errors.Is(err, context.Canceled):
+		session.status.State = models.ReplayStateCancelled
+		level, message = "warn", "Replay cancelled"
+	default:
+		session.status.State = models.ReplayStateFailed
+		session.status.Error = err.Error()
+		level, message = "error", fmt.Sprintf("Replay failed: %v", err)
+	}
+
+	// The agent may have been deleted during the replay
+	if _, exists := m.agents[agentID]; exists {
+		if stopErr := m.stopAgent(agentID); stopErr != nil {
+			logrus.Errorf("Failed to stop agent %s after replay: %v", agentID, stopErr)
+		}
+		m.addLogEntry(agentID, level, message)
+	}
+	m.mu.Unlock()
+
+	joinly.Close()
+}
+
+// replayMeeting starts the agent against the fake joinly server and says the participant
+// segments, paced like the recording but keeping its turn boundaries
+func (m *AgentManager) replayMeeting(ctx context.Context, agentID string, joinly *joinlytest.Server, session *replaySession, segments []replay.Segment) error {
+	m.mu.Lock()
+	err := m.startAgentUnsafe(agentID, joinly.URL)
+	var config models.AgentConfig
+	if agent := m.agents[agentID]; agent != nil {
+		config = agent.Config
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	started := func() bool {
+		status, _ := m.replayAgentState(agentID)
+		return status == models.AgentStatusRunning || status == models.AgentStatusError
+	}
+	if err := m.waitForReplay(ctx, replayStartTimeout, started); err != nil {
+		return fmt.Errorf("agent did not start: %w", err)
+	}
+	if status, _ := m.replayAgentState(agentID); status == models.AgentStatusError {
+		return fmt.Errorf("agent failed to start")
+	}
+	if !config.AutoJoin {
+		if err := m.JoinMeeting(agentID); err != nil {
+			return err
+		}
+	}
+	joined := func() bool {
+		_, serverJoined := joinly.Joined()
+		_, clientJoined := m.replayAgentState(agentID)
+		return serverJoined && clientJoined
+	}
+	if err := m.waitForReplay(ctx, replayStartTimeout, joined); err != nil {
+		return fmt.Errorf("agent did not join the meeting: %w", err)
+	}
+
+	turnGap := func(text string) time.Duration {
+		return client.EndOfTurnDelay(config.TurnTaking, text) + client.TranscriptPollInterval + replayTurnMargin
+	}
+
+	var prev *replay.Segment
+	for i := range segments {
+		segment := segments[i]
+		if segment.Assistant {
+			m.mu.Lock()
+			session.status.Skipped++
+			m.mu.Unlock()
+			continue
+		}
+
+		if prev != nil {
+			if err := sleepContext(ctx, replay.Delay(*prev, segment, session.status.Speed, turnGap(prev.Text))); err != nil {
+				return err
+			}
+		}
+
+		speaker := segment.Speaker
+		if speaker == "" {
+			speaker = "Participant"
+		}
+		joinly.Say(speaker, segment.Text)
+		prev = &segments[i]
+
+		m.mu.Lock()
+		session.status.Replayed++
+		session.status.Replies = joinly.Spoken()
+		m.mu.Unlock()
+	}
+
+	// Let the last turn end and its reply or analysis finish
+	if err := sleepContext(ctx, turnGap(prev.Text)); err != nil {
+		return err
+	}
+	idle := func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		_, busy := m.utteranceTasks[agentID]
+		return !busy
+	}
+	if err := m.waitForReplay(ctx, replayDrainTimeout, idle); err != nil {
+		return fmt.Errorf("last utterance was not processed: %w", err)
+	}
+
+	// A meeting ending between periodic passes still gets a complete analysis
+	if analyst := m.GetAnalystAgent(agentID); analyst != nil {
+		analyst.AnalyzeNow()
+	}
+	return nil
+}
+
+// replayAgentState returns the status of an agent and whether its client joined the meeting
+func (m *AgentManager) replayAgentState(agentID string) (models.AgentStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	agent := m.agents[agentID]
+	if agent == nil {
+		return "", false
+	}
+	client := m.clients[agentID]
+	return agent.Status, client != nil && client.IsJoined()
+}
+
+// waitForReplay polls until condition holds, the timeout passes or the replay is cancelled
+func (m *AgentManager) waitForReplay(ctx context.Context, timeout time.Duration, condition func() bool) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !condition() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("timed out after %s", timeout)
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// sleepContext waits for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
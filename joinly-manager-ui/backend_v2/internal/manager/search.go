@@ -0,0 +1,11 @@
+package manager
+
+import (
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/search"
+)
+
+// SearchMeetings searches the transcripts, summaries and action items of saved meeting analyses
+func (m *AgentManager) SearchMeetings(query search.Query) ([]models.SearchResult, int, error) {
+	return m.search.Search(query)
+}
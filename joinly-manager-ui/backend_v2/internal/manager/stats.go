@@ -3,6 +3,7 @@ package manager
 import (
 	"time"
 
+	"joinly-manager/internal/metrics"
 	"joinly-manager/internal/models"
 )
 
@@ -23,7 +24,32 @@ func (m *AgentManager) GetUsageStats() *models.UsageStats {
 		ActiveAgents:  activeAgents,
 		TotalMeetings: len(m.meetings),
 		UptimeSeconds: time.Since(m.startTime).Seconds(),
-		APICalls:      make(map[string]int), // TODO: Implement API call tracking
+		APICalls:      metrics.APICalls(),
+		BackendPool:   m.backends.Usage(),
+		Costs:         m.ledger.Usage(),
 	}
 }
 
+// metricsState snapshots the manager state for the Prometheus gauges
+func (m *AgentManager) metricsState() metrics.State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byStatus := map[string]int{
+		string(models.AgentStatusCreated):  0,
+		string(models.AgentStatusStarting): 0,
+		string(models.AgentStatusRunning):  0,
+		string(models.AgentStatusStopping): 0,
+		string(models.AgentStatusStopped):  0,
+		string(models.AgentStatusError):    0,
+	}
+	for _, agent := range m.agents {
+		byStatus[string(agent.Status)]++
+	}
+
+	return metrics.State{
+		AgentsByStatus:   byStatus,
+		Meetings:         len(m.meetings),
+		WebSocketClients: m.wsHub.GetClientCount(),
+	}
+}
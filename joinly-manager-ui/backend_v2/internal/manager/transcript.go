@@ -0,0 +1,24 @@
+package manager
+
+import (
+	"fmt"
+
+	"joinly-manager/internal/models"
+)
+
+// GetAgentTranscript returns the transcript of an agent's current meeting, or of its last
+// meeting when it is stopped
+func (m *AgentManager) GetAgentTranscript(agentID string) ([]models.MeetingSegment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.agents[agentID]; !exists {
+		return nil, fmt.Errorf("agent not found")
+	}
+
+	if client := m.clients[agentID]; client != nil {
+		return client.MeetingTranscript(), nil
+	}
+	segments := append([]models.MeetingSegment{}, m.transcripts[agentID]...)
+	return segments, nil
+}
@@ -46,7 +46,7 @@ func (m *AgentManager) handleAgentErrorUnsafe(agentID string, err error) {
 	// Update status while holding lock to avoid deadlock
 	agent.Status = models.AgentStatusError
 
-	m.addLogEntryUnsafe(agentID, models.LogEntry{
+	m.appendLog(agentID, models.LogEntry{
 		Timestamp: time.Now(),
 		Level:     "error",
 		Message:   fmt.Sprintf("Agent error: %s", errorMsg),
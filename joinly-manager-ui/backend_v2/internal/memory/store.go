@@ -0,0 +1,453 @@
+// Package memory persists what agent identities remember across meetings: facts, decisions
+// and action items extracted when a meeting ends. Each identity is stored as one JSON file.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/storage"
+)
+
+const (
+	// maxItemsPerIdentity caps an identity's memory; the oldest items are forgotten first
+	maxItemsPerIdentity = 500
+	// maxContentLength caps the length of a single memory item
+	maxContentLength = 1000
+)
+
+// Sources of memory items
+const (
+	SourceExtracted = "extracted"
+	SourceManual    = "manual"
+)
+
+// identityPattern restricts identities to names that are safe as file names
+var identityPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidIdentity reports whether identity can be used as an agent identity
+func ValidIdentity(identity string) bool {
+	return identityPattern.MatchString(identity)
+}
+
+// ValidKind reports whether kind is a known memory kind
+func ValidKind(kind models.MemoryKind) bool {
+	switch kind {
+	case models.MemoryKindFact, models.MemoryKindDecision, models.MemoryKindActionItem:
+		return true
+	}
+	return false
+}
+
+// Store holds the memory of every identity and mirrors each one to <dir>/<identity>.json
+type Store struct {
+	dir   string
+	vault *storage.Vault
+	items map[string][]models.MemoryItem // identity -> items, oldest first
+	mu    sync.RWMutex
+	now   func() time.Time
+}
+
+// NewStore creates a store backed by dir and loads the identities already saved there. Files
+// are written through vault, which may be nil. The store is usable even when loading fails;
+// unreadable identities start empty.
+func NewStore(dir string, vault *storage.Vault) (*Store, error) {
+	s := &Store{
+		dir:   dir,
+		vault: vault,
+		items: make(map[string][]models.MemoryItem),
+		now:   time.Now,
+	}
+
+	if err := vault.MkdirAll(dir); err != nil {
+		return s, fmt.Errorf("failed to create memory directory: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return s, fmt.Errorf("failed to list memory files: %w", err)
+	}
+
+	var loadErrs []string
+	for _, file := range files {
+		identity := strings.TrimSuffix(filepath.Base(file), ".json")
+		if !ValidIdentity(identity) {
+			continue
+		}
+
+		data, err := vault.ReadFile(file)
+		if err != nil {
+			loadErrs = append(loadErrs, fmt.Sprintf("%s: %v", identity, err))
+			continue
+		}
+		var items []models.MemoryItem
+		if err := json.Unmarshal(data, &items); err != nil {
+			loadErrs = append(loadErrs, fmt.Sprintf("%s: %v", identity, err))
+			continue
+		}
+		if len(items) > 0 {
+			s.items[identity] = items
+		}
+	}
+
+	if len(loadErrs) > 0 {
+		return s, fmt.Errorf("failed to load memory: %s", strings.Join(loadErrs, "; "))
+	}
+	return s, nil
+}
+
+// Identities returns every identity with stored memory, sorted by name
+func (s *Store) Identities() []models.MemoryIdentity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	identities := make([]models.MemoryIdentity, 0, len(s.items))
+	for identity, items := range s.items {
+		summary := models.MemoryIdentity{Identity: identity, Items: len(items)}
+		for _, item := range items {
+			if item.UpdatedAt.After(summary.UpdatedAt) {
+				summary.UpdatedAt = item.UpdatedAt
+			}
+		}
+		identities = append(identities, summary)
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		return identities[i].Identity < identities[j].Identity
+	})
+
+	return identities
+}
+
+// List returns a copy of an identity's memory, oldest first
+func (s *Store) List(identity string) ([]models.MemoryItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items, exists := s.items[identity]
+	if !exists {
+		return nil, fmt.Errorf("memory not found")
+	}
+
+	result := make([]models.MemoryItem, len(items))
+	for i, item := range items {
+		result[i] = copyItem(item)
+	}
+	return result, nil
+}
+
+// Add stores a memory item for an identity. An item with the same kind and content is
+// merged into the existing one instead of being stored twice.
+func (s *Store) Add(identity string, item models.MemoryItem) (models.MemoryItem, error) {
+	if !ValidIdentity(identity) {
+		return models.MemoryItem{}, fmt.Errorf("invalid identity")
+	}
+	item.Content = strings.TrimSpace(item.Content)
+	if err := validateItem(item.Kind, item.Content); err != nil {
+		return models.MemoryItem{}, err
+	}
+	if item.Source == "" {
+		item.Source = SourceManual
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	items := s.items[identity]
+
+	for i := range items {
+		existing := &items[i]
+		if existing.Kind != item.Kind || !sameContent(existing.Content, item.Content) {
+			continue
+		}
+		existing.Participants = mergeNames(existing.Participants, item.Participants)
+		if existing.Owner == "" {
+			existing.Owner = item.Owner
+		}
+		existing.Series = item.Series
+		existing.MeetingURL = item.MeetingURL
+		existing.UpdatedAt = now
+		if err := s.saveUnsafe(identity); err != nil {
+			return models.MemoryItem{}, err
+		}
+		return copyItem(*existing), nil
+	}
+
+	item.ID = fmt.Sprintf("mem_%s", uuid.New().String()[:8])
+	item.Participants = mergeNames(nil, item.Participants)
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	items = append(items, item)
+	if len(items) > maxItemsPerIdentity {
+		items = append([]models.MemoryItem(nil), items[len(items)-maxItemsPerIdentity:]...)
+	}
+	s.items[identity] = items
+
+	if err := s.saveUnsafe(identity); err != nil {
+		return models.MemoryItem{}, err
+	}
+	return copyItem(item), nil
+}
+
+// Update applies a partial update to one memory item
+func (s *Store) Update(identity, itemID string, update models.MemoryItemUpdate) (models.MemoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := s.indexUnsafe(identity, itemID)
+	if index < 0 {
+		return models.MemoryItem{}, fmt.Errorf("memory item not found")
+	}
+
+	item := s.items[identity][index]
+	if update.Kind != nil {
+		item.Kind = *update.Kind
+	}
+	if update.Content != nil {
+		item.Content = strings.TrimSpace(*update.Content)
+	}
+	if update.Owner != nil {
+		item.Owner = strings.TrimSpace(*update.Owner)
+	}
+	if update.Done != nil {
+		item.Done = *update.Done
+	}
+	if err := validateItem(item.Kind, item.Content); err != nil {
+		return models.MemoryItem{}, err
+	}
+	item.UpdatedAt = s.now()
+
+	s.items[identity][index] = item
+	if err := s.saveUnsafe(identity); err != nil {
+		return models.MemoryItem{}, err
+	}
+	return copyItem(item), nil
+}
+
+// Delete removes one memory item
+func (s *Store) Delete(identity, itemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := s.indexUnsafe(identity, itemID)
+	if index < 0 {
+		return fmt.Errorf("memory item not found")
+	}
+
+	items := s.items[identity]
+	s.items[identity] = append(items[:index:index], items[index+1:]...)
+	return s.saveUnsafe(identity)
+}
+
+// Purge forgets everything an identity remembers and removes its file. It returns the number of items removed.
+func (s *Store) Purge(identity string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, exists := s.items[identity]
+	if !exists {
+		return 0, fmt.Errorf("memory not found")
+	}
+
+	delete(s.items, identity)
+	return len(items), s.saveUnsafe(identity)
+}
+
+// PurgeParticipant forgets every item, across all identities, that involves the participant
+// as an attendee or action item owner. It returns the number of items removed.
+func (s *Store) PurgeParticipant(participant string) (int, error) {
+	participant = strings.TrimSpace(participant)
+	if participant == "" {
+		return 0, fmt.Errorf("participant is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	var saveErrs []string
+	for identity, items := range s.items {
+		kept := items[:0:0]
+		for _, item := range items {
+			if involves(item, participant) {
+				continue
+			}
+			kept = append(kept, item)
+		}
+		if len(kept) == len(items) {
+			continue
+		}
+
+		removed += len(items) - len(kept)
+		if len(kept) == 0 {
+			delete(s.items, identity)
+		} else {
+			s.items[identity] = kept
+		}
+		if err := s.saveUnsafe(identity); err != nil {
+			saveErrs = append(saveErrs, err.Error())
+		}
+	}
+
+	if len(saveErrs) > 0 {
+		return removed, fmt.Errorf("%s", strings.Join(saveErrs, "; "))
+	}
+	return removed, nil
+}
+
+// Recall returns the items of an identity relevant to a meeting: those from the same series
+// or shared with at least one of the participants. Completed action items are left out.
+// Open action items come first, then decisions, then facts, newest first within each kind.
+func (s *Store) Recall(identity, series string, participants []string, limit int) []models.MemoryItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var recalled []models.MemoryItem
+	for _, item := range s.items[identity] {
+		if item.Kind == models.MemoryKindActionItem && item.Done {
+			continue
+		}
+		relevant := series != "" && item.Series == series
+		for _, participant := range participants {
+			if relevant {
+				break
+			}
+			relevant = involves(item, participant)
+		}
+		if relevant {
+			recalled = append(recalled, copyItem(item))
+		}
+	}
+
+	sort.SliceStable(recalled, func(i, j int) bool {
+		if ri, rj := kindRank(recalled[i].Kind), kindRank(recalled[j].Kind); ri != rj {
+			return ri < rj
+		}
+		return recalled[i].UpdatedAt.After(recalled[j].UpdatedAt)
+	})
+
+	if limit > 0 && len(recalled) > limit {
+		recalled = recalled[:limit]
+	}
+	return recalled
+}
+
+// indexUnsafe returns the position of an item, or -1 (caller must hold mu)
+func (s *Store) indexUnsafe(identity, itemID string) int {
+	for i, item := range s.items[identity] {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// saveUnsafe writes an identity's memory to disk, removing the file once it is empty
+// (caller must hold mu). The file is replaced atomically so a crash never leaves it truncated.
+func (s *Store) saveUnsafe(identity string) error {
+	path := filepath.Join(s.dir, identity+".json")
+
+	items := s.items[identity]
+	if len(items) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove memory file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal memory: %w", err)
+	}
+
+	if err := s.vault.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write memory file: %w", err)
+	}
+	return nil
+}
+
+// validateItem checks the fields every memory item needs
+func validateItem(kind models.MemoryKind, content string) error {
+	if !ValidKind(kind) {
+		return fmt.Errorf("invalid memory kind")
+	}
+	if content == "" {
+		return fmt.Errorf("memory content is required")
+	}
+	if len(content) > maxContentLength {
+		return fmt.Errorf("memory content too long")
+	}
+	return nil
+}
+
+// involves reports whether a participant attended the meeting an item came from or owns it
+func involves(item models.MemoryItem, participant string) bool {
+	if strings.EqualFold(item.Owner, participant) {
+		return true
+	}
+	for _, name := range item.Participants {
+		if strings.EqualFold(name, participant) {
+			return true
+		}
+	}
+	return false
+}
+
+// kindRank orders recalled items by how actionable they are
+func kindRank(kind models.MemoryKind) int {
+	switch kind {
+	case models.MemoryKindActionItem:
+		return 0
+	case models.MemoryKindDecision:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// sameContent compares memory contents ignoring case, spacing and trailing punctuation
+func sameContent(a, b string) bool {
+	normalize := func(s string) string {
+		return strings.TrimRight(strings.ToLower(strings.Join(strings.Fields(s), " ")), ".!")
+	}
+	return normalize(a) == normalize(b)
+}
+
+// mergeNames adds names to a list, skipping blanks and case-insensitive duplicates
+func mergeNames(names, more []string) []string {
+	merged := append([]string(nil), names...)
+	for _, name := range more {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		duplicate := false
+		for _, existing := range merged {
+			if strings.EqualFold(existing, name) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
+
+// copyItem returns an item that shares no slices with the store
+func copyItem(item models.MemoryItem) models.MemoryItem {
+	item.Participants = append([]string(nil), item.Participants...)
+	return item
+}
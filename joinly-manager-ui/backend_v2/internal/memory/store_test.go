@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+)
+
+func TestStoreAddMergesDuplicatesAndPersists(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, nil)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	first, err := store.Add("weekly-sync", models.MemoryItem{Kind: models.MemoryKindDecision, Content: "Ship v2 on Friday.", Participants: []string{"Alice"}})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	second, err := store.Add("weekly-sync", models.MemoryItem{Kind: models.MemoryKindDecision, Content: "ship v2 on  friday", Participants: []string{"alice", "Bob"}})
+	if err != nil {
+		t.Fatalf("Add duplicate: %v", err)
+	}
+	if second.ID != first.ID || len(second.Participants) != 2 {
+		t.Fatalf("duplicate not merged: %+v", second)
+	}
+
+	if _, err := store.Add("weekly-sync", models.MemoryItem{Kind: "rumour", Content: "x"}); err == nil {
+		t.Fatal("expected invalid kind to be rejected")
+	}
+	if _, err := store.Add("../etc", models.MemoryItem{Kind: models.MemoryKindFact, Content: "x"}); err == nil {
+		t.Fatal("expected invalid identity to be rejected")
+	}
+
+	reloaded, err := NewStore(dir, nil)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	items, err := reloaded.List("weekly-sync")
+	if err != nil || len(items) != 1 || items[0].Source != SourceManual {
+		t.Fatalf("reloaded items = %+v, %v", items, err)
+	}
+}
+
+func TestStoreRecall(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), nil)
+	clock := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+
+	add := func(kind models.MemoryKind, content, series string, participants ...string) models.MemoryItem {
+		t.Helper()
+		item, err := store.Add("pm", models.MemoryItem{Kind: kind, Content: content, Series: series, Participants: participants})
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		return item
+	}
+
+	add(models.MemoryKindFact, "Team uses Postgres", "weekly")
+	add(models.MemoryKindDecision, "Freeze scope", "weekly")
+	done := add(models.MemoryKindActionItem, "Write the RFC", "weekly")
+	add(models.MemoryKindActionItem, "Book the venue", "offsite", "Carol")
+	add(models.MemoryKindFact, "Unrelated", "other", "Dave")
+
+	isDone := true
+	if _, err := store.Update("pm", done.ID, models.MemoryItemUpdate{Done: &isDone}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	recalled := store.Recall("pm", "weekly", []string{"carol"}, 0)
+	var contents []string
+	for _, item := range recalled {
+		contents = append(contents, item.Content)
+	}
+	want := []string{"Book the venue", "Freeze scope", "Team uses Postgres"}
+	if len(contents) != len(want) {
+		t.Fatalf("recalled %v, want %v", contents, want)
+	}
+	for i := range want {
+		if contents[i] != want[i] {
+			t.Fatalf("recalled %v, want %v", contents, want)
+		}
+	}
+
+	if limited := store.Recall("pm", "weekly", nil, 1); len(limited) != 1 {
+		t.Fatalf("limit not applied: %d items", len(limited))
+	}
+}
+
+func TestStorePurgeParticipant(t *testing.T) {
+	store, _ := NewStore(t.TempDir(), nil)
+	store.Add("a", models.MemoryItem{Kind: models.MemoryKindActionItem, Content: "Send notes", Owner: "Erin"})
+	store.Add("a", models.MemoryItem{Kind: models.MemoryKindFact, Content: "Kept"})
+	store.Add("b", models.MemoryItem{Kind: models.MemoryKindFact, Content: "Erin is on leave", Participants: []string{"erin"}})
+
+	removed, err := store.PurgeParticipant("Erin")
+	if err != nil || removed != 2 {
+		t.Fatalf("PurgeParticipant = %d, %v", removed, err)
+	}
+	if _, err := store.List("b"); err == nil {
+		t.Fatal("expected identity b to be empty after purge")
+	}
+	if items, _ := store.List("a"); len(items) != 1 || items[0].Content != "Kept" {
+		t.Fatalf("identity a = %+v", items)
+	}
+}
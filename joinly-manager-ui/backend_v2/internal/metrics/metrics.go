@@ -0,0 +1,224 @@
+// Package metrics exposes Prometheus metrics for the agent manager. Metrics live in a
+// private registry so that importing the package never pollutes the global default.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "joinly_manager"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	utterancesProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "utterances_processed_total",
+		Help:      "Utterances processed per agent.",
+	}, []string{"agent_id"})
+
+	llmCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_call_duration_seconds",
+		Help:      "LLM call latency per provider and model.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 16, 32, 64},
+	}, []string{"provider", "model"})
+
+	llmCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_call_errors_total",
+		Help:      "Failed LLM calls per provider and model.",
+	}, []string{"provider", "model"})
+
+	llmTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "LLM tokens consumed per provider, model and direction (prompt or completion).",
+	}, []string{"provider", "model", "direction"})
+
+	llmCost = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_cost_usd_total",
+		Help:      "LLM spend in USD per provider and model, priced from the configured price table.",
+	}, []string{"provider", "model"})
+
+	speakDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "speak_text_duration_seconds",
+		Help:      "Latency of the joinly speak_text tool call.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32, 64},
+	}, []string{"result"})
+
+	speechInterruptions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "speech_interruptions_total",
+		Help:      "Agent replies cut short by participant speech, by who stopped them (server or barge_in).",
+	}, []string{"source"})
+
+	addressingDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "addressing_decisions_total",
+		Help:      "Decisions on whether participant speech was directed at a name-triggered agent, by reason.",
+	}, []string{"reason"})
+
+	interjectionDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "interjection_decisions_total",
+		Help:      "Interjection policy decisions to speak or stay silent, by the rule that made the agent speak.",
+	}, []string{"decision", "rule"})
+
+	transcriptPollFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transcript_poll_failures_total",
+		Help:      "Failed reads of the joinly transcript segments resource.",
+	})
+
+	websocketDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "websocket_messages_dropped_total",
+		Help:      "WebSocket messages dropped because a buffer was full.",
+	}, []string{"reason"})
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests per route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency per route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+// apiCalls counts HTTP requests per "METHOD route" for the /usage endpoint
+var (
+	apiCalls   = make(map[string]int)
+	apiCallsMu sync.Mutex
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		utterancesProcessed,
+		llmCallDuration,
+		llmCallErrors,
+		llmTokens,
+		llmCost,
+		speakDuration,
+		speechInterruptions,
+		addressingDecisions,
+		interjectionDecisions,
+		transcriptPollFailures,
+		websocketDropped,
+		httpRequests,
+		httpRequestDuration,
+		state,
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// IncUtterancesProcessed counts an utterance handled by an agent
+func IncUtterancesProcessed(agentID string) {
+	utterancesProcessed.WithLabelValues(agentID).Inc()
+}
+
+// ForgetAgent removes the series of a deleted agent
+func ForgetAgent(agentID string) {
+	utterancesProcessed.DeleteLabelValues(agentID)
+}
+
+// ObserveLLMCall records the latency and outcome of an LLM call
+func ObserveLLMCall(provider, model string, duration time.Duration, err error) {
+	llmCallDuration.WithLabelValues(provider, model).Observe(duration.Seconds())
+	if err != nil {
+		llmCallErrors.WithLabelValues(provider, model).Inc()
+	}
+}
+
+// AddLLMTokens records the tokens reported by an LLM provider
+func AddLLMTokens(provider, model string, promptTokens, completionTokens int) {
+	if promptTokens > 0 {
+		llmTokens.WithLabelValues(provider, model, "prompt").Add(float64(promptTokens))
+	}
+	if completionTokens > 0 {
+		llmTokens.WithLabelValues(provider, model, "completion").Add(float64(completionTokens))
+	}
+}
+
+// AddLLMCost records LLM spend
+func AddLLMCost(provider, model string, costUSD float64) {
+	if costUSD > 0 {
+		llmCost.WithLabelValues(provider, model).Add(costUSD)
+	}
+}
+
+// ObserveSpeak records the latency and outcome of a speak_text call
+func ObserveSpeak(duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	speakDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// IncSpeechInterruptions counts a reply cut short by participant speech
+func IncSpeechInterruptions(source string) {
+	speechInterruptions.WithLabelValues(source).Inc()
+}
+
+// IncAddressingDecisions counts an addressing decision of a name-triggered agent
+func IncAddressingDecisions(reason string) {
+	addressingDecisions.WithLabelValues(reason).Inc()
+}
+
+// IncInterjectionDecisions counts an interjection policy decision
+func IncInterjectionDecisions(decision, rule string) {
+	interjectionDecisions.WithLabelValues(decision, rule).Inc()
+}
+
+// IncTranscriptPollFailures counts a failed transcript read
+func IncTranscriptPollFailures() {
+	transcriptPollFailures.Inc()
+}
+
+// IncWebSocketDropped counts a dropped WebSocket message
+func IncWebSocketDropped(reason string) {
+	websocketDropped.WithLabelValues(reason).Inc()
+}
+
+// ObserveHTTPRequest records a served HTTP request
+func ObserveHTTPRequest(route, method string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+
+	apiCallsMu.Lock()
+	apiCalls[method+" "+route]++
+	apiCallsMu.Unlock()
+}
+
+// APICalls returns a snapshot of the request counts per "METHOD route"
+func APICalls() map[string]int {
+	apiCallsMu.Lock()
+	defer apiCallsMu.Unlock()
+
+	calls := make(map[string]int, len(apiCalls))
+	for key, count := range apiCalls {
+		calls[key] = count
+	}
+	return calls
+}
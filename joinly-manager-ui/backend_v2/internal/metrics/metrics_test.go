@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerExposesRecordedMetrics(t *testing.T) {
+	SetStateFunc(func() State {
+		return State{AgentsByStatus: map[string]int{"running": 2}, Meetings: 1, WebSocketClients: 3}
+	})
+	defer SetStateFunc(nil)
+
+	ObserveLLMCall("openai", "gpt-4o", 1200*time.Millisecond, nil)
+	ObserveLLMCall("openai", "gpt-4o", time.Second, errors.New("boom"))
+	AddLLMTokens("openai", "gpt-4o", 120, 30)
+	ObserveHTTPRequest("/agents/:agent_id", "GET", 200, 5*time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	output := string(body)
+
+	for _, expected := range []string{
+		`joinly_manager_llm_call_duration_seconds_count{model="gpt-4o",provider="openai"} 2`,
+		`joinly_manager_llm_call_errors_total{model="gpt-4o",provider="openai"} 1`,
+		`joinly_manager_llm_tokens_total{direction="prompt",model="gpt-4o",provider="openai"} 120`,
+		`joinly_manager_http_requests_total{method="GET",route="/agents/:agent_id",status="200"} 1`,
+		`joinly_manager_agents{status="running"} 2`,
+		`joinly_manager_websocket_clients 3`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected metrics output to contain %q", expected)
+		}
+	}
+
+	if calls := APICalls()["GET /agents/:agent_id"]; calls != 1 {
+		t.Errorf("Expected 1 API call for GET /agents/:agent_id, got %d", calls)
+	}
+}
+
+func TestForgetAgentRemovesItsSeries(t *testing.T) {
+	IncUtterancesProcessed("agent_deleted")
+	ForgetAgent("agent_deleted")
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if body, _ := io.ReadAll(recorder.Body); strings.Contains(string(body), "agent_deleted") {
+		t.Error("Expected the series of a deleted agent to be removed")
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// State is a point-in-time snapshot of the manager, read on every scrape
+type State struct {
+	AgentsByStatus   map[string]int
+	Meetings         int
+	WebSocketClients int
+}
+
+// stateCollector turns a State snapshot into gauges at scrape time
+type stateCollector struct {
+	snapshot func() State
+	mu       sync.RWMutex
+
+	agents    *prometheus.Desc
+	meetings  *prometheus.Desc
+	wsClients *prometheus.Desc
+}
+
+var state = &stateCollector{
+	agents:    prometheus.NewDesc(namespace+"_agents", "Agents per status.", []string{"status"}, nil),
+	meetings:  prometheus.NewDesc(namespace+"_meetings", "Meetings with at least one agent.", nil, nil),
+	wsClients: prometheus.NewDesc(namespace+"_websocket_clients", "Connected WebSocket clients.", nil, nil),
+}
+
+// SetStateFunc sets the function that provides the manager snapshot for the state gauges
+func SetStateFunc(snapshot func() State) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.snapshot = snapshot
+}
+
+// Describe implements prometheus.Collector
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.agents
+	ch <- c.meetings
+	ch <- c.wsClients
+}
+
+// Collect implements prometheus.Collector
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	snapshot := c.snapshot
+	c.mu.RUnlock()
+
+	if snapshot == nil {
+		return
+	}
+	current := snapshot()
+
+	for status, count := range current.AgentsByStatus {
+		ch <- prometheus.MustNewConstMetric(c.agents, prometheus.GaugeValue, float64(count), status)
+	}
+	ch <- prometheus.MustNewConstMetric(c.meetings, prometheus.GaugeValue, float64(current.Meetings))
+	ch <- prometheus.MustNewConstMetric(c.wsClients, prometheus.GaugeValue, float64(current.WebSocketClients))
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AgentConfigUpdate represents the outcome of a partial agent configuration update
+type AgentConfigUpdate struct {
+	Agent           *Agent   `json:"agent" yaml:"agent"`
+	Changed         []string `json:"changed" yaml:"changed"`                   // Every field whose value changed
+	Applied         []string `json:"applied" yaml:"applied"`                   // Fields applied to the running agent immediately
+	RestartRequired []string `json:"restart_required" yaml:"restart_required"` // Fields that only take effect after a restart
+}
+
+// MergeAgentConfig applies a partial JSON patch to an agent configuration.
+// Keys are AgentConfig JSON field names; a null value clears an optional field.
+func MergeAgentConfig(base AgentConfig, patch map[string]json.RawMessage) (AgentConfig, error) {
+	known := agentConfigFields()
+	for field := range patch {
+		if !known[field] {
+			return AgentConfig{}, fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	fields, err := agentConfigToMap(base)
+	if err != nil {
+		return AgentConfig{}, err
+	}
+	for field, value := range patch {
+		fields[field] = value
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return AgentConfig{}, fmt.Errorf("failed to encode merged config: %w", err)
+	}
+
+	var result AgentConfig
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return AgentConfig{}, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	return result, nil
+}
+
+// ChangedAgentConfigFields lists the JSON field names whose values differ between two configurations
+func ChangedAgentConfigFields(old, updated AgentConfig) []string {
+	oldFields, errOld := agentConfigToMap(old)
+	newFields, errNew := agentConfigToMap(updated)
+	if errOld != nil || errNew != nil {
+		return nil
+	}
+
+	var changed []string
+	for field := range agentConfigFields() {
+		if !bytes.Equal(oldFields[field], newFields[field]) {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+// agentConfigToMap encodes an agent configuration as a map of raw JSON fields
+func agentConfigToMap(config AgentConfig) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	return fields, nil
+}
+
+// agentConfigFields returns the set of JSON field names of AgentConfig
+func agentConfigFields() map[string]bool {
+	fields := make(map[string]bool)
+
+	configType := reflect.TypeOf(AgentConfig{})
+	for i := 0; i < configType.NumField(); i++ {
+		name := strings.Split(configType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+
+	return fields
+}
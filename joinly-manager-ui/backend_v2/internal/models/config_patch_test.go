@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergeAgentConfig(t *testing.T) {
+	prompt := "Be brief"
+	base := AgentConfig{
+		Name:         "Assistant",
+		MeetingURL:   "https://meet.google.com/abc-defg-hij",
+		LLMProvider:  LLMProviderOpenAI,
+		LLMModel:     "gpt-4o",
+		CustomPrompt: &prompt,
+		AutoJoin:     true,
+	}
+
+	patch := map[string]json.RawMessage{
+		"llm_model":     json.RawMessage(`"gpt-4o-mini"`),
+		"custom_prompt": json.RawMessage(`null`),
+		"auto_join":     json.RawMessage(`false`),
+	}
+
+	merged, err := MergeAgentConfig(base, patch)
+	if err != nil {
+		t.Fatalf("Failed to merge config: %v", err)
+	}
+	if merged.LLMModel != "gpt-4o-mini" || merged.CustomPrompt != nil || merged.AutoJoin {
+		t.Errorf("Patch not applied: %+v", merged)
+	}
+	if merged.Name != base.Name || merged.MeetingURL != base.MeetingURL {
+		t.Errorf("Untouched fields changed: %+v", merged)
+	}
+
+	changed := ChangedAgentConfigFields(base, merged)
+	if expected := []string{"auto_join", "custom_prompt", "llm_model"}; !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected changed fields %v, got %v", expected, changed)
+	}
+}
+
+func TestMergeAgentConfig_RejectsBadPatches(t *testing.T) {
+	if _, err := MergeAgentConfig(AgentConfig{}, map[string]json.RawMessage{"llm_modle": json.RawMessage(`"x"`)}); err == nil {
+		t.Error("Expected unknown field to be rejected")
+	}
+	if _, err := MergeAgentConfig(AgentConfig{}, map[string]json.RawMessage{"auto_join": json.RawMessage(`"yes"`)}); err == nil {
+		t.Error("Expected wrongly typed value to be rejected")
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -24,6 +25,9 @@ const (
 	LLMProviderAnthropic LLMProvider = "anthropic"
 	LLMProviderGoogle    LLMProvider = "google"
 	LLMProviderOllama    LLMProvider = "ollama"
+
+	LLMProviderOpenAICompatible LLMProvider = "openai_compatible" // Self-hosted servers speaking the OpenAI API
+	LLMProviderMock             LLMProvider = "mock"              // Scripted responses for offline runs and tests
 )
 
 // TTSProvider represents the TTS provider type
@@ -51,6 +55,137 @@ const (
 	ConversationModeAnalyst        ConversationMode = "analyst"        // Analyst: transcribes and analyzes without speaking
 )
 
+// BudgetAction represents what happens to an agent once its LLM budget is spent
+type BudgetAction string
+
+const (
+	BudgetActionDowngrade BudgetAction = "downgrade" // Switch to the fallback model
+	BudgetActionSilence   BudgetAction = "silence"   // Stop making LLM calls
+)
+
+// AgentBudget represents an optional LLM spending limit for an agent
+type AgentBudget struct {
+	MaxCostUSD       float64      `json:"max_cost_usd" yaml:"max_cost_usd"`
+	Action           BudgetAction `json:"action" yaml:"action"`
+	FallbackProvider LLMProvider  `json:"fallback_provider,omitempty" yaml:"fallback_provider,omitempty"` // Defaults to the agent's provider
+	FallbackModel    string       `json:"fallback_model,omitempty" yaml:"fallback_model,omitempty"`       // Required for downgrade
+}
+
+// TurnTakingConfig represents how a conversational agent detects the end of a participant's
+// turn and reacts to being interrupted. Zero values use the defaults.
+type TurnTakingConfig struct {
+	DebounceMs           int   `json:"debounce_ms,omitempty" yaml:"debounce_ms,omitempty"`                       // Silence that ends a turn (default 2000)
+	QuestionDebounceMs   int   `json:"question_debounce_ms,omitempty" yaml:"question_debounce_ms,omitempty"`     // After a question mark (default 800)
+	IncompleteDebounceMs int   `json:"incomplete_debounce_ms,omitempty" yaml:"incomplete_debounce_ms,omitempty"` // After a trailing comma, conjunction or filler (default 3500)
+	BargeIn              *bool `json:"barge_in,omitempty" yaml:"barge_in,omitempty"`                             // Stop speaking when a participant talks over the agent (default true)
+	BargeInMinWords      int   `json:"barge_in_min_words,omitempty" yaml:"barge_in_min_words,omitempty"`         // Words needed to count as barge-in rather than backchannel (default 2)
+}
+
+// AddressingConfig represents how a name-triggered agent decides whether an utterance is
+// directed at it. Zero values use the defaults.
+type AddressingConfig struct {
+	Aliases         []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`                     // Other names the agent answers to, besides its name
+	FollowUpSeconds int      `json:"follow_up_seconds,omitempty" yaml:"follow_up_seconds,omitempty"` // After a reply, follow-ups need no name for this long (default 20, -1 disables)
+	LLMCheck        bool     `json:"llm_check,omitempty" yaml:"llm_check,omitempty"`                 // Ask the LLM about utterances that do not name the agent
+	LLMCheckModel   string   `json:"llm_check_model,omitempty" yaml:"llm_check_model,omitempty"`     // Cheaper model of the agent's provider for the check
+}
+
+// InterjectionRule represents a reason for a conversational agent to speak up
+type InterjectionRule string
+
+const (
+	InterjectionDirectQuestion    InterjectionRule = "direct_question"     // Answer questions asked in the meeting
+	InterjectionFactualCorrection InterjectionRule = "factual_correction"  // Correct a clear factual error
+	InterjectionActionItem        InterjectionRule = "related_action_item" // Bring up a related open action item
+)
+
+// InterjectionConfig represents the policy deciding when a conversational agent speaks.
+// Zero values use the defaults.
+type InterjectionConfig struct {
+	Rules           []InterjectionRule `json:"rules,omitempty" yaml:"rules,omitempty"`                           // Reasons to speak up (default all)
+	CooldownSeconds int                `json:"cooldown_seconds,omitempty" yaml:"cooldown_seconds,omitempty"`     // Minimum time between interjections (default 30)
+	MaxPer10Minutes int                `json:"max_per_10_minutes,omitempty" yaml:"max_per_10_minutes,omitempty"` // Interjections allowed in any 10 minutes (default 5)
+	CheckModel      string             `json:"check_model,omitempty" yaml:"check_model,omitempty"`               // Cheaper model of the agent's provider for the factual check
+}
+
+// PIIDetector represents a kind of personal data the redaction stage recognises
+type PIIDetector string
+
+const (
+	PIIEmail PIIDetector = "email"
+	PIIPhone PIIDetector = "phone"
+	PIICard  PIIDetector = "card"  // Payment card numbers passing the Luhn check
+	PIIIBAN  PIIDetector = "iban"  // Bank account numbers passing the IBAN checksum
+	PIIIP    PIIDetector = "ip"    // IPv4 addresses
+	PIINames PIIDetector = "names" // Names of the meeting's participants
+)
+
+// RedactionConfig represents how an agent keeps personal data out of LLM prompts and stored
+// files. Zero values use the defaults.
+type RedactionConfig struct {
+	Detectors  []PIIDetector `json:"detectors,omitempty" yaml:"detectors,omitempty"`     // Kinds of personal data to redact (default all)
+	Terms      []string      `json:"terms,omitempty" yaml:"terms,omitempty"`             // Further words and phrases to redact, e.g. customer names
+	PersistRaw bool          `json:"persist_raw,omitempty" yaml:"persist_raw,omitempty"` // Store transcripts, analyses and memories unredacted
+}
+
+// ConsentDelivery represents how an agent delivers its recording disclosure
+type ConsentDelivery string
+
+const (
+	ConsentDeliverySpeak ConsentDelivery = "speak"
+	ConsentDeliveryChat  ConsentDelivery = "chat"
+	ConsentDeliveryBoth  ConsentDelivery = "both"
+)
+
+// ConsentConfig represents how an agent discloses that an AI is listening once it joined, and
+// how participants object to it. Zero values use the defaults.
+type ConsentConfig struct {
+	Message                string          `json:"message,omitempty" yaml:"message,omitempty"`                                   // Disclosure; {name} is replaced by the agent's name (default names the first objection phrase)
+	Delivery               ConsentDelivery `json:"delivery,omitempty" yaml:"delivery,omitempty"`                                 // Speak and/or post the disclosure in the chat (default both)
+	ObjectionWindowSeconds int             `json:"objection_window_seconds,omitempty" yaml:"objection_window_seconds,omitempty"` // Wait this long after the disclosure before answering or analysing (default 0)
+	ObjectionPhrases       []string        `json:"objection_phrases,omitempty" yaml:"objection_phrases,omitempty"`               // Said or typed by a participant to object (default "stop recording", "do not record", "don't record")
+}
+
+// ConsentObjection represents a participant objecting to an agent listening to the meeting
+type ConsentObjection struct {
+	Participant string    `json:"participant" yaml:"participant"`
+	Source      string    `json:"source" yaml:"source"` // speech or chat
+	Phrase      string    `json:"phrase" yaml:"phrase"` // Objection phrase that matched
+	Time        time.Time `json:"time" yaml:"time"`
+}
+
+// LLMTarget represents a provider/model pair in an agent's fallback chain
+type LLMTarget struct {
+	Provider         LLMProvider             `json:"provider" yaml:"provider"`
+	Model            string                  `json:"model" yaml:"model"`
+	OpenAICompatible *OpenAICompatibleConfig `json:"openai_compatible,omitempty" yaml:"openai_compatible,omitempty"` // Endpoint for the openai_compatible provider
+}
+
+// OpenAICompatibleConfig represents the endpoint of an OpenAI-compatible server
+// (vLLM, llama.cpp server, LM Studio, LiteLLM, ...)
+type OpenAICompatibleConfig struct {
+	BaseURL            string            `json:"base_url" yaml:"base_url"`                           // API root including the version, e.g. http://vllm:8000/v1
+	APIKeyEnv          string            `json:"api_key_env,omitempty" yaml:"api_key_env,omitempty"` // Environment variable holding the API key
+	Headers            map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`         // Extra request headers
+	SupportsJSONSchema bool              `json:"supports_json_schema" yaml:"supports_json_schema"`   // Server honours response_format json_schema
+	SupportsTools      bool              `json:"supports_tools" yaml:"supports_tools"`               // Server supports tool calling; used for structured output without JSON schema
+	SupportsStreaming  bool              `json:"supports_streaming" yaml:"supports_streaming"`       // Request replies as server-sent events
+}
+
+// MockLLMConfig represents the scripted responses of the mock provider. Responses are tried in
+// order; the first whose pattern and schema both match the call answers it.
+type MockLLMConfig struct {
+	Responses []MockResponse `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Default   string         `json:"default,omitempty" yaml:"default,omitempty"` // Answer when no response matches (default: an empty answer of the requested shape)
+}
+
+// MockResponse represents one scripted answer of the mock provider
+type MockResponse struct {
+	Pattern  string `json:"pattern,omitempty" yaml:"pattern,omitempty"` // Regular expression matched against the prompt (empty matches any)
+	Schema   string `json:"schema,omitempty" yaml:"schema,omitempty"`   // Name of the requested response schema, e.g. summary (empty matches any)
+	Response string `json:"response" yaml:"response"`
+}
+
 // Note: TranscriptionController removed - transcription should be clean, context is for response generation
 
 // ConversationEntry represents a single entry in conversation history
@@ -58,6 +193,19 @@ type ConversationEntry struct {
 	Speaker   string    `json:"speaker" yaml:"speaker"`
 	Message   string    `json:"message" yaml:"message"`
 	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	IsAgent   bool      `json:"is_agent,omitempty" yaml:"is_agent,omitempty"` // Spoken by the agent itself (sent to the LLM as an assistant turn)
+}
+
+// ConversationWindow represents the conversation history sent to the LLM with a reply request
+type ConversationWindow struct {
+	Summary string              `json:"summary,omitempty" yaml:"summary,omitempty"` // Rolling summary of turns that no longer fit the token budget
+	Turns   []ConversationEntry `json:"turns" yaml:"turns"`                         // Most recent turns, oldest first
+
+	Memories []MemoryItem `json:"memories,omitempty" yaml:"memories,omitempty"` // Recalled from earlier meetings of the agent's identity
+
+	Passages []KnowledgePassage `json:"passages,omitempty" yaml:"passages,omitempty"` // Retrieved from the agent's knowledge bases for the current utterance
+
+	Interjection string `json:"interjection,omitempty" yaml:"interjection,omitempty"` // What the interjection policy wants the reply to bring up
 }
 
 // AgentConfig represents the configuration for an agent
@@ -66,6 +214,8 @@ type AgentConfig struct {
 	MeetingURL        string           `json:"meeting_url" yaml:"meeting_url"`
 	LLMProvider       LLMProvider      `json:"llm_provider" yaml:"llm_provider"`
 	LLMModel          string           `json:"llm_model" yaml:"llm_model"`
+	LLMFallbacks      []LLMTarget      `json:"llm_fallbacks,omitempty" yaml:"llm_fallbacks,omitempty"` // Tried in order when the primary provider fails
+	CannedFallback    bool             `json:"canned_fallback" yaml:"canned_fallback"`                 // Reply with a canned phrase when every provider fails
 	TTSProvider       TTSProvider      `json:"tts_provider" yaml:"tts_provider"`
 	STTProvider       STTProvider      `json:"stt_provider" yaml:"stt_provider"`
 	Language          string           `json:"language" yaml:"language"`
@@ -89,6 +239,54 @@ type AgentConfig struct {
 	VADArgs map[string]interface{} `json:"vad_args,omitempty" yaml:"vad_args,omitempty"`
 
 	EnvVars map[string]string `json:"env_vars" yaml:"env_vars"`
+
+	// Cost accounting
+	Tenant string       `json:"tenant,omitempty" yaml:"tenant,omitempty"` // Groups agents for cost reporting
+	Budget *AgentBudget `json:"budget,omitempty" yaml:"budget,omitempty"`
+
+	// Endpoint when llm_provider is openai_compatible
+	OpenAICompatible *OpenAICompatibleConfig `json:"openai_compatible,omitempty" yaml:"openai_compatible,omitempty"`
+
+	// Scripted responses when llm_provider (or a fallback) is mock
+	MockLLM *MockLLMConfig `json:"mock_llm,omitempty" yaml:"mock_llm,omitempty"`
+
+	// Conversation context sent to the LLM
+	SystemPrompt       *string `json:"system_prompt,omitempty" yaml:"system_prompt,omitempty"`               // Replaces the default system prompt of conversational agents
+	ContextTokenBudget int     `json:"context_token_budget,omitempty" yaml:"context_token_budget,omitempty"` // History tokens sent per reply; older turns are summarised (0 = 2000)
+
+	// Long-term memory shared by every agent with the same identity
+	Identity      string `json:"identity,omitempty" yaml:"identity,omitempty"`             // Enables memory across meetings when set
+	MeetingSeries string `json:"meeting_series,omitempty" yaml:"meeting_series,omitempty"` // Groups recurring meetings (defaults to the meeting URL)
+
+	// Knowledge bases searched for passages to answer from
+	KnowledgeBases []string `json:"knowledge_bases,omitempty" yaml:"knowledge_bases,omitempty"`
+
+	// End-of-turn detection and barge-in
+	TurnTaking *TurnTakingConfig `json:"turn_taking,omitempty" yaml:"turn_taking,omitempty"`
+
+	// Deciding whether name-triggered agents are being addressed
+	Addressing *AddressingConfig `json:"addressing,omitempty" yaml:"addressing,omitempty"`
+
+	// Policy deciding whether a conversational agent speaks up; without it every utterance that reaches the agent is answered
+	Interjection *InterjectionConfig `json:"interjection,omitempty" yaml:"interjection,omitempty"`
+
+	// Personal data replaced by tokens before every LLM call and, unless persist_raw is set, in stored files
+	Redaction *RedactionConfig `json:"redaction,omitempty" yaml:"redaction,omitempty"`
+
+	// Disclosure announced after joining; a participant's objection makes the agent purge what it captured and leave
+	Consent *ConsentConfig `json:"consent,omitempty" yaml:"consent,omitempty"`
+}
+
+// FieldError describes a single invalid field in a request payload
+type FieldError struct {
+	Field   string `json:"field" yaml:"field"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// ValidationResult represents the outcome of validating an agent configuration
+type ValidationResult struct {
+	Valid  bool         `json:"valid" yaml:"valid"`
+	Errors []FieldError `json:"errors" yaml:"errors"`
 }
 
 // Agent represents an agent instance
@@ -101,7 +299,13 @@ type Agent struct {
 	StoppedAt   *time.Time  `json:"stopped_at,omitempty" yaml:"stopped_at,omitempty"`
 	ErrorMsg    *string     `json:"error_message,omitempty" yaml:"error_message,omitempty"`
 	GoroutineID *int        `json:"goroutine_id,omitempty" yaml:"goroutine_id,omitempty"`
-	Logs        []LogEntry  `json:"logs" yaml:"logs"`
+	BackendURL  string      `json:"backend_url,omitempty" yaml:"backend_url,omitempty"` // Joinly server leased from the backend pool
+	Cost        CostTotals  `json:"cost" yaml:"cost"`
+	// Set once the budget is spent and its action has been applied
+	BudgetExceeded bool `json:"budget_exceeded,omitempty" yaml:"budget_exceeded,omitempty"`
+	// Set when a participant objected and the agent left the meeting; cleared when it joins again
+	ConsentObjection *ConsentObjection `json:"consent_objection,omitempty" yaml:"consent_objection,omitempty"`
+	Logs             []LogEntry        `json:"logs" yaml:"logs"`
 }
 
 // LogEntry represents a log entry for an agent
@@ -127,13 +331,298 @@ type TranscriptSegment struct {
 	IsAgent   bool    `json:"is_agent" yaml:"is_agent"`
 }
 
+// MeetingSegment represents a transcript segment of a meeting as joinly reported it
+type MeetingSegment struct {
+	Start   float64 `json:"start" yaml:"start"` // Seconds from the start of the meeting
+	End     float64 `json:"end" yaml:"end"`
+	Speaker string  `json:"speaker" yaml:"speaker"`
+	Text    string  `json:"text" yaml:"text"`
+	IsAgent bool    `json:"is_agent" yaml:"is_agent"` // Spoken by the agent itself
+}
+
+// MeetingRecord represents the archived record of one meeting session, from the first agent
+// joining a meeting to the last one leaving it
+type MeetingRecord struct {
+	ID           string               `json:"id" yaml:"id"`
+	MeetingURL   string               `json:"meeting_url" yaml:"meeting_url"`
+	StartedAt    time.Time            `json:"started_at" yaml:"started_at"`
+	EndedAt      time.Time            `json:"ended_at" yaml:"ended_at"`
+	Participants []string             `json:"participants" yaml:"participants"` // Human speakers in the transcript
+	Agents       []MeetingRecordAgent `json:"agents" yaml:"agents"`
+	Segments     int                  `json:"segments" yaml:"segments"`                         // Length of the transcript
+	Transcript   []MeetingSegment     `json:"transcript,omitempty" yaml:"transcript,omitempty"` // Omitted from listings
+	Cost         CostTotals           `json:"cost" yaml:"cost"`
+}
+
+// MeetingRecordAgent represents an agent's part in an archived meeting session
+type MeetingRecordAgent struct {
+	ID               string           `json:"id" yaml:"id"`
+	Name             string           `json:"name" yaml:"name"`
+	ConversationMode ConversationMode `json:"conversation_mode" yaml:"conversation_mode"`
+	JoinedAt         time.Time        `json:"joined_at" yaml:"joined_at"`
+	LeftAt           time.Time        `json:"left_at" yaml:"left_at"`
+	Cost             CostTotals       `json:"cost" yaml:"cost"`                                       // LLM cost during the session
+	Analysis         json.RawMessage  `json:"analysis,omitempty" yaml:"analysis,omitempty"`           // Analyst agents only; omitted from listings
+	AnalysisFile     string           `json:"analysis_file,omitempty" yaml:"analysis_file,omitempty"` // Deleted with the record
+}
+
+// MeetingHistory represents one page of archived meeting records, newest first
+type MeetingHistory struct {
+	Meetings []MeetingRecord `json:"meetings" yaml:"meetings"`
+	Total    int             `json:"total" yaml:"total"`
+	Offset   int             `json:"offset" yaml:"offset"`
+	Limit    int             `json:"limit" yaml:"limit"`
+}
+
+// Audit outcomes
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEntry represents one mutating action in the append-only audit trail. Each entry carries
+// the hash of its predecessor, so that removed or altered entries break the chain.
+type AuditEntry struct {
+	Seq           int64             `json:"seq" yaml:"seq"`
+	Time          time.Time         `json:"time" yaml:"time"`
+	Action        string            `json:"action" yaml:"action"` // e.g. agent.create or memory_item.delete
+	Method        string            `json:"method" yaml:"method"`
+	Path          string            `json:"path" yaml:"path"`
+	Target        string            `json:"target,omitempty" yaml:"target,omitempty"` // ID of the resource acted on
+	Params        map[string]string `json:"params,omitempty" yaml:"params,omitempty"` // Route parameters
+	Principal     string            `json:"principal" yaml:"principal"`
+	SourceIP      string            `json:"source_ip" yaml:"source_ip"`
+	PayloadDigest string            `json:"payload_digest,omitempty" yaml:"payload_digest,omitempty"` // sha256 of the request body
+	PayloadBytes  int               `json:"payload_bytes" yaml:"payload_bytes"`
+	Status        int               `json:"status" yaml:"status"`
+	Outcome       string            `json:"outcome" yaml:"outcome"`
+	Error         string            `json:"error,omitempty" yaml:"error,omitempty"`
+	PrevHash      string            `json:"prev_hash" yaml:"prev_hash"`
+	Hash          string            `json:"hash" yaml:"hash"`
+}
+
+// AuditPage represents one page of audit entries, newest first
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries" yaml:"entries"`
+	Total   int          `json:"total" yaml:"total"`
+	Offset  int          `json:"offset" yaml:"offset"`
+	Limit   int          `json:"limit" yaml:"limit"`
+}
+
 // UsageStats represents usage statistics
 type UsageStats struct {
-	TotalAgents   int            `json:"total_agents" yaml:"total_agents"`
-	ActiveAgents  int            `json:"active_agents" yaml:"active_agents"`
-	TotalMeetings int            `json:"total_meetings" yaml:"total_meetings"`
-	UptimeSeconds float64        `json:"uptime_seconds" yaml:"uptime_seconds"`
-	APICalls      map[string]int `json:"api_calls" yaml:"api_calls"`
+	TotalAgents   int              `json:"total_agents" yaml:"total_agents"`
+	ActiveAgents  int              `json:"active_agents" yaml:"active_agents"`
+	TotalMeetings int              `json:"total_meetings" yaml:"total_meetings"`
+	UptimeSeconds float64          `json:"uptime_seconds" yaml:"uptime_seconds"`
+	APICalls      map[string]int   `json:"api_calls" yaml:"api_calls"`
+	BackendPool   BackendPoolUsage `json:"backend_pool" yaml:"backend_pool"`
+	Costs         CostUsage        `json:"costs" yaml:"costs"`
+}
+
+// LLMUsage represents the tokens consumed by one LLM call
+type LLMUsage struct {
+	Provider         LLMProvider `json:"provider" yaml:"provider"`
+	Model            string      `json:"model" yaml:"model"`
+	PromptTokens     int         `json:"prompt_tokens" yaml:"prompt_tokens"`
+	CompletionTokens int         `json:"completion_tokens" yaml:"completion_tokens"`
+}
+
+// CostTotals represents accumulated LLM usage and its cost
+type CostTotals struct {
+	Calls            int     `json:"calls" yaml:"calls"`
+	PromptTokens     int     `json:"prompt_tokens" yaml:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens" yaml:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd" yaml:"cost_usd"`
+	UnpricedCalls    int     `json:"unpriced_calls,omitempty" yaml:"unpriced_calls,omitempty"` // Calls to models missing from the price table
+}
+
+// CostUsage represents LLM cost broken down by agent, meeting, tenant and model
+type CostUsage struct {
+	Total     CostTotals            `json:"total" yaml:"total"`
+	ByAgent   map[string]CostTotals `json:"by_agent" yaml:"by_agent"`
+	ByMeeting map[string]CostTotals `json:"by_meeting" yaml:"by_meeting"`
+	ByTenant  map[string]CostTotals `json:"by_tenant" yaml:"by_tenant"`
+	ByModel   map[string]CostTotals `json:"by_model" yaml:"by_model"` // Keyed by provider/model
+}
+
+// BackendStatus represents the health of a joinly backend
+type BackendStatus string
+
+const (
+	BackendStatusUnknown   BackendStatus = "unknown"
+	BackendStatusHealthy   BackendStatus = "healthy"
+	BackendStatusUnhealthy BackendStatus = "unhealthy"
+)
+
+// JoinlyBackend represents a joinly MCP server in the backend pool
+type JoinlyBackend struct {
+	ID           string        `json:"id" yaml:"id"`
+	URL          string        `json:"url" yaml:"url"`
+	Status       BackendStatus `json:"status" yaml:"status"`
+	Static       bool          `json:"static" yaml:"static"`                         // Configured at startup rather than registered via API
+	AgentID      string        `json:"agent_id,omitempty" yaml:"agent_id,omitempty"` // Agent currently holding the lease
+	LeasedAt     *time.Time    `json:"leased_at,omitempty" yaml:"leased_at,omitempty"`
+	LastChecked  *time.Time    `json:"last_checked,omitempty" yaml:"last_checked,omitempty"`
+	LastError    *string       `json:"last_error,omitempty" yaml:"last_error,omitempty"`
+	RegisteredAt time.Time     `json:"registered_at" yaml:"registered_at"`
+}
+
+// BackendPoolUsage represents the occupancy of the joinly backend pool
+type BackendPoolUsage struct {
+	Total    int             `json:"total" yaml:"total"`
+	Healthy  int             `json:"healthy" yaml:"healthy"`
+	Leased   int             `json:"leased" yaml:"leased"`
+	Free     int             `json:"free" yaml:"free"`
+	Queued   int             `json:"queued" yaml:"queued"`
+	Backends []JoinlyBackend `json:"backends" yaml:"backends"`
+}
+
+// UtteranceLatency represents the latency breakdown of one utterance, in milliseconds
+type UtteranceLatency struct {
+	UtteranceID string    `json:"utterance_id" yaml:"utterance_id"`
+	TraceID     string    `json:"trace_id,omitempty" yaml:"trace_id,omitempty"`
+	Outcome     string    `json:"outcome" yaml:"outcome"` // delivered, no_reply, speak_failed, analyzed, cancelled
+	StartedAt   time.Time `json:"started_at" yaml:"started_at"`
+	DebounceMs  float64   `json:"debounce_ms" yaml:"debounce_ms"` // First segment until the debounce timer fired
+	QueueMs     float64   `json:"queue_ms" yaml:"queue_ms"`       // Debounce until the LLM request was sent
+	LLMMs       float64   `json:"llm_ms" yaml:"llm_ms"`
+	SpeakMs     float64   `json:"speak_ms" yaml:"speak_ms"`
+	TotalMs     float64   `json:"total_ms" yaml:"total_ms"`
+}
+
+// LatencyPercentiles represents the distribution of one latency stage, in milliseconds
+type LatencyPercentiles struct {
+	Count int     `json:"count" yaml:"count"`
+	P50   float64 `json:"p50" yaml:"p50"`
+	P90   float64 `json:"p90" yaml:"p90"`
+	P95   float64 `json:"p95" yaml:"p95"`
+	P99   float64 `json:"p99" yaml:"p99"`
+	Max   float64 `json:"max" yaml:"max"`
+}
+
+// AgentLatencyReport represents the per-stage latency of an agent's recent utterances
+type AgentLatencyReport struct {
+	AgentID string                        `json:"agent_id" yaml:"agent_id"`
+	Samples int                           `json:"samples" yaml:"samples"`
+	Stages  map[string]LatencyPercentiles `json:"stages" yaml:"stages"`
+	Recent  []UtteranceLatency            `json:"recent" yaml:"recent"`
+}
+
+// MemoryKind represents the kind of a long-term memory item
+type MemoryKind string
+
+const (
+	MemoryKindFact       MemoryKind = "fact"
+	MemoryKindDecision   MemoryKind = "decision"
+	MemoryKindActionItem MemoryKind = "action_item"
+)
+
+// MemoryItem represents something an agent identity remembers across meetings
+type MemoryItem struct {
+	ID           string     `json:"id" yaml:"id"`
+	Kind         MemoryKind `json:"kind" yaml:"kind"`
+	Content      string     `json:"content" yaml:"content"`
+	Owner        string     `json:"owner,omitempty" yaml:"owner,omitempty"` // Person responsible for an action item
+	Done         bool       `json:"done" yaml:"done"`                       // Whether an action item has been completed
+	Series       string     `json:"series,omitempty" yaml:"series,omitempty"`
+	MeetingURL   string     `json:"meeting_url,omitempty" yaml:"meeting_url,omitempty"`
+	Participants []string   `json:"participants,omitempty" yaml:"participants,omitempty"`
+	Source       string     `json:"source" yaml:"source"` // extracted or manual
+	CreatedAt    time.Time  `json:"created_at" yaml:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" yaml:"updated_at"`
+}
+
+// MemoryItemUpdate represents a partial update of a memory item; nil fields are left unchanged
+type MemoryItemUpdate struct {
+	Kind    *MemoryKind `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Content *string     `json:"content,omitempty" yaml:"content,omitempty"`
+	Owner   *string     `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Done    *bool       `json:"done,omitempty" yaml:"done,omitempty"`
+}
+
+// MemoryIdentity summarises the memory stored for one agent identity
+type MemoryIdentity struct {
+	Identity  string    `json:"identity" yaml:"identity"`
+	Items     int       `json:"items" yaml:"items"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+}
+
+// KnowledgeBaseInfo summarises a knowledge base
+type KnowledgeBaseInfo struct {
+	Name      string    `json:"name" yaml:"name"`
+	Documents int       `json:"documents" yaml:"documents"`
+	Chunks    int       `json:"chunks" yaml:"chunks"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+}
+
+// KnowledgeDocument represents a document uploaded to a knowledge base
+type KnowledgeDocument struct {
+	ID            string    `json:"id" yaml:"id"`
+	KnowledgeBase string    `json:"knowledge_base" yaml:"knowledge_base"`
+	Title         string    `json:"title" yaml:"title"`
+	Source        string    `json:"source,omitempty" yaml:"source,omitempty"` // Original file name
+	Format        string    `json:"format" yaml:"format"`                     // markdown, text or pdf_text
+	Characters    int       `json:"characters" yaml:"characters"`
+	Chunks        int       `json:"chunks" yaml:"chunks"`
+	Embedded      bool      `json:"embedded" yaml:"embedded"` // Whether the chunks have embeddings for semantic search
+	CreatedAt     time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// KnowledgeChunk represents an indexed piece of a knowledge base document
+type KnowledgeChunk struct {
+	Section string `json:"section,omitempty" yaml:"section,omitempty"`
+	Text    string `json:"text" yaml:"text"`
+}
+
+// KnowledgePassage represents a knowledge base passage retrieved for an utterance
+type KnowledgePassage struct {
+	Citation      int     `json:"citation" yaml:"citation"` // Number the LLM cites the passage by, e.g. [1]
+	KnowledgeBase string  `json:"knowledge_base" yaml:"knowledge_base"`
+	DocumentID    string  `json:"document_id" yaml:"document_id"`
+	Title         string  `json:"title" yaml:"title"`
+	Section       string  `json:"section,omitempty" yaml:"section,omitempty"`
+	Text          string  `json:"text" yaml:"text"`
+	Score         float64 `json:"score" yaml:"score"`
+}
+
+// SearchResult represents a transcript entry, summary or action item matching a search
+type SearchResult struct {
+	MeetingID     string    `json:"meeting_id" yaml:"meeting_id"`
+	AgentID       string    `json:"agent_id" yaml:"agent_id"`
+	MeetingURL    string    `json:"meeting_url" yaml:"meeting_url"`
+	Kind          string    `json:"kind" yaml:"kind"` // transcript, summary or action_item
+	Speaker       string    `json:"speaker,omitempty" yaml:"speaker,omitempty"`
+	Timestamp     time.Time `json:"timestamp" yaml:"timestamp"`
+	OffsetSeconds float64   `json:"offset_seconds" yaml:"offset_seconds"` // Time into the meeting
+	Snippet       string    `json:"snippet" yaml:"snippet"`               // HTML-escaped text with matches in <mark>
+	Score         float64   `json:"score" yaml:"score"`
+	Link          string    `json:"link" yaml:"link"` // API path of the meeting's analysis
+}
+
+// ReplayState represents the state of a transcript replay
+type ReplayState string
+
+const (
+	ReplayStateRunning   ReplayState = "running"
+	ReplayStateCompleted ReplayState = "completed"
+	ReplayStateFailed    ReplayState = "failed"
+	ReplayStateCancelled ReplayState = "cancelled"
+)
+
+// ReplayStatus represents the progress and outcome of replaying a recorded transcript into an agent
+type ReplayStatus struct {
+	AgentID    string      `json:"agent_id" yaml:"agent_id"`
+	State      ReplayState `json:"state" yaml:"state"`
+	Source     string      `json:"source,omitempty" yaml:"source,omitempty"` // Transcript file name
+	Speed      float64     `json:"speed" yaml:"speed"`                       // 1 is real time
+	Segments   int         `json:"segments" yaml:"segments"`
+	Replayed   int         `json:"replayed" yaml:"replayed"`
+	Skipped    int         `json:"skipped" yaml:"skipped"` // Agent segments of the recording, which are not replayed
+	Replies    []string    `json:"replies" yaml:"replies"` // What the agent said during the replay
+	StartedAt  time.Time   `json:"started_at" yaml:"started_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty" yaml:"finished_at,omitempty"`
+	Error      string      `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 // WebSocketMessage represents a WebSocket message
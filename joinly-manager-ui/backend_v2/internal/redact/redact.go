@@ -0,0 +1,323 @@
+// Package redact keeps personal data out of LLM prompts and stored meeting files. Detected
+// values are replaced by numbered tokens such as [EMAIL_1]. A Redactor remembers every value it
+// replaced, so a value always gets the same token and LLM answers can be rehydrated for display.
+package redact
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"joinly-manager/internal/models"
+)
+
+const (
+	// minNameLength keeps initials and short words from being learned as names
+	minNameLength = 2
+	// maxNames caps the participant names learned in one meeting
+	maxNames = 500
+)
+
+// Token kinds
+const (
+	kindEmail = "EMAIL"
+	kindPhone = "PHONE"
+	kindCard  = "CARD"
+	kindIBAN  = "IBAN"
+	kindIP    = "IP"
+	kindName  = "NAME"
+	kindTerm  = "TERM"
+)
+
+// detector finds one kind of personal data; valid filters false positives of the pattern
+type detector struct {
+	name    models.PIIDetector
+	kind    string
+	pattern *regexp.Regexp
+	valid   func(match string) bool
+}
+
+// detectors run in order, so that longer numbers are claimed before phone numbers
+var detectors = []detector{
+	{models.PIIEmail, kindEmail, regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`), nil},
+	{models.PIIIBAN, kindIBAN, regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`), validIBAN},
+	{models.PIICard, kindCard, regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`), validCard},
+	{models.PIIIP, kindIP, regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`), nil},
+	{models.PIIPhone, kindPhone, regexp.MustCompile(`(?:\+|\()?\b\d[\d ().\-]{5,18}\d\b`), validPhone},
+}
+
+// tokenPattern matches the tokens a Redactor hands out
+var tokenPattern = regexp.MustCompile(`\[(?:EMAIL|PHONE|CARD|IBAN|IP|NAME|TERM)_\d+\]`)
+
+// ValidDetector reports whether name is a known detector
+func ValidDetector(name models.PIIDetector) bool {
+	switch name {
+	case models.PIIEmail, models.PIIPhone, models.PIICard, models.PIIIBAN, models.PIIIP, models.PIINames:
+		return true
+	}
+	return false
+}
+
+// Redactor replaces personal data with tokens and restores it. The zero value is not usable;
+// a nil *Redactor leaves text unchanged, so callers need not check whether redaction is enabled.
+type Redactor struct {
+	enabled    map[models.PIIDetector]bool
+	persistRaw bool
+	ownName    string // The agent's own name is never redacted
+
+	mu          sync.Mutex
+	terms       map[string]string // Lowercased term or name -> token kind
+	names       int               // Learned names among the terms
+	termPattern *regexp.Regexp    // Matches every term, longest first (nil without terms)
+	tokens      map[string]string // Kind and normalised value -> token
+	values      map[string]string // Token -> value as first seen
+	counts      map[string]int    // Tokens handed out per kind
+}
+
+// New creates the redactor of an agent, or returns nil when the agent has no redaction policy
+func New(config *models.RedactionConfig, agentName string) *Redactor {
+	if config == nil {
+		return nil
+	}
+
+	r := &Redactor{
+		enabled:    make(map[models.PIIDetector]bool),
+		persistRaw: config.PersistRaw,
+		ownName:    strings.ToLower(strings.TrimSpace(agentName)),
+		terms:      make(map[string]string),
+		tokens:     make(map[string]string),
+		values:     make(map[string]string),
+		counts:     make(map[string]int),
+	}
+
+	if len(config.Detectors) == 0 {
+		for _, d := range detectors {
+			r.enabled[d.name] = true
+		}
+		r.enabled[models.PIINames] = true
+	}
+	for _, name := range config.Detectors {
+		r.enabled[name] = true
+	}
+
+	for _, term := range config.Terms {
+		if term = normaliseTerm(term); term != "" {
+			r.terms[term] = kindTerm
+		}
+	}
+	r.compileTermsUnsafe()
+
+	return r
+}
+
+// AddName teaches the redactor a participant's name, e.g. the speaker of a transcript segment.
+// Names are only redacted when the names detector is enabled.
+func (r *Redactor) AddName(name string) {
+	if r == nil || !r.enabled[models.PIINames] {
+		return
+	}
+	name = normaliseTerm(name)
+	if len([]rune(name)) < minNameLength || name == r.ownName || tokenPattern.MatchString(name) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, known := r.terms[name]; known || r.names >= maxNames {
+		return
+	}
+	r.terms[name] = kindName
+	r.names++
+	r.compileTermsUnsafe()
+}
+
+// Redact replaces the personal data in text with tokens
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range detectors {
+		if !r.enabled[d.name] {
+			continue
+		}
+		text = d.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+			return r.tokenUnsafe(d.kind, match, normaliseValue(d.kind, match))
+		})
+	}
+
+	if r.termPattern != nil {
+		text = r.termPattern.ReplaceAllStringFunc(text, func(match string) string {
+			term := normaliseTerm(match)
+			return r.tokenUnsafe(r.terms[term], match, term)
+		})
+	}
+	return text
+}
+
+// Rehydrate puts the values back in place of the tokens this redactor handed out. Unknown
+// tokens are left as they are.
+func (r *Redactor) Rehydrate(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		if value, known := r.values[token]; known {
+			return value
+		}
+		return token
+	})
+}
+
+// ForStorage returns text as it may be written to disk: redacted, unless the policy allows
+// persisting raw text
+func (r *Redactor) ForStorage(text string) string {
+	if r == nil || r.persistRaw {
+		return text
+	}
+	return r.Redact(text)
+}
+
+// PersistsRaw reports whether text is stored unredacted
+func (r *Redactor) PersistsRaw() bool {
+	return r == nil || r.persistRaw
+}
+
+// tokenUnsafe returns the token of a value, handing out the next one of its kind for a new
+// value (caller must hold lock)
+func (r *Redactor) tokenUnsafe(kind, value, key string) string {
+	key = kind + "\x00" + key
+	if token, known := r.tokens[key]; known {
+		return token
+	}
+	r.counts[kind]++
+	token := fmt.Sprintf("[%s_%d]", kind, r.counts[kind])
+	r.tokens[key] = token
+	r.values[token] = value
+	return token
+}
+
+// compileTermsUnsafe rebuilds the pattern matching every term (caller must hold lock)
+func (r *Redactor) compileTermsUnsafe() {
+	if len(r.terms) == 0 {
+		r.termPattern = nil
+		return
+	}
+
+	terms := make([]string, 0, len(r.terms))
+	for term := range r.terms {
+		terms = append(terms, term)
+	}
+	// Longest first, so that "Ada Lovelace" wins over "Ada"
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+
+	alternatives := make([]string, len(terms))
+	for i, term := range terms {
+		alternatives[i] = strings.ReplaceAll(regexp.QuoteMeta(term), " ", `\s+`)
+	}
+	r.termPattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+}
+
+// normaliseTerm lowercases a term and collapses its whitespace
+func normaliseTerm(term string) string {
+	return strings.ToLower(strings.Join(strings.Fields(term), " "))
+}
+
+// normaliseValue returns the form of a detected value that identifies it, so that differently
+// formatted numbers get the same token
+func normaliseValue(kind, value string) string {
+	switch kind {
+	case kindEmail:
+		return strings.ToLower(value)
+	case kindPhone, kindCard, kindIBAN:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToUpper(r)
+			}
+			return -1
+		}, value)
+	}
+	return value
+}
+
+// digits returns the decimal digits of s
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// validCard reports whether a number passes the Luhn check
+func validCard(match string) bool {
+	number := digits(match)
+	if len(number) < 13 || len(number) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validIBAN reports whether an account number passes the IBAN mod-97 checksum
+func validIBAN(match string) bool {
+	iban := strings.ReplaceAll(match, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	var numeric strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			numeric.WriteString(fmt.Sprint(int(r-'A') + 10))
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	value, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(value, big.NewInt(97)).Int64() == 1
+}
+
+// validPhone accepts 9 to 15 digits, or 7 when written in international form. Shorter runs
+// are more often amounts, dates or times.
+func validPhone(match string) bool {
+	count := len(digits(match))
+	if strings.HasPrefix(match, "+") {
+		return count >= 7 && count <= 15
+	}
+	return count >= 9 && count <= 15
+}
@@ -0,0 +1,90 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/models"
+)
+
+func TestRedactDetectsPersonalData(t *testing.T) {
+	r := New(&models.RedactionConfig{}, "Ada")
+
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{"Mail me at Jane.Doe@example.com please", "Mail me at [EMAIL_1] please"},
+		{"Call +44 20 7946 0958 or (555) 123-4567", "Call [PHONE_1] or [PHONE_2]"},
+		{"My card is 4111 1111 1111 1111", "My card is [CARD_1]"},
+		{"Transfer to DE89 3704 0044 0532 0130 00", "Transfer to [IBAN_1]"},
+		{"The server is at 192.168.10.4", "The server is at [IP_1]"},
+	}
+	for _, c := range cases {
+		if got := r.Redact(c.text); got != c.expected {
+			t.Errorf("Redact(%q) = %q, expected %q", c.text, got, c.expected)
+		}
+	}
+}
+
+func TestRedactLeavesOrdinaryNumbers(t *testing.T) {
+	r := New(&models.RedactionConfig{}, "Ada")
+
+	for _, text := range []string{
+		"We meet on 2026-03-01 at 10:30",
+		"The budget is 125,000 euros for 2027",
+		"Order 4111 1111 1111 1112 failed", // Fails the Luhn check
+		"Version 1.2.3 shipped",
+	} {
+		if got := r.Redact(text); got != text {
+			t.Errorf("Redact(%q) = %q, expected it unchanged", text, got)
+		}
+	}
+}
+
+func TestRedactTokensAreConsistentAndRehydrate(t *testing.T) {
+	r := New(&models.RedactionConfig{Terms: []string{"Project Falcon"}}, "Ada")
+	r.AddName("Bob Smith")
+	r.AddName("Ada") // The agent's own name is not redacted
+
+	text := "Bob Smith wrote bob@example.com about project  falcon. Ada replied to BOB@example.com."
+	redacted := r.Redact(text)
+	expected := "[NAME_1] wrote [EMAIL_1] about [TERM_1]. Ada replied to [EMAIL_1]."
+	if redacted != expected {
+		t.Fatalf("Redact = %q, expected %q", redacted, expected)
+	}
+
+	answer := "Thanks [NAME_1], I will write to [EMAIL_1] about [TERM_1] and [EMAIL_9]."
+	if got := r.Rehydrate(answer); got != "Thanks Bob Smith, I will write to bob@example.com about project  falcon and [EMAIL_9]." {
+		t.Errorf("Rehydrate = %q", got)
+	}
+}
+
+func TestRedactDetectorSelection(t *testing.T) {
+	r := New(&models.RedactionConfig{Detectors: []models.PIIDetector{models.PIIEmail}}, "Ada")
+	r.AddName("Bob") // Names are not enabled
+
+	got := r.Redact("Bob: ada@example.com, +1 202 555 0101")
+	if got != "Bob: [EMAIL_1], +1 202 555 0101" {
+		t.Errorf("Redact = %q", got)
+	}
+}
+
+func TestForStorage(t *testing.T) {
+	text := "Write to jane@example.com"
+
+	var disabled *Redactor
+	if disabled.ForStorage(text) != text || disabled.Redact(text) != text || !disabled.PersistsRaw() {
+		t.Error("Expected a nil redactor to leave text unchanged")
+	}
+
+	raw := New(&models.RedactionConfig{PersistRaw: true}, "Ada")
+	if raw.ForStorage(text) != text || !strings.Contains(raw.Redact(text), "[EMAIL_1]") {
+		t.Error("Expected raw persistence to redact prompts only")
+	}
+
+	redacted := New(&models.RedactionConfig{}, "Ada")
+	if got := redacted.ForStorage(text); got != "Write to [EMAIL_1]" {
+		t.Errorf("ForStorage = %q", got)
+	}
+}
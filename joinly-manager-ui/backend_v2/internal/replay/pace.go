@@ -0,0 +1,26 @@
+package replay
+
+import "time"
+
+// Delay returns how long to wait after saying prev before saying next at the given speed. When
+// the recording has a turn boundary between them (another speaker, or a pause of at least
+// turnGap), the wait lasts at least turnGap so that the agent sees the same turns even when the
+// meeting is accelerated.
+func Delay(prev, next Segment, speed float64, turnGap time.Duration) time.Duration {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	gap := next.Start - prev.Start
+	if gap < 0 {
+		gap = 0
+	}
+	delay := time.Duration(gap / speed * float64(time.Second))
+
+	silence := time.Duration((next.Start - prev.End) * float64(time.Second))
+	turnEnded := next.Speaker != prev.Speaker || silence >= turnGap
+	if turnEnded && delay < turnGap {
+		return turnGap
+	}
+	return delay
+}
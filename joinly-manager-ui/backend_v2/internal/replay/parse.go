@@ -0,0 +1,279 @@
+// Package replay reads recorded meeting transcripts so that they can be played back through an
+// agent's utterance pipeline as if the meeting were live.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transcript formats
+const (
+	FormatVTT      = "vtt"
+	FormatSRT      = "srt"
+	FormatJSONL    = "jsonl"    // One joinly segment object per line
+	FormatAnalysis = "analysis" // A saved analyst AnalysisData file
+)
+
+// maxSegments bounds the size of a replayed transcript
+const maxSegments = 20000
+
+var (
+	cueTiming    = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s*-->\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})`)
+	voiceTag     = regexp.MustCompile(`^<v((?:\.[^ .>]+)*)\s+([^>]+)>`)
+	markupTag    = regexp.MustCompile(`</?[^>]+>`)
+	speakerLabel = regexp.MustCompile(`^([\p{L}][\p{L}\p{N} .'_-]{0,40}?)(\s*\(agent\))?:\s+(.+)$`)
+)
+
+// Segment is one utterance of a recorded meeting
+type Segment struct {
+	Speaker   string  `json:"speaker"`
+	Text      string  `json:"text"`
+	Start     float64 `json:"start"` // Seconds from the start of the meeting
+	End       float64 `json:"end"`
+	Assistant bool    `json:"assistant,omitempty"` // Spoken by the agent in the recording
+}
+
+// Parse reads a transcript. format may be empty, in which case it is inferred from the file
+// name and then from the content.
+func Parse(name, format string, data []byte) ([]Segment, error) {
+	if format == "" {
+		format = DetectFormat(name, data)
+	}
+
+	var segments []Segment
+	var err error
+	switch strings.ToLower(format) {
+	case FormatVTT, FormatSRT:
+		segments, err = parseCues(data)
+	case FormatJSONL:
+		segments, err = parseJSONL(data)
+	case FormatAnalysis:
+		segments, err = parseAnalysis(data)
+	default:
+		return nil, fmt.Errorf("unsupported transcript format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("transcript contains no segments")
+	}
+	if len(segments) > maxSegments {
+		return nil, fmt.Errorf("transcript has %d segments, at most %d are supported", len(segments), maxSegments)
+	}
+	return segments, nil
+}
+
+// DetectFormat infers the format of a transcript from its file extension, or else its content
+func DetectFormat(name string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".vtt":
+		return FormatVTT
+	case ".srt":
+		return FormatSRT
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("WEBVTT")):
+		return FormatVTT
+	case len(trimmed) > 0 && trimmed[0] >= '0' && trimmed[0] <= '9':
+		return FormatSRT
+	case isAnalysis(trimmed):
+		return FormatAnalysis
+	}
+	return FormatJSONL
+}
+
+// isAnalysis reports whether data is a single JSON object with a transcript, as analysis files
+// are; JSONL segments have one object per line and no transcript field
+func isAnalysis(data []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["transcript"]
+	return ok
+}
+
+// parseCues reads WebVTT and SRT cues. Speakers come from VTT voice tags or a "Name: " prefix.
+func parseCues(data []byte) ([]Segment, error) {
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(data, []byte("\ufeff"))), "\r\n", "\n")
+
+	var segments []Segment
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+
+		// Find the timing line; anything before it is a cue number or identifier
+		timing := -1
+		for i, line := range lines {
+			if cueTiming.MatchString(line) {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			continue // Header, NOTE or STYLE block
+		}
+
+		match := cueTiming.FindStringSubmatch(lines[timing])
+		start, err := parseTimestamp(match[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimestamp(match[2])
+		if err != nil {
+			return nil, err
+		}
+
+		speaker, body, assistant := cueText(lines[timing+1:])
+		if body == "" {
+			continue
+		}
+
+		// Consecutive cues of one speaker are usually a single utterance split for display
+		if n := len(segments); n > 0 && speaker != "" && segments[n-1].Speaker == speaker && segments[n-1].Assistant == assistant && start-segments[n-1].End < 1 {
+			segments[n-1].Text += " " + body
+			segments[n-1].End = end
+			continue
+		}
+		segments = append(segments, Segment{Speaker: speaker, Text: body, Start: start, End: end, Assistant: assistant})
+	}
+	return segments, nil
+}
+
+// cueText joins the text lines of a cue and extracts its speaker. Agent replies are flagged by
+// an "agent" voice class or an "(agent)" label, as transcript exports write them.
+func cueText(lines []string) (speaker, text string, assistant bool) {
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if speaker == "" {
+			if match := voiceTag.FindStringSubmatch(line); match != nil {
+				speaker = html.UnescapeString(strings.TrimSpace(match[2]))
+				assistant = strings.Contains(match[1]+".", ".agent.")
+			}
+		}
+		if line = strings.TrimSpace(markupTag.ReplaceAllString(line, "")); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	text = html.UnescapeString(strings.Join(parts, " "))
+
+	if speaker == "" {
+		if match := speakerLabel.FindStringSubmatch(text); match != nil {
+			speaker, text, assistant = strings.TrimSpace(match[1]), match[3], match[2] != ""
+		}
+	}
+	return speaker, text, assistant
+}
+
+// parseTimestamp converts an SRT or VTT timestamp such as 01:02:03,456 or 02:03.456 to seconds
+func parseTimestamp(value string) (float64, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	fields := strings.Split(value, ":")
+
+	var seconds float64
+	for _, field := range fields {
+		number, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		seconds = seconds*60 + number
+	}
+	return seconds, nil
+}
+
+// parseJSONL reads joinly segments, one JSON object per line
+func parseJSONL(data []byte) ([]Segment, error) {
+	var segments []Segment
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var segment struct {
+			Text    string  `json:"text"`
+			Start   float64 `json:"start"`
+			End     float64 `json:"end"`
+			Speaker string  `json:"speaker"`
+			Role    string  `json:"role"`
+		}
+		if err := json.Unmarshal(raw, &segment); err != nil {
+			return nil, fmt.Errorf("invalid segment on line %d: %w", line, err)
+		}
+		if strings.TrimSpace(segment.Text) == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Speaker:   segment.Speaker,
+			Text:      strings.TrimSpace(segment.Text),
+			Start:     segment.Start,
+			End:       segment.End,
+			Assistant: segment.Role == "assistant",
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read segments: %w", err)
+	}
+	return segments, nil
+}
+
+// parseAnalysis reads the transcript of a saved analysis, timed relative to its first entry
+func parseAnalysis(data []byte) ([]Segment, error) {
+	var analysis struct {
+		Transcript []struct {
+			Timestamp time.Time `json:"timestamp"`
+			Speaker   string    `json:"speaker"`
+			Text      string    `json:"text"`
+			IsAgent   bool      `json:"is_agent"`
+		} `json:"transcript"`
+	}
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return nil, fmt.Errorf("invalid analysis file: %w", err)
+	}
+
+	var segments []Segment
+	var origin time.Time
+	for _, entry := range analysis.Transcript {
+		if strings.TrimSpace(entry.Text) == "" {
+			continue
+		}
+		if origin.IsZero() {
+			origin = entry.Timestamp
+		}
+		start := entry.Timestamp.Sub(origin).Seconds()
+		if start < 0 {
+			start = 0
+		}
+		segments = append(segments, Segment{
+			Speaker:   entry.Speaker,
+			Text:      strings.TrimSpace(entry.Text),
+			Start:     start,
+			End:       start + speakingTime(entry.Text),
+			Assistant: entry.IsAgent,
+		})
+	}
+	return segments, nil
+}
+
+// speakingTime estimates how long text takes to say, at roughly three words per second
+func speakingTime(text string) float64 {
+	return float64(len(strings.Fields(text))) / 3
+}
@@ -0,0 +1,103 @@
+package replay
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFormats(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected []Segment
+	}{
+		{
+			name: "meeting.vtt",
+			data: "WEBVTT\n\nNOTE exported\n\n1\n00:00:01.000 --> 00:00:03.500\n<v Bob>When is the release?</v>\n\n" +
+				"00:00:04.000 --> 00:00:06.000\n<v Ada>Friday.\n\n00:00:06.500 --> 00:00:08.000\n<v Ada>After the review.\n",
+			expected: []Segment{
+				{Speaker: "Bob", Text: "When is the release?", Start: 1, End: 3.5},
+				{Speaker: "Ada", Text: "Friday. After the review.", Start: 4, End: 8},
+			},
+		},
+		{
+			name: "",
+			data: "1\r\n00:01:02,500 --> 00:01:04,000\r\nCarol: Let's start\r\nthe retro.\r\n\r\n2\r\n00:01:05,000 --> 00:01:06,000\r\nNo speaker here\r\n",
+			expected: []Segment{
+				{Speaker: "Carol", Text: "Let's start the retro.", Start: 62.5, End: 64},
+				{Speaker: "", Text: "No speaker here", Start: 65, End: 66},
+			},
+		},
+		{
+			name: "segments.txt",
+			data: `{"text": "Hi all", "start": 0.5, "end": 1.2, "speaker": "Bob", "role": "participant"}` + "\n\n" +
+				`{"text": "Hello Bob", "start": 2, "end": 3, "speaker": "Ada", "role": "assistant"}`,
+			expected: []Segment{
+				{Speaker: "Bob", Text: "Hi all", Start: 0.5, End: 1.2},
+				{Speaker: "Ada", Text: "Hello Bob", Start: 2, End: 3, Assistant: true},
+			},
+		},
+		{
+			name: "meeting_analysis_agent_1.json",
+			data: `{"meeting_id": "agent_1", "transcript": [
+				{"timestamp": "2026-01-05T10:00:00Z", "speaker": "Bob", "text": "Ship it on Friday", "is_agent": false},
+				{"timestamp": "2026-01-05T10:00:30Z", "speaker": "Ada", "text": "Noted", "is_agent": true}
+			]}`,
+			expected: []Segment{
+				{Speaker: "Bob", Text: "Ship it on Friday", Start: 0, End: 4.0 / 3},
+				{Speaker: "Ada", Text: "Noted", Start: 30, End: 30 + 1.0/3, Assistant: true},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		segments, err := Parse(c.name, "", []byte(c.data))
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.name, err)
+			continue
+		}
+		if len(segments) != len(c.expected) {
+			t.Errorf("Parse(%q) = %+v, expected %+v", c.name, segments, c.expected)
+			continue
+		}
+		for i := range segments {
+			if segments[i] != c.expected[i] {
+				t.Errorf("Parse(%q)[%d] = %+v, expected %+v", c.name, i, segments[i], c.expected[i])
+			}
+		}
+	}
+}
+
+func TestParseRejectsEmptyAndUnknown(t *testing.T) {
+	if _, err := Parse("empty.vtt", "", []byte("WEBVTT\n")); err == nil {
+		t.Error("Expected an error for a transcript without cues")
+	}
+	if _, err := Parse("meeting.doc", "docx", []byte("x")); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+	if _, err := Parse("segments.jsonl", "", []byte("{not json}")); err == nil {
+		t.Error("Expected an error for invalid JSONL")
+	}
+}
+
+func TestDelayKeepsTurnBoundaries(t *testing.T) {
+	turnGap := 3 * time.Second
+	bob := Segment{Speaker: "Bob", Start: 0, End: 2}
+
+	// Accelerated, but another speaker still gets a separate turn
+	if got := Delay(bob, Segment{Speaker: "Ada", Start: 4, End: 5}, 10, turnGap); got != turnGap {
+		t.Errorf("Expected a turn gap between speakers, got %v", got)
+	}
+	// A short pause of the same speaker stays within the turn
+	if got := Delay(bob, Segment{Speaker: "Bob", Start: 2.5, End: 4}, 10, turnGap); got != 250*time.Millisecond {
+		t.Errorf("Expected the scaled gap, got %v", got)
+	}
+	// A long pause ended the turn in the recording
+	if got := Delay(bob, Segment{Speaker: "Bob", Start: 10, End: 11}, 10, turnGap); got != turnGap {
+		t.Errorf("Expected a turn gap after a long pause, got %v", got)
+	}
+	// Real time keeps the recorded gap
+	if got := Delay(bob, Segment{Speaker: "Ada", Start: 8, End: 9}, 1, turnGap); got != 8*time.Second {
+		t.Errorf("Expected the recorded gap, got %v", got)
+	}
+}
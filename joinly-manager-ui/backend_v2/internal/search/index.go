@@ -0,0 +1,177 @@
+// Package search indexes the meeting analyses saved by analyst agents, so that transcripts,
+// summaries and action items of every meeting can be searched. Each analysis file gets its own
+// positional inverted index, which is rebuilt when the file changes on disk.
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"joinly-manager/internal/storage"
+)
+
+// Kinds of indexed documents
+const (
+	KindTranscript = "transcript"
+	KindSummary    = "summary"
+	KindActionItem = "action_item"
+)
+
+// analysisFile is the part of a saved analysis that is searchable
+type analysisFile struct {
+	MeetingID   string    `json:"meeting_id"`
+	MeetingURL  string    `json:"meeting_url"`
+	StartTime   time.Time `json:"start_time"`
+	LastUpdated time.Time `json:"last_updated"`
+	Transcript  []struct {
+		Timestamp time.Time `json:"timestamp"`
+		Speaker   string    `json:"speaker"`
+		Text      string    `json:"text"`
+	} `json:"transcript"`
+	Summary     string `json:"summary"`
+	ActionItems []struct {
+		Description string    `json:"description"`
+		Assignee    string    `json:"assignee"`
+		CreatedAt   time.Time `json:"created_at"`
+	} `json:"action_items"`
+}
+
+// document is one searchable piece of a meeting
+type document struct {
+	kind      string
+	speaker   string
+	text      string
+	timestamp time.Time
+	offset    float64 // Seconds into the meeting
+	tokens    []token
+}
+
+// meeting is an indexed analysis file
+type meeting struct {
+	id        string
+	url       string
+	start     time.Time
+	modTime   time.Time
+	size      int64
+	docs      []document
+	postings  map[string]map[int][]int // term -> document -> token positions
+	termCount int                      // Tokens over all documents
+}
+
+// Index searches the analysis files in a directory
+type Index struct {
+	dir   string
+	vault *storage.Vault
+
+	mu       sync.Mutex
+	meetings map[string]*meeting // File path -> indexed meeting
+}
+
+// NewIndex creates an index over the *.json analysis files in dir, decrypted by vault when it
+// is not nil. Files are read on the first search and re-read whenever they change.
+func NewIndex(dir string, vault *storage.Vault) *Index {
+	return &Index{dir: dir, vault: vault, meetings: make(map[string]*meeting)}
+}
+
+// refreshUnsafe re-indexes new and changed files and forgets deleted ones (caller must hold lock)
+func (idx *Index) refreshUnsafe() error {
+	files, err := filepath.Glob(filepath.Join(idx.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list analysis files: %w", err)
+	}
+
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file] = true
+
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if indexed := idx.meetings[file]; indexed != nil && indexed.modTime.Equal(info.ModTime()) && indexed.size == info.Size() {
+			continue
+		}
+
+		indexed, err := idx.loadMeeting(file)
+		if err != nil {
+			// Files are rewritten while agents run; a half-written file is picked up on the next search
+			logrus.Debugf("Skipping analysis file %s: %v", file, err)
+			delete(idx.meetings, file)
+			continue
+		}
+		indexed.modTime, indexed.size = info.ModTime(), info.Size()
+		idx.meetings[file] = indexed
+	}
+
+	for file := range idx.meetings {
+		if !present[file] {
+			delete(idx.meetings, file)
+		}
+	}
+	return nil
+}
+
+// loadMeeting reads and indexes one analysis file
+func (idx *Index) loadMeeting(path string) (*meeting, error) {
+	data, err := idx.vault.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var analysis analysisFile
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return nil, err
+	}
+
+	m := &meeting{
+		id:       analysis.MeetingID,
+		url:      analysis.MeetingURL,
+		start:    analysis.StartTime,
+		postings: make(map[string]map[int][]int),
+	}
+	if m.id == "" {
+		m.id = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+
+	for _, entry := range analysis.Transcript {
+		m.add(document{kind: KindTranscript, speaker: entry.Speaker, text: entry.Text, timestamp: entry.Timestamp})
+	}
+	if analysis.Summary != "" {
+		m.add(document{kind: KindSummary, text: analysis.Summary, timestamp: analysis.LastUpdated})
+	}
+	for _, item := range analysis.ActionItems {
+		m.add(document{kind: KindActionItem, speaker: item.Assignee, text: item.Description, timestamp: item.CreatedAt})
+	}
+	return m, nil
+}
+
+// add indexes a document of the meeting
+func (m *meeting) add(doc document) {
+	doc.text = strings.TrimSpace(doc.text)
+	if doc.text == "" {
+		return
+	}
+	if !m.start.IsZero() && !doc.timestamp.IsZero() {
+		doc.offset = doc.timestamp.Sub(m.start).Seconds()
+		if doc.offset < 0 {
+			doc.offset = 0
+		}
+	}
+	doc.tokens = tokenize(doc.text)
+
+	i := len(m.docs)
+	m.docs = append(m.docs, doc)
+	m.termCount += len(doc.tokens)
+	for position, tok := range doc.tokens {
+		if m.postings[tok.term] == nil {
+			m.postings[tok.term] = make(map[int][]int)
+		}
+		m.postings[tok.term][i] = append(m.postings[tok.term][i], position)
+	}
+}
@@ -0,0 +1,375 @@
+package search
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"joinly-manager/internal/models"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+const (
+	// DefaultLimit is the number of results returned when a query sets no limit
+	DefaultLimit = 20
+	// MaxLimit caps the results of one query
+	MaxLimit = 100
+	// snippetChars is the length of the text shown around the first match
+	snippetChars = 240
+)
+
+// stopwords match nearly every document; they only count inside quoted phrases
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "can": true, "do": true, "does": true, "for": true, "from": true, "how": true, "i": true,
+	"if": true, "in": true, "is": true, "it": true, "me": true, "of": true, "on": true, "or": true,
+	"our": true, "so": true, "that": true, "the": true, "this": true, "to": true, "was": true, "we": true,
+	"what": true, "when": true, "where": true, "which": true, "who": true, "why": true, "will": true,
+	"with": true, "you": true, "your": true,
+}
+
+// token is a lowercased term and where it appears in the original text
+type token struct {
+	term       string
+	start, end int // Byte offsets
+}
+
+// Query is a search with optional filters. Text holds terms and "quoted phrases", all of which
+// must match.
+type Query struct {
+	Text       string
+	Speaker    string // Speaker of a transcript entry or assignee of an action item
+	MeetingURL string
+	AgentID    string
+	Kind       string    // transcript, summary or action_item
+	From, To   time.Time // Zero for an open range
+	Limit      int
+}
+
+// match is a document matching a query
+type match struct {
+	meeting *meeting
+	doc     int
+	spans   [][2]int // Matched token ranges
+	score   float64
+}
+
+// tokenize splits text into lowercased letter and digit runs
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			tokens = append(tokens, token{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// parseQuery splits query text into phrases; a bare word is a phrase of one term. Bare
+// stopwords are dropped.
+func parseQuery(text string) [][]string {
+	var phrases [][]string
+	for i, part := range strings.Split(text, `"`) {
+		quoted := i%2 == 1
+		if quoted {
+			if phrase := terms(part); len(phrase) > 0 {
+				phrases = append(phrases, phrase)
+			}
+			continue
+		}
+		for _, term := range terms(part) {
+			if !stopwords[term] {
+				phrases = append(phrases, []string{term})
+			}
+		}
+	}
+	return phrases
+}
+
+// terms returns the terms of text
+func terms(text string) []string {
+	tokens := tokenize(text)
+	result := make([]string, len(tokens))
+	for i, tok := range tokens {
+		result[i] = tok.term
+	}
+	return result
+}
+
+// Search returns the best matching documents and the total number of matches
+func (idx *Index) Search(query Query) ([]models.SearchResult, int, error) {
+	phrases := parseQuery(query.Text)
+	if len(phrases) == 0 {
+		return nil, 0, fmt.Errorf("query has no searchable terms")
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if err := idx.refreshUnsafe(); err != nil {
+		return nil, 0, err
+	}
+
+	// Corpus statistics for BM25 span every meeting, filtered or not
+	totalDocs, totalTerms := 0, 0
+	frequencies := make(map[string]int)
+	for _, m := range idx.meetings {
+		totalDocs += len(m.docs)
+		totalTerms += m.termCount
+		for _, phrase := range phrases {
+			for _, term := range phrase {
+				frequencies[term] += len(m.postings[term])
+			}
+		}
+	}
+	if totalDocs == 0 {
+		return []models.SearchResult{}, 0, nil
+	}
+	avgLength := float64(totalTerms) / float64(totalDocs)
+
+	var matches []match
+	for _, m := range idx.meetings {
+		if query.MeetingURL != "" && m.url != query.MeetingURL {
+			continue
+		}
+		if query.AgentID != "" && m.id != query.AgentID {
+			continue
+		}
+		for _, candidate := range m.candidates(phrases[0]) {
+			doc := m.docs[candidate]
+			if !query.accepts(doc) {
+				continue
+			}
+			spans, ok := m.matchPhrases(candidate, phrases)
+			if !ok {
+				continue
+			}
+			matches = append(matches, match{
+				meeting: m,
+				doc:     candidate,
+				spans:   spans,
+				score:   m.score(candidate, phrases, frequencies, totalDocs, avgLength),
+			})
+		}
+	}
+
+	// Best first; ties go to the newest document
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if ta, tb := a.meeting.docs[a.doc].timestamp, b.meeting.docs[b.doc].timestamp; !ta.Equal(tb) {
+			return ta.After(tb)
+		}
+		if a.meeting.id != b.meeting.id {
+			return a.meeting.id < b.meeting.id
+		}
+		return a.doc < b.doc
+	})
+
+	total := len(matches)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	results := make([]models.SearchResult, len(matches))
+	for i, found := range matches {
+		doc := found.meeting.docs[found.doc]
+		results[i] = models.SearchResult{
+			MeetingID:     found.meeting.id,
+			AgentID:       found.meeting.id,
+			MeetingURL:    found.meeting.url,
+			Kind:          doc.kind,
+			Speaker:       doc.speaker,
+			Timestamp:     doc.timestamp,
+			OffsetSeconds: math.Round(doc.offset*10) / 10,
+			Snippet:       snippet(doc, found.spans),
+			Score:         math.Round(found.score*1000) / 1000,
+			Link:          fmt.Sprintf("/agents/%s/analysis", found.meeting.id),
+		}
+	}
+	return results, total, nil
+}
+
+// accepts reports whether a document passes the filters of the query
+func (q Query) accepts(doc document) bool {
+	if q.Kind != "" && doc.kind != q.Kind {
+		return false
+	}
+	if q.Speaker != "" && !strings.EqualFold(doc.speaker, q.Speaker) {
+		return false
+	}
+	if !q.From.IsZero() && doc.timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !doc.timestamp.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// candidates returns the documents containing the first term of a phrase
+func (m *meeting) candidates(phrase []string) []int {
+	var docs []int
+	for doc := range m.postings[phrase[0]] {
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// matchPhrases returns the token ranges where each phrase occurs in a document, or false when
+// one of the phrases does not occur
+func (m *meeting) matchPhrases(doc int, phrases [][]string) ([][2]int, bool) {
+	var spans [][2]int
+	for _, phrase := range phrases {
+		found := false
+		for _, position := range m.postings[phrase[0]][doc] {
+			if m.phraseAt(doc, phrase, position) {
+				spans = append(spans, [2]int{position, position + len(phrase)})
+				found = true
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return spans, true
+}
+
+// phraseAt reports whether a phrase occurs at a token position of a document
+func (m *meeting) phraseAt(doc int, phrase []string, position int) bool {
+	tokens := m.docs[doc].tokens
+	if position+len(phrase) > len(tokens) {
+		return false
+	}
+	for k, term := range phrase {
+		if tokens[position+k].term != term {
+			return false
+		}
+	}
+	return true
+}
+
+// score returns the BM25 score of a document for the distinct terms of the query
+func (m *meeting) score(doc int, phrases [][]string, frequencies map[string]int, totalDocs int, avgLength float64) float64 {
+	n := float64(totalDocs)
+	length := float64(len(m.docs[doc].tokens))
+
+	var score float64
+	seen := make(map[string]bool)
+	for _, phrase := range phrases {
+		for _, term := range phrase {
+			if seen[term] || stopwords[term] {
+				continue
+			}
+			seen[term] = true
+
+			df := float64(frequencies[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			frequency := float64(len(m.postings[term][doc]))
+			norm := bm25K1 * (1 - bm25B + bm25B*length/avgLength)
+			score += idf * frequency * (bm25K1 + 1) / (frequency + norm)
+		}
+	}
+	return score
+}
+
+// snippet returns the text around the first match, HTML-escaped, with every match in <mark>
+func snippet(doc document, spans [][2]int) string {
+	text := doc.text
+
+	// Byte ranges of the matches, merged where phrases overlap
+	ranges := make([][2]int, 0, len(spans))
+	for _, span := range spans {
+		ranges = append(ranges, [2]int{doc.tokens[span[0]].start, doc.tokens[span[1]-1].end})
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1] {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	// Window of snippetChars around the first match, cut at word boundaries
+	from, to := 0, len(text)
+	if len(text) > snippetChars && len(merged) > 0 {
+		from = wordBoundary(text, merged[0][0]-snippetChars/3, false)
+		to = wordBoundary(text, from+snippetChars, true)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	position := from
+	for _, r := range merged {
+		if r[1] <= from || r[0] >= to {
+			continue
+		}
+		start, end := max(r[0], position), min(r[1], to)
+		b.WriteString(html.EscapeString(text[position:start]))
+		b.WriteString("<mark>" + html.EscapeString(text[start:end]) + "</mark>")
+		position = end
+	}
+	b.WriteString(html.EscapeString(text[position:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// wordBoundary moves offset to the nearest space before it, or after it when forward is set,
+// staying within text
+func wordBoundary(text string, offset int, forward bool) int {
+	if offset <= 0 {
+		return 0
+	}
+	if offset >= len(text) {
+		return len(text)
+	}
+	if forward {
+		if i := strings.IndexByte(text[offset:], ' '); i >= 0 {
+			return offset + i
+		}
+		return len(text)
+	}
+	if i := strings.LastIndexByte(text[:offset], ' '); i >= 0 {
+		return i + 1
+	}
+	// Without a space, step back to the start of a UTF-8 character
+	for offset > 0 && !utf8.RuneStart(text[offset]) {
+		offset--
+	}
+	return offset
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAnalysis saves an analysis file like analyst agents do
+func writeAnalysis(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestIndex(t *testing.T) (*Index, string) {
+	dir := t.TempDir()
+	writeAnalysis(t, dir, "meeting_analysis_agent_1_1.json", `{
+		"meeting_id": "agent_1", "meeting_url": "https://meet.google.com/abc-defg-hij",
+		"start_time": "2026-01-05T10:00:00Z", "last_updated": "2026-01-05T10:30:00Z",
+		"transcript": [
+			{"timestamp": "2026-01-05T10:01:00Z", "speaker": "Bob", "text": "The release is blocked by the database migration."},
+			{"timestamp": "2026-01-05T10:02:30Z", "speaker": "Carol", "text": "I will fix the migration of the database today."}
+		],
+		"summary": "The team discussed the release and the database migration.",
+		"action_items": [{"description": "Fix the database migration", "assignee": "Carol", "created_at": "2026-01-05T10:03:00Z"}]
+	}`)
+	writeAnalysis(t, dir, "meeting_analysis_agent_2_2.json", `{
+		"meeting_id": "agent_2", "meeting_url": "https://zoom.us/j/123",
+		"start_time": "2026-02-01T09:00:00Z", "last_updated": "2026-02-01T09:20:00Z",
+		"transcript": [{"timestamp": "2026-02-01T09:05:00Z", "speaker": "Bob", "text": "Budget review & database <costs>."}]
+	}`)
+	writeAnalysis(t, dir, "broken.json", `{"transcript": [`)
+	return NewIndex(dir, nil), dir
+}
+
+func TestSearchPhrasesAndFilters(t *testing.T) {
+	idx, _ := newTestIndex(t)
+
+	cases := []struct {
+		query    Query
+		expected []string // speaker/kind of each result, in any order
+	}{
+		{Query{Text: "database"}, []string{"Bob/transcript", "Carol/transcript", "/summary", "Carol/action_item", "Bob/transcript"}},
+		{Query{Text: `"database migration"`}, []string{"Bob/transcript", "/summary", "Carol/action_item"}},
+		{Query{Text: `"database migration" release`}, []string{"Bob/transcript", "/summary"}},
+		{Query{Text: "database", Speaker: "carol"}, []string{"Carol/transcript", "Carol/action_item"}},
+		{Query{Text: "database", MeetingURL: "https://zoom.us/j/123"}, []string{"Bob/transcript"}},
+		{Query{Text: "database", AgentID: "agent_1", Kind: KindSummary}, []string{"/summary"}},
+		{Query{Text: "database", From: time.Date(2026, 1, 5, 10, 2, 0, 0, time.UTC), To: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)}, []string{"Carol/transcript", "/summary", "Carol/action_item"}},
+		{Query{Text: "deployment"}, nil},
+	}
+	for _, c := range cases {
+		results, total, err := idx.Search(c.query)
+		if err != nil {
+			t.Errorf("Search(%+v): %v", c.query, err)
+			continue
+		}
+		var got []string
+		for _, result := range results {
+			got = append(got, result.Speaker+"/"+result.Kind)
+		}
+		if total != len(c.expected) || !sameElements(got, c.expected) {
+			t.Errorf("Search(%+v) = %v (total %d), expected %v", c.query, got, total, c.expected)
+		}
+	}
+
+	if _, _, err := idx.Search(Query{Text: "the and of"}); err == nil {
+		t.Error("Expected an error for a query of stopwords only")
+	}
+}
+
+func TestSearchSnippetsAndLinks(t *testing.T) {
+	idx, _ := newTestIndex(t)
+
+	results, _, err := idx.Search(Query{Text: `"the database" costs`})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Expected no document with both, got %+v, %v", results, err)
+	}
+
+	results, _, err = idx.Search(Query{Text: "database costs"})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected one result, got %+v, %v", results, err)
+	}
+	result := results[0]
+	if expected := "Budget review &amp; <mark>database</mark> &lt;<mark>costs</mark>&gt;."; result.Snippet != expected {
+		t.Errorf("Snippet = %q, expected %q", result.Snippet, expected)
+	}
+	if result.OffsetSeconds != 300 || result.Link != "/agents/agent_2/analysis" || result.MeetingURL != "https://zoom.us/j/123" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// Long texts are cut around the first match
+	long := strings.Repeat("filler words here ", 40) + "the rollback plan " + strings.Repeat("more filler ", 40)
+	doc := document{text: long, tokens: tokenize(long)}
+	spans := [][2]int{}
+	for i, tok := range doc.tokens {
+		if tok.term == "rollback" {
+			spans = append(spans, [2]int{i, i + 1})
+		}
+	}
+	got := snippet(doc, spans)
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<mark>rollback</mark>") || len(got) > snippetChars+40 {
+		t.Errorf("unexpected snippet %q", got)
+	}
+}
+
+func TestSearchPicksUpChangedFiles(t *testing.T) {
+	idx, dir := newTestIndex(t)
+
+	if _, total, _ := idx.Search(Query{Text: "rollback"}); total != 0 {
+		t.Fatalf("Expected no match yet, got %d", total)
+	}
+
+	writeAnalysis(t, dir, "meeting_analysis_agent_3_3.json", `{"meeting_id": "agent_3", "transcript": [{"speaker": "Dan", "text": "Prepare a rollback."}]}`)
+	if _, total, _ := idx.Search(Query{Text: "rollback"}); total != 1 {
+		t.Errorf("Expected the new file to be searched, got %d matches", total)
+	}
+
+	os.Remove(filepath.Join(dir, "meeting_analysis_agent_3_3.json"))
+	if _, total, _ := idx.Search(Query{Text: "rollback"}); total != 0 {
+		t.Errorf("Expected the deleted file to be forgotten, got %d matches", total)
+	}
+}
+
+// sameElements reports whether a and b hold the same strings, in any order
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		counts[s]--
+		if counts[s] < 0 {
+			return false
+		}
+	}
+	return true
+}
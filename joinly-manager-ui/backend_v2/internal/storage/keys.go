@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Key providers built in
+const (
+	ProviderNone  = "none"
+	ProviderLocal = "local"
+)
+
+// KeyProvider wraps the data keys of envelopes with a key-encryption key it never hands out,
+// like a KMS does. Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// WrapKey encrypts a data key with the current key-encryption key and returns that key's ID
+	WrapKey(dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped with the key-encryption key keyID
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// KeyRotator is a KeyProvider that can replace its current key-encryption key. Earlier keys
+// stay available for unwrapping.
+type KeyRotator interface {
+	KeyProvider
+	RotateKey() (keyID string, err error)
+}
+
+// ProviderSettings configures a key provider
+type ProviderSettings struct {
+	KeyFile string // Key file of the local provider
+	KeyURI  string // Key reference for plugins, e.g. a KMS key ARN
+}
+
+// ProviderFactory creates a key provider from its settings
+type ProviderFactory func(settings ProviderSettings) (KeyProvider, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]ProviderFactory{
+		ProviderLocal: func(settings ProviderSettings) (KeyProvider, error) {
+			return OpenLocalKeys(settings.KeyFile)
+		},
+	}
+)
+
+// RegisterKeyProvider makes a key provider plugin available under name, e.g. from an init
+// function of a KMS integration
+func RegisterKeyProvider(name string, factory ProviderFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[name] = factory
+}
+
+// NewKeyProvider creates the key provider registered under name. ProviderNone and "" return
+// nil, which disables encryption.
+func NewKeyProvider(name string, settings ProviderSettings) (KeyProvider, error) {
+	if name == "" || name == ProviderNone {
+		return nil, nil
+	}
+
+	factoriesMu.RLock()
+	factory := factories[name]
+	factoriesMu.RUnlock()
+
+	if factory == nil {
+		return nil, fmt.Errorf("unknown encryption key provider %q", name)
+	}
+	return factory(settings)
+}
+
+// localKey is one key-encryption key of a key file
+type localKey struct {
+	ID        string    `json:"id"`
+	Key       []byte    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// keyFile is the content of a local key file
+type keyFile struct {
+	Current string     `json:"current"`
+	Keys    []localKey `json:"keys"`
+}
+
+// LocalKeys keeps key-encryption keys in a file readable only by the manager's user
+type LocalKeys struct {
+	path string
+
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+	created map[string]time.Time
+}
+
+// OpenLocalKeys loads a key file, creating it with a new key when it does not exist
+func OpenLocalKeys(path string) (*LocalKeys, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no encryption key file configured")
+	}
+
+	k := &LocalKeys{path: path, keys: make(map[string][]byte), created: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if _, err := k.RotateKey(); err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("encryption key file %s must not be accessible by other users (mode %04o)", path, info.Mode().Perm())
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption key file: %w", err)
+	}
+	for _, key := range file.Keys {
+		if len(key.Key) != 32 {
+			return nil, fmt.Errorf("encryption key %s is not a 256-bit key", key.ID)
+		}
+		k.keys[key.ID] = key.Key
+		k.created[key.ID] = key.CreatedAt
+	}
+	if k.keys[file.Current] == nil {
+		return nil, fmt.Errorf("encryption key file has no current key")
+	}
+	k.current = file.Current
+	return k, nil
+}
+
+// CurrentKeyID returns the ID of the key new data keys are wrapped with
+func (k *LocalKeys) CurrentKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// WrapKey encrypts a data key with the current key
+func (k *LocalKeys) WrapKey(dataKey []byte) (string, []byte, error) {
+	k.mu.RLock()
+	keyID, key := k.current, k.keys[k.current]
+	k.mu.RUnlock()
+
+	wrapped, err := seal(key, dataKey)
+	return keyID, wrapped, err
+}
+
+// UnwrapKey decrypts a data key wrapped with the key keyID
+func (k *LocalKeys) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	k.mu.RLock()
+	key := k.keys[keyID]
+	k.mu.RUnlock()
+
+	if key == nil {
+		return nil, fmt.Errorf("unknown encryption key %s", keyID)
+	}
+	return open(key, wrapped)
+}
+
+// RotateKey adds a new key, makes it current and saves the key file
+func (k *LocalKeys) RotateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now().UTC()
+	keyID := fmt.Sprintf("key_%s", now.Format("20060102T150405.000000000"))
+	k.keys[keyID] = key
+	k.created[keyID] = now
+	previous := k.current
+	k.current = keyID
+
+	if err := k.saveUnsafe(); err != nil {
+		delete(k.keys, keyID)
+		delete(k.created, keyID)
+		k.current = previous
+		return "", err
+	}
+	return keyID, nil
+}
+
+// saveUnsafe writes the key file with owner-only permissions (caller must hold lock)
+func (k *LocalKeys) saveUnsafe() error {
+	file := keyFile{Current: k.current}
+	for id, key := range k.keys {
+		file.Keys = append(file.Keys, localKey{ID: id, Key: key, CreatedAt: k.created[id]})
+	}
+	sort.Slice(file.Keys, func(i, j int) bool { return file.Keys[i].CreatedAt.Before(file.Keys[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode encryption key file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), dirMode); err != nil {
+		return fmt.Errorf("failed to create encryption key directory: %w", err)
+	}
+	if err := writeAtomic(k.path, data); err != nil {
+		return fmt.Errorf("failed to write encryption key file: %w", err)
+	}
+	return nil
+}
+
+// seal encrypts plaintext with AES-256-GCM; the nonce is prepended to the ciphertext
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts what seal encrypted
+func open(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// newAEAD creates an AES-GCM cipher for a 256-bit key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultEncryptsFiles(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := OpenLocalKeys(filepath.Join(dir, "keys", "master.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := NewVault(keys)
+
+	dataDir := filepath.Join(dir, "analysis")
+	if err := vault.MkdirAll(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dataDir, "meeting.json")
+	plaintext := []byte(`{"summary": "Bob shares his card number"}`)
+	if err := vault.WriteFile(path, plaintext); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := os.ReadFile(path)
+	if !IsSealed(raw) || bytes.Contains(raw, []byte("card number")) {
+		t.Errorf("Expected an encrypted file, got %s", raw)
+	}
+	for file, mode := range map[string]os.FileMode{dataDir: dirMode, path: fileMode, keys.path: fileMode} {
+		if info, err := os.Stat(file); err != nil || info.Mode().Perm() != mode {
+			t.Errorf("Expected mode %04o for %s, got %v (%v)", mode, file, info.Mode().Perm(), err)
+		}
+	}
+
+	got, err := vault.ReadFile(path)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("ReadFile = %s, %v", got, err)
+	}
+
+	// The key file is reloaded; a plain vault cannot read encrypted files but still reads plaintext
+	reloaded, err := OpenLocalKeys(keys.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := NewVault(reloaded).ReadFile(path); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("ReadFile after reload = %s, %v", got, err)
+	}
+	var plain *Vault
+	if _, err := plain.ReadFile(path); err == nil {
+		t.Error("Expected a vault without keys to refuse encrypted files")
+	}
+	legacy := filepath.Join(dataDir, "legacy.json")
+	os.WriteFile(legacy, []byte(`{}`), 0644)
+	if got, err := vault.ReadFile(legacy); err != nil || string(got) != `{}` {
+		t.Errorf("Expected plaintext files to be read as they are, got %s, %v", got, err)
+	}
+}
+
+func TestRotateKeyAndRewrap(t *testing.T) {
+	dir := t.TempDir()
+	keys, _ := OpenLocalKeys(filepath.Join(dir, "master.json"))
+	vault := NewVault(keys)
+
+	path := filepath.Join(dir, "data", "memory.json")
+	vault.MkdirAll(filepath.Dir(path))
+	if err := vault.WriteFile(path, []byte("remember this")); err != nil {
+		t.Fatal(err)
+	}
+	// An append-only file sealed line by line, after a line written before encryption
+	line, err := vault.SealLine([]byte(`{"action":"agent.create"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	linesPath := filepath.Join(dir, "data", "audit.jsonl")
+	os.WriteFile(linesPath, append([]byte("{\"action\":\"plain\"}\n"), append(line, '\n')...), 0o600)
+	oldKey := keys.CurrentKeyID()
+
+	newKey, err := keys.RotateKey()
+	if err != nil || newKey == oldKey {
+		t.Fatalf("RotateKey = %s, %v", newKey, err)
+	}
+
+	// Files wrapped with the old key stay readable until they are re-wrapped
+	if got, err := vault.ReadFile(path); err != nil || string(got) != "remember this" {
+		t.Errorf("ReadFile with the old key = %s, %v", got, err)
+	}
+	count, err := vault.RewrapDir(dir)
+	if err != nil || count != 2 {
+		t.Fatalf("RewrapDir = %d, %v", count, err)
+	}
+
+	raw, _ := os.ReadFile(path)
+	var env envelope
+	json.Unmarshal(raw[len(envelopeMagic):], &env)
+	if env.KeyID != newKey {
+		t.Errorf("Expected the data key to be wrapped with %s, got %s", newKey, env.KeyID)
+	}
+	if got, err := vault.ReadFile(path); err != nil || string(got) != "remember this" {
+		t.Errorf("ReadFile after rewrap = %s, %v", got, err)
+	}
+
+	raw, _ = os.ReadFile(linesPath)
+	lines := bytes.Split(bytes.TrimSpace(raw), []byte("\n"))
+	if len(lines) != 2 || string(lines[0]) != `{"action":"plain"}` {
+		t.Fatalf("Expected the plaintext line to be kept, got %s", raw)
+	}
+	sealed, _ := sealedLine(lines[1])
+	json.Unmarshal(sealed[len(envelopeMagic):], &env)
+	if env.KeyID != newKey {
+		t.Errorf("Expected the sealed line to be wrapped with %s, got %s", newKey, env.KeyID)
+	}
+	if got, err := vault.OpenLine(lines[1]); err != nil || string(got) != `{"action":"agent.create"}` {
+		t.Errorf("OpenLine after rewrap = %s, %v", got, err)
+	}
+}
+
+func TestKeyProviderRegistry(t *testing.T) {
+	if keys, err := NewKeyProvider(ProviderNone, ProviderSettings{}); keys != nil || err != nil {
+		t.Errorf("Expected no provider for %q, got %v, %v", ProviderNone, keys, err)
+	}
+	if _, err := NewKeyProvider("vault-kms", ProviderSettings{}); err == nil {
+		t.Error("Expected an error for an unregistered provider")
+	}
+
+	local, _ := OpenLocalKeys(filepath.Join(t.TempDir(), "master.json"))
+	RegisterKeyProvider("test-kms", func(settings ProviderSettings) (KeyProvider, error) {
+		if settings.KeyURI != "kms://meetings" {
+			t.Errorf("Unexpected settings %+v", settings)
+		}
+		return local, nil
+	})
+	if keys, err := NewKeyProvider("test-kms", ProviderSettings{KeyURI: "kms://meetings"}); err != nil || keys != local {
+		t.Errorf("NewKeyProvider = %v, %v", keys, err)
+	}
+}
+
+func TestOpenLocalKeysRejectsOpenPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.json")
+	if _, err := OpenLocalKeys(path); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(path, 0644)
+	if _, err := OpenLocalKeys(path); err == nil {
+		t.Error("Expected a key file readable by other users to be rejected")
+	}
+}
@@ -0,0 +1,285 @@
+// Package storage persists the manager's data with owner-only permissions and, when a key
+// provider is configured, envelope encryption: every write is encrypted with a fresh data key,
+// which is stored next to the ciphertext wrapped by the provider's key-encryption key. Rotating
+// the key-encryption key therefore only re-wraps data keys and never re-encrypts the data.
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// dirMode and fileMode keep persisted data readable by the manager's user only
+	dirMode  = 0o700
+	fileMode = 0o600
+)
+
+// envelopeMagic starts every encrypted file and sealed value
+var envelopeMagic = []byte("JMENC1\n")
+
+// envelope is an encrypted value together with its wrapped data key
+type envelope struct {
+	KeyID      string `json:"key_id"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Ciphertext []byte `json:"ciphertext"` // AES-256-GCM, nonce first
+}
+
+// Vault reads and writes the manager's data at rest. A nil *Vault, or one without a key
+// provider, stores plaintext with owner-only permissions.
+type Vault struct {
+	keys KeyProvider
+}
+
+// NewVault creates a vault encrypting with keys; nil keys disable encryption
+func NewVault(keys KeyProvider) *Vault {
+	return &Vault{keys: keys}
+}
+
+// Encrypted reports whether the vault encrypts what it writes
+func (v *Vault) Encrypted() bool {
+	return v != nil && v.keys != nil
+}
+
+// Seal encrypts a value under a fresh data key, e.g. a file or a database row. Without a key
+// provider the value is returned as it is.
+func (v *Vault) Seal(plaintext []byte) ([]byte, error) {
+	if !v.Encrypted() {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	ciphertext, err := seal(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	keyID, wrapped, err := v.keys.WrapKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return encodeEnvelope(envelope{KeyID: keyID, WrappedKey: wrapped, Ciphertext: ciphertext})
+}
+
+// Open decrypts a sealed value. Plaintext values, e.g. written before encryption was enabled,
+// are returned as they are.
+func (v *Vault) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if !v.Encrypted() {
+		return nil, fmt.Errorf("data is encrypted but no encryption key provider is configured")
+	}
+
+	env, err := decodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := v.keys.UnwrapKey(env.KeyID, env.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return open(dataKey, env.Ciphertext)
+}
+
+// Rewrap re-wraps the data key of a sealed value with the current key-encryption key. The
+// ciphertext is kept. Plaintext values are returned as they are.
+func (v *Vault) Rewrap(data []byte) ([]byte, error) {
+	if !IsSealed(data) || !v.Encrypted() {
+		return data, nil
+	}
+
+	env, err := decodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := v.keys.UnwrapKey(env.KeyID, env.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	if env.KeyID, env.WrappedKey, err = v.keys.WrapKey(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return encodeEnvelope(env)
+}
+
+// SealLine encrypts one line of an append-only file, e.g. a JSONL record. Sealed lines are
+// base64 encoded so they never contain a newline; without a key provider the line is returned
+// as it is.
+func (v *Vault) SealLine(line []byte) ([]byte, error) {
+	if !v.Encrypted() {
+		return line, nil
+	}
+	sealed, err := v.Seal(line)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// OpenLine decrypts a line written by SealLine. Plaintext lines are returned as they are.
+func (v *Vault) OpenLine(line []byte) ([]byte, error) {
+	sealed, ok := sealedLine(line)
+	if !ok {
+		return line, nil
+	}
+	return v.Open(sealed)
+}
+
+// sealedLine returns the envelope of a line written by SealLine
+func sealedLine(line []byte) ([]byte, bool) {
+	// JSON records start with a brace, which base64 never does
+	if len(line) == 0 || line[0] == '{' {
+		return nil, false
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(line))
+	if err != nil || !IsSealed(sealed) {
+		return nil, false
+	}
+	return sealed, true
+}
+
+// IsSealed reports whether data is an encrypted envelope
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, envelopeMagic)
+}
+
+// MkdirAll creates a data directory readable by the manager's user only. Existing directories
+// are restricted too.
+func (v *Vault) MkdirAll(dir string) error {
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return err
+	}
+	return os.Chmod(dir, dirMode)
+}
+
+// WriteFile seals data and writes it atomically with owner-only permissions
+func (v *Vault) WriteFile(path string, data []byte) error {
+	sealed, err := v.Seal(data)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, sealed)
+}
+
+// ReadFile reads a file written by WriteFile, or a plaintext file
+func (v *Vault) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return v.Open(data)
+}
+
+// RewrapDir re-wraps the data keys of every encrypted file below dir with the current
+// key-encryption key, e.g. after a key rotation, and returns how many files were re-wrapped.
+// Append-only files whose lines are sealed one by one are re-wrapped line by line.
+func (v *Vault) RewrapDir(dir string) (int, error) {
+	if !v.Encrypted() {
+		return 0, fmt.Errorf("no encryption key provider is configured")
+	}
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	rewrapped := 0
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sealed := IsSealed(data)
+		if sealed {
+			data, err = v.Rewrap(data)
+		} else {
+			data, sealed, err = v.rewrapLines(data)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if !sealed {
+			return nil
+		}
+		if err := writeAtomic(path, data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		rewrapped++
+		return nil
+	})
+	return rewrapped, err
+}
+
+// rewrapLines re-wraps every line of data written by SealLine and reports whether there was any
+func (v *Vault) rewrapLines(data []byte) ([]byte, bool, error) {
+	lines := bytes.Split(data, []byte("\n"))
+	found := false
+	for i, line := range lines {
+		sealed, ok := sealedLine(line)
+		if !ok {
+			continue
+		}
+		found = true
+		rewrapped, err := v.Rewrap(sealed)
+		if err != nil {
+			return nil, false, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		lines[i] = []byte(base64.StdEncoding.EncodeToString(rewrapped))
+	}
+	return bytes.Join(lines, []byte("\n")), found, nil
+}
+
+// encodeEnvelope serialises an envelope behind the magic prefix
+func encodeEnvelope(env envelope) ([]byte, error) {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode envelope: %w", err)
+	}
+	return append(append([]byte{}, envelopeMagic...), body...), nil
+}
+
+// decodeEnvelope parses an envelope written by encodeEnvelope
+func decodeEnvelope(data []byte) (envelope, error) {
+	var env envelope
+	if err := json.Unmarshal(data[len(envelopeMagic):], &env); err != nil {
+		return env, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	if env.KeyID == "" || len(env.WrappedKey) == 0 {
+		return env, fmt.Errorf("failed to decode envelope: missing data key")
+	}
+	return env, nil
+}
+
+// writeAtomic writes data to a temporary file first so a crash never leaves a truncated file
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, fileMode); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing temporary file
+	if err := os.Chmod(tmp, fileMode); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
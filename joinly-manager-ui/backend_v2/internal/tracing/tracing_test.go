@@ -0,0 +1,17 @@
+package tracing
+
+import "testing"
+
+func TestTracesURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:4318":            "http://localhost:4318/v1/traces",
+		"https://collector.example.com/":   "https://collector.example.com/v1/traces",
+		"https://gateway.example.com/otlp": "https://gateway.example.com/otlp/v1/traces",
+		"localhost:4318":                   "http://localhost:4318/v1/traces",
+	}
+	for endpoint, want := range tests {
+		if got := TracesURL(endpoint); got != want {
+			t.Errorf("TracesURL(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"joinly-manager/internal/models"
+)
+
+// Stages of an utterance, used as span names and latency report keys
+const (
+	StageDebounce  = "debounce"
+	StageQueue     = "queue"
+	StageRetrieval = "retrieval"
+	StageLLM       = "llm"
+	StageSpeak     = "speak"
+	StageTotal     = "total"
+)
+
+// Utterance follows one consolidated utterance from the first transcript segment to the
+// spoken reply. It owns the root span; stages are recorded as child spans.
+type Utterance struct {
+	ID string
+
+	span     trace.Span
+	onFinish func(models.UtteranceLatency)
+
+	mu        sync.Mutex
+	arrivedAt time.Time
+	stages    map[string][2]time.Time // stage -> start, end
+	finished  bool
+}
+
+type utteranceKey struct{}
+
+// StartUtterance starts the root span of an utterance whose first segment arrived at arrivedAt.
+// onFinish, if set, receives the latency breakdown when the utterance finishes.
+func StartUtterance(ctx context.Context, id string, arrivedAt time.Time, onFinish func(models.UtteranceLatency), attrs ...attribute.KeyValue) (context.Context, *Utterance) {
+	attrs = append(attrs, attribute.String("utterance.id", id))
+	ctx, span := Tracer().Start(ctx, "utterance", trace.WithTimestamp(arrivedAt), trace.WithAttributes(attrs...))
+
+	u := &Utterance{
+		ID:        id,
+		span:      span,
+		onFinish:  onFinish,
+		arrivedAt: arrivedAt,
+		stages:    make(map[string][2]time.Time),
+	}
+
+	return context.WithValue(ctx, utteranceKey{}, u), u
+}
+
+// UtteranceFromContext returns the utterance carried by the context, or nil
+func UtteranceFromContext(ctx context.Context) *Utterance {
+	u, _ := ctx.Value(utteranceKey{}).(*Utterance)
+	return u
+}
+
+// ContextWithUtterance attaches an utterance (and its root span) to another context
+func ContextWithUtterance(ctx context.Context, u *Utterance) context.Context {
+	if u == nil {
+		return ctx
+	}
+	ctx = trace.ContextWithSpan(ctx, u.span)
+	return context.WithValue(ctx, utteranceKey{}, u)
+}
+
+// RecordStage records a stage that has already completed, such as the debounce wait
+func (u *Utterance) RecordStage(name string, start, end time.Time) {
+	if u == nil {
+		return
+	}
+
+	ctx := trace.ContextWithSpan(context.Background(), u.span)
+	_, span := Tracer().Start(ctx, name, trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(end))
+
+	u.mu.Lock()
+	u.stages[name] = [2]time.Time{start, end}
+	u.mu.Unlock()
+}
+
+// StartStage starts a child span of the current span; the returned function ends it.
+// When the context carries an utterance, the stage timing is added to its breakdown.
+func StartStage(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+	u := UtteranceFromContext(ctx)
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		if u != nil {
+			u.mu.Lock()
+			u.stages[name] = [2]time.Time{start, time.Now()}
+			u.mu.Unlock()
+		}
+	}
+}
+
+// Finish ends the root span and reports the latency breakdown. Only the first call has an effect.
+func (u *Utterance) Finish(outcome string) {
+	if u == nil {
+		return
+	}
+
+	u.mu.Lock()
+	if u.finished {
+		u.mu.Unlock()
+		return
+	}
+	u.finished = true
+	latency := u.latencyUnsafe(outcome, time.Now())
+	u.mu.Unlock()
+
+	u.span.SetAttributes(attribute.String("utterance.outcome", outcome))
+	u.span.End()
+
+	if u.onFinish != nil {
+		u.onFinish(latency)
+	}
+}
+
+// latencyUnsafe builds the latency breakdown (caller must hold mu)
+func (u *Utterance) latencyUnsafe(outcome string, finishedAt time.Time) models.UtteranceLatency {
+	latency := models.UtteranceLatency{
+		UtteranceID: u.ID,
+		Outcome:     outcome,
+		StartedAt:   u.arrivedAt,
+		TotalMs:     milliseconds(finishedAt.Sub(u.arrivedAt)),
+	}
+	if spanContext := u.span.SpanContext(); spanContext.HasTraceID() {
+		latency.TraceID = spanContext.TraceID().String()
+	}
+
+	if debounce, ok := u.stages[StageDebounce]; ok {
+		latency.DebounceMs = milliseconds(debounce[1].Sub(debounce[0]))
+		if llm, ok := u.stages[StageLLM]; ok {
+			latency.QueueMs = milliseconds(llm[0].Sub(debounce[1]))
+		}
+	}
+	if llm, ok := u.stages[StageLLM]; ok {
+		latency.LLMMs = milliseconds(llm[1].Sub(llm[0]))
+	}
+	if speak, ok := u.stages[StageSpeak]; ok {
+		latency.SpeakMs = milliseconds(speak[1].Sub(speak[0]))
+	}
+
+	return latency
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
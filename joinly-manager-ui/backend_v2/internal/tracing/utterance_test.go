@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"joinly-manager/internal/models"
+)
+
+func TestUtteranceLatencyBreakdown(t *testing.T) {
+	var reported []models.UtteranceLatency
+	arrived := time.Now().Add(-3 * time.Second)
+
+	ctx, utterance := StartUtterance(context.Background(), "abc", arrived, func(latency models.UtteranceLatency) {
+		reported = append(reported, latency)
+	})
+	utterance.RecordStage(StageDebounce, arrived, arrived.Add(2*time.Second))
+
+	// Stages started from a derived context land on the same utterance
+	ctx = ContextWithUtterance(context.Background(), UtteranceFromContext(ctx))
+	_, endLLM := StartStage(ctx, StageLLM)
+	time.Sleep(10 * time.Millisecond)
+	endLLM(nil)
+
+	utterance.Finish("delivered")
+	utterance.Finish("cancelled") // Ignored: already finished
+
+	if len(reported) != 1 {
+		t.Fatalf("Expected exactly one latency report, got %d", len(reported))
+	}
+
+	latency := reported[0]
+	if latency.UtteranceID != "abc" || latency.Outcome != "delivered" {
+		t.Errorf("Unexpected report identity: %+v", latency)
+	}
+	if latency.DebounceMs != 2000 {
+		t.Errorf("Expected 2000ms debounce, got %v", latency.DebounceMs)
+	}
+	if latency.QueueMs < 900 || latency.LLMMs < 10 {
+		t.Errorf("Expected queue >= 900ms and llm >= 10ms, got %+v", latency)
+	}
+	if latency.SpeakMs != 0 {
+		t.Errorf("Expected no speak stage, got %v", latency.SpeakMs)
+	}
+	if latency.TotalMs < 3000 {
+		t.Errorf("Expected total >= 3000ms, got %v", latency.TotalMs)
+	}
+}
+
+func TestNilUtteranceIsSafe(t *testing.T) {
+	var utterance *Utterance
+	utterance.RecordStage(StageDebounce, time.Now(), time.Now())
+	utterance.Finish("skipped")
+
+	if UtteranceFromContext(context.Background()) != nil {
+		t.Error("Expected no utterance on a bare context")
+	}
+	_, end := StartStage(context.Background(), StageSpeak)
+	end(nil)
+}
@@ -0,0 +1,139 @@
+// Package transcript renders meeting transcripts as subtitles, JSON lines, Markdown or plain text.
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"joinly-manager/internal/models"
+)
+
+// Export formats
+const (
+	FormatSRT      = "srt"
+	FormatVTT      = "vtt"
+	FormatJSONL    = "jsonl" // joinly segment objects, one per line
+	FormatMarkdown = "md"
+	FormatText     = "txt"
+)
+
+// minCueSeconds is the duration given to segments without a usable end time
+const minCueSeconds = 1.0
+
+var contentTypes = map[string]string{
+	FormatSRT:      "application/x-subrip; charset=utf-8",
+	FormatVTT:      "text/vtt; charset=utf-8",
+	FormatJSONL:    "application/x-ndjson",
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatText:     "text/plain; charset=utf-8",
+}
+
+// jsonSegment is a segment as joinly serialises it
+type jsonSegment struct {
+	Text    string  `json:"text"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker"`
+	Role    string  `json:"role"` // participant or assistant
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// ContentType returns the MIME type of a format, or "" for unsupported formats
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Render writes segments in the given format. title heads Markdown transcripts.
+func Render(format, title string, segments []models.MeetingSegment) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case FormatSRT:
+		for i, segment := range segments {
+			fmt.Fprintf(&buf, "%d\n%s --> %s\n%s: %s\n\n", i+1,
+				cueTime(segment.Start, ","), cueTime(cueEnd(segment), ","), label(segment), segment.Text)
+		}
+	case FormatVTT:
+		buf.WriteString("WEBVTT\n\n")
+		for _, segment := range segments {
+			voice := "v"
+			if segment.IsAgent {
+				voice = "v.agent"
+			}
+			fmt.Fprintf(&buf, "%s --> %s\n<%s %s>%s\n\n",
+				cueTime(segment.Start, "."), cueTime(cueEnd(segment), "."), voice, vttEscaper.Replace(speaker(segment)), vttEscaper.Replace(segment.Text))
+		}
+	case FormatJSONL:
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		for _, segment := range segments {
+			role := "participant"
+			if segment.IsAgent {
+				role = "assistant"
+			}
+			if err := encoder.Encode(jsonSegment{segment.Text, segment.Start, segment.End, segment.Speaker, role}); err != nil {
+				return nil, fmt.Errorf("failed to encode segment: %w", err)
+			}
+		}
+	case FormatMarkdown:
+		fmt.Fprintf(&buf, "# Transcript: %s\n\n", title)
+		if len(segments) == 0 {
+			buf.WriteString("_No speech recorded yet._\n")
+		}
+		for _, segment := range segments {
+			fmt.Fprintf(&buf, "[%s] **%s:** %s\n\n", clockTime(segment.Start), label(segment), segment.Text)
+		}
+	case FormatText:
+		for _, segment := range segments {
+			fmt.Fprintf(&buf, "[%s] %s: %s\n", clockTime(segment.Start), label(segment), segment.Text)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported transcript format")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// speaker returns the speaker of a segment, with a placeholder for unknown speakers
+func speaker(segment models.MeetingSegment) string {
+	switch {
+	case segment.Speaker != "":
+		return segment.Speaker
+	case segment.IsAgent:
+		return "Agent"
+	default:
+		return "Participant"
+	}
+}
+
+// label returns the speaker label of a segment, flagging agent replies
+func label(segment models.MeetingSegment) string {
+	if segment.IsAgent {
+		return speaker(segment) + " (agent)"
+	}
+	return speaker(segment)
+}
+
+// cueEnd returns the end of a segment, at least minCueSeconds after its start
+func cueEnd(segment models.MeetingSegment) float64 {
+	if segment.End <= segment.Start {
+		return segment.Start + minCueSeconds
+	}
+	return segment.End
+}
+
+// cueTime formats seconds as a subtitle timestamp, e.g. 01:02:03,456 with a "," separator
+func cueTime(seconds float64, separator string) string {
+	millis := int64(math.Round(math.Max(seconds, 0) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", millis/3600000, millis/60000%60, millis/1000%60, separator, millis%1000)
+}
+
+// clockTime formats seconds as HH:MM:SS
+func clockTime(seconds float64) string {
+	total := int64(math.Max(seconds, 0))
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
@@ -0,0 +1,68 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/replay"
+)
+
+var meeting = []models.MeetingSegment{
+	{Start: 1.5, End: 3.25, Speaker: "Bob", Text: "When is the release?"},
+	{Start: 4, End: 4, Speaker: "Ada", Text: "Friday, after <QA> & review.", IsAgent: true},
+	{Start: 3725, End: 3727, Text: "Thanks."},
+}
+
+func TestRender(t *testing.T) {
+	cases := map[string]string{
+		FormatSRT: "1\n00:00:01,500 --> 00:00:03,250\nBob: When is the release?\n\n" +
+			"2\n00:00:04,000 --> 00:00:05,000\nAda (agent): Friday, after <QA> & review.\n\n" +
+			"3\n01:02:05,000 --> 01:02:07,000\nParticipant: Thanks.\n\n",
+		FormatVTT: "WEBVTT\n\n00:00:01.500 --> 00:00:03.250\n<v Bob>When is the release?\n\n" +
+			"00:00:04.000 --> 00:00:05.000\n<v.agent Ada>Friday, after &lt;QA&gt; &amp; review.\n\n" +
+			"01:02:05.000 --> 01:02:07.000\n<v Participant>Thanks.\n\n",
+		FormatJSONL: `{"text":"When is the release?","start":1.5,"end":3.25,"speaker":"Bob","role":"participant"}` + "\n" +
+			`{"text":"Friday, after <QA> & review.","start":4,"end":4,"speaker":"Ada","role":"assistant"}` + "\n" +
+			`{"text":"Thanks.","start":3725,"end":3727,"speaker":"","role":"participant"}` + "\n",
+		FormatMarkdown: "# Transcript: Standup\n\n[00:00:01] **Bob:** When is the release?\n\n" +
+			"[00:00:04] **Ada (agent):** Friday, after <QA> & review.\n\n[01:02:05] **Participant:** Thanks.\n\n",
+		FormatText: "[00:00:01] Bob: When is the release?\n[00:00:04] Ada (agent): Friday, after <QA> & review.\n" +
+			"[01:02:05] Participant: Thanks.\n",
+	}
+	for format, expected := range cases {
+		content, err := Render(format, "Standup", meeting)
+		if err != nil {
+			t.Errorf("Render(%s): %v", format, err)
+			continue
+		}
+		if string(content) != expected {
+			t.Errorf("Render(%s) =\n%s\nexpected\n%s", format, content, expected)
+		}
+	}
+
+	if _, err := Render("docx", "Standup", meeting); err == nil || ContentType("docx") != "" {
+		t.Error("Expected docx to be unsupported")
+	}
+}
+
+func TestExportsReplay(t *testing.T) {
+	for _, format := range []string{FormatSRT, FormatVTT, FormatJSONL} {
+		content, err := Render(format, "Standup", meeting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments, err := replay.Parse("", "", content)
+		if err != nil {
+			t.Errorf("%s export does not parse: %v", format, err)
+			continue
+		}
+		if len(segments) != 3 || segments[0].Speaker != "Bob" || segments[1].Speaker != "Ada" || !segments[1].Assistant || segments[0].Assistant {
+			t.Errorf("%s export replays as %+v", format, segments)
+		}
+		// SRT readers drop tags as markup, so only the escaped rest of the text must survive
+		if !strings.Contains(segments[1].Text, "& review") {
+			t.Errorf("%s export lost text: %q", format, segments[1].Text)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+// Package validation checks agent configurations before they reach the agent manager,
+// so that problems surface as field-level API errors instead of failing later inside
+// the agent goroutine.
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"joinly-manager/internal/interjection"
+	"joinly-manager/internal/knowledge"
+	"joinly-manager/internal/memory"
+	"joinly-manager/internal/models"
+	"joinly-manager/internal/redact"
+)
+
+// meetingPlatforms mirrors the URL patterns of the joinly browser platforms
+var meetingPlatforms = map[string]*regexp.Regexp{
+	"google_meet": regexp.MustCompile(`^(?:www\.)?meet\.google\.com$`),
+	"teams":       regexp.MustCompile(`^(?:[a-z0-9-]+\.)?(?:teams\.microsoft\.com|teams\.live\.com)$`),
+	"zoom":        regexp.MustCompile(`^(?:[a-z0-9-]+\.)?zoom\.us$`),
+}
+
+// headerName matches a valid HTTP header field name (RFC 9110 token)
+var headerName = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// llmAPIKeys lists the environment variables that satisfy each provider (any one is enough)
+var llmAPIKeys = map[models.LLMProvider][]string{
+	models.LLMProviderOpenAI:    {"OPENAI_API_KEY"},
+	models.LLMProviderAnthropic: {"ANTHROPIC_API_KEY"},
+	models.LLMProviderGoogle:    {"GOOGLE_API_KEY", "GOOGLE_APPLICATION_CREDENTIALS"},
+}
+
+// sttLanguages lists the languages each STT provider can transcribe
+var sttLanguages = map[models.STTProvider][]string{
+	models.STTProviderWhisper: {
+		"af", "am", "ar", "as", "az", "ba", "be", "bg", "bn", "bo", "br", "bs", "ca", "cs", "cy",
+		"da", "de", "el", "en", "es", "et", "eu", "fa", "fi", "fo", "fr", "gl", "gu", "ha", "haw",
+		"he", "hi", "hr", "ht", "hu", "hy", "id", "is", "it", "ja", "jw", "ka", "kk", "km", "kn",
+		"ko", "la", "lb", "ln", "lo", "lt", "lv", "mg", "mi", "mk", "ml", "mn", "mr", "ms", "mt",
+		"my", "ne", "nl", "nn", "no", "oc", "pa", "pl", "ps", "pt", "ro", "ru", "sa", "sd", "si",
+		"sk", "sl", "sn", "so", "sq", "sr", "su", "sv", "sw", "ta", "te", "tg", "th", "tk", "tl",
+		"tr", "tt", "uk", "ur", "uz", "vi", "yi", "yo", "yue", "zh",
+	},
+	models.STTProviderDeepgram: {
+		"bg", "ca", "cs", "da", "de", "el", "en", "es", "et", "fi", "fr", "hi", "hu", "id", "it",
+		"ja", "ko", "lt", "lv", "ms", "nl", "no", "pl", "pt", "ro", "ru", "sk", "sv", "th", "tr",
+		"uk", "vi", "zh",
+	},
+}
+
+// ValidateAgentConfig checks an agent configuration and returns every problem found
+func ValidateAgentConfig(config models.AgentConfig) []models.FieldError {
+	v := &validator{}
+
+	v.validateIdentity(config)
+	v.validateMeetingURL(config.MeetingURL)
+	v.validateLLM(config)
+	v.validateLLMFallbacks(config)
+	v.validateMockLLM(config.MockLLM)
+	v.validateSpeech(config)
+	v.validateTranscriptionController(config)
+	v.validateTurnTaking(config.TurnTaking)
+	v.validateAddressing(config.Addressing)
+	v.validateInterjection(config.Interjection)
+	v.validateRedaction(config.Redaction)
+	v.validateConsent(config.Consent)
+	v.validateBudget(config)
+
+	return v.errors
+}
+
+// validator accumulates field errors
+type validator struct {
+	errors []models.FieldError
+}
+
+// add records a field error
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errors = append(v.errors, models.FieldError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validateIdentity checks the agent name, mode and prompts
+func (v *validator) validateIdentity(config models.AgentConfig) {
+	if strings.TrimSpace(config.Name) == "" {
+		v.add("name", "is required")
+	} else if len(config.Name) > 100 {
+		v.add("name", "must be at most 100 characters")
+	}
+
+	switch config.ConversationMode {
+	case "", models.ConversationModeConversational, models.ConversationModeAnalyst:
+	default:
+		v.add("conversation_mode", "must be one of %q or %q", models.ConversationModeConversational, models.ConversationModeAnalyst)
+	}
+
+	if config.CustomPrompt != nil && len(*config.CustomPrompt) > 10000 {
+		v.add("custom_prompt", "must be at most 10000 characters")
+	}
+
+	if config.SystemPrompt != nil && len(*config.SystemPrompt) > 10000 {
+		v.add("system_prompt", "must be at most 10000 characters")
+	}
+
+	if config.ContextTokenBudget != 0 && (config.ContextTokenBudget < 200 || config.ContextTokenBudget > 100000) {
+		v.add("context_token_budget", "must be between 200 and 100000")
+	}
+
+	if config.Identity != "" && !memory.ValidIdentity(config.Identity) {
+		v.add("identity", "must be 1-64 letters, digits, dashes or underscores")
+	}
+
+	if len(config.MeetingSeries) > 200 {
+		v.add("meeting_series", "must be at most 200 characters")
+	}
+
+	if len(config.KnowledgeBases) > 10 {
+		v.add("knowledge_bases", "must list at most 10 knowledge bases")
+	}
+	for i, name := range config.KnowledgeBases {
+		if !knowledge.ValidName(name) {
+			v.add(fmt.Sprintf("knowledge_bases[%d]", i), "must be 1-64 letters, digits, dashes or underscores")
+		}
+	}
+
+	// The analyst rejects longer personalities at runtime, so catch it here
+	if config.PersonalityPrompt != nil && len(*config.PersonalityPrompt) > 5000 {
+		v.add("personality_prompt", "must be at most 5000 characters")
+	}
+}
+
+// validateMeetingURL checks the URL format and that joinly supports the platform
+func (v *validator) validateMeetingURL(meetingURL string) {
+	if strings.TrimSpace(meetingURL) == "" {
+		v.add("meeting_url", "is required")
+		return
+	}
+
+	parsed, err := url.Parse(meetingURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		v.add("meeting_url", "must be an absolute http(s) URL")
+		return
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, pattern := range meetingPlatforms {
+		if pattern.MatchString(host) {
+			return
+		}
+	}
+
+	v.add("meeting_url", "unsupported meeting platform %q (supported: Google Meet, Microsoft Teams, Zoom)", host)
+}
+
+// validateLLM checks the provider/model combination and that credentials exist
+func (v *validator) validateLLM(config models.AgentConfig) {
+	if config.LLMProvider == "" {
+		v.add("llm_provider", "is required")
+	} else if !isKnownLLMProvider(config.LLMProvider) {
+		v.add("llm_provider", "unsupported provider %q", config.LLMProvider)
+		return
+	}
+
+	// Model names are not checked against a list: providers release new models faster than it
+	// could be kept current, and an unknown model surfaces as a provider error on the first call
+	if strings.TrimSpace(config.LLMModel) == "" {
+		v.add("llm_model", "is required")
+	}
+
+	if keys, ok := llmAPIKeys[config.LLMProvider]; ok && !hasAnyEnv(keys) {
+		v.add("llm_provider", "missing API key for provider %q (set %s)", config.LLMProvider, strings.Join(keys, " or "))
+	}
+
+	if config.LLMProvider == models.LLMProviderOpenAICompatible {
+		v.validateOpenAICompatible("openai_compatible", config.OpenAICompatible)
+	}
+}
+
+// validateOpenAICompatible checks the endpoint of an openai_compatible provider. Without an
+// explicit endpoint the server-wide OPENAI_COMPATIBLE_BASE_URL must be set.
+func (v *validator) validateOpenAICompatible(field string, compatible *models.OpenAICompatibleConfig) {
+	if compatible == nil {
+		if os.Getenv("OPENAI_COMPATIBLE_BASE_URL") == "" {
+			v.add(field, "is required when OPENAI_COMPATIBLE_BASE_URL is not set")
+		}
+		return
+	}
+
+	parsed, err := url.Parse(compatible.BaseURL)
+	if strings.TrimSpace(compatible.BaseURL) == "" {
+		v.add(field+".base_url", "is required")
+	} else if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		v.add(field+".base_url", "must be an absolute http(s) URL")
+	}
+
+	if compatible.APIKeyEnv != "" && !hasAnyEnv([]string{compatible.APIKeyEnv}) {
+		v.add(field+".api_key_env", "environment variable %q is not set", compatible.APIKeyEnv)
+	}
+
+	for name := range compatible.Headers {
+		if !headerName.MatchString(name) {
+			v.add(field+".headers", "invalid header name %q", name)
+		}
+	}
+}
+
+// validateLLMFallbacks checks each provider/model pair of the fallback chain
+func (v *validator) validateLLMFallbacks(config models.AgentConfig) {
+	if len(config.LLMFallbacks) > 5 {
+		v.add("llm_fallbacks", "must list at most 5 fallbacks")
+	}
+
+	for i, target := range config.LLMFallbacks {
+		field := fmt.Sprintf("llm_fallbacks[%d]", i)
+
+		if !isKnownLLMProvider(target.Provider) {
+			v.add(field+".provider", "unsupported provider %q", target.Provider)
+			continue
+		}
+		if target.Provider == models.LLMProviderOpenAICompatible {
+			v.validateOpenAICompatible(field+".openai_compatible", target.OpenAICompatible)
+		}
+		if strings.TrimSpace(target.Model) == "" {
+			v.add(field+".model", "is required")
+		}
+		if keys, ok := llmAPIKeys[target.Provider]; ok && !hasAnyEnv(keys) {
+			v.add(field+".provider", "missing API key for provider %q (set %s)", target.Provider, strings.Join(keys, " or "))
+		}
+	}
+}
+
+// validateSpeech checks the TTS/STT providers and the transcription language
+func (v *validator) validateSpeech(config models.AgentConfig) {
+	switch config.TTSProvider {
+	case "", models.TTSProviderKokoro, models.TTSProviderElevenLabs, models.TTSProviderDeepgram:
+	default:
+		v.add("tts_provider", "unsupported provider %q", config.TTSProvider)
+	}
+
+	switch config.STTProvider {
+	case "", models.STTProviderWhisper, models.STTProviderDeepgram:
+	default:
+		v.add("stt_provider", "unsupported provider %q", config.STTProvider)
+		return
+	}
+
+	if config.Language == "" {
+		return
+	}
+
+	// Joinly defaults to whisper when no STT provider is given
+	stt := config.STTProvider
+	if stt == "" {
+		stt = models.STTProviderWhisper
+	}
+
+	language := strings.ToLower(config.Language)
+	for _, supported := range sttLanguages[stt] {
+		if supported == language {
+			return
+		}
+	}
+	v.add("language", "language %q is not supported by STT provider %q", config.Language, stt)
+}
+
+// validateTranscriptionController checks the numeric transcription controller parameters
+func (v *validator) validateTranscriptionController(config models.AgentConfig) {
+	if config.UtteranceTailSeconds != nil && (*config.UtteranceTailSeconds <= 0 || *config.UtteranceTailSeconds > 10) {
+		v.add("utterance_tail_seconds", "must be greater than 0 and at most 10")
+	}
+	if config.NoSpeechEventDelay != nil && (*config.NoSpeechEventDelay < 0 || *config.NoSpeechEventDelay > 10) {
+		v.add("no_speech_event_delay", "must be between 0 and 10")
+	}
+	if config.MaxSTTTasks != nil && (*config.MaxSTTTasks < 1 || *config.MaxSTTTasks > 20) {
+		v.add("max_stt_tasks", "must be between 1 and 20")
+	}
+	if config.WindowQueueSize != nil && (*config.WindowQueueSize < 1 || *config.WindowQueueSize > 1000) {
+		v.add("window_queue_size", "must be between 1 and 1000")
+	}
+}
+
+// validateTurnTaking checks the end-of-turn delays and the barge-in threshold
+func (v *validator) validateTurnTaking(config *models.TurnTakingConfig) {
+	if config == nil {
+		return
+	}
+
+	delays := []struct {
+		field string
+		value int
+	}{
+		{"turn_taking.debounce_ms", config.DebounceMs},
+		{"turn_taking.question_debounce_ms", config.QuestionDebounceMs},
+		{"turn_taking.incomplete_debounce_ms", config.IncompleteDebounceMs},
+	}
+	for _, delay := range delays {
+		if delay.value != 0 && (delay.value < 200 || delay.value > 10000) {
+			v.add(delay.field, "must be between 200 and 10000")
+		}
+	}
+
+	if config.BargeInMinWords < 0 || config.BargeInMinWords > 20 {
+		v.add("turn_taking.barge_in_min_words", "must be between 0 and 20")
+	}
+}
+
+// validateMockLLM checks that the scripted responses of the mock provider have valid patterns
+func (v *validator) validateMockLLM(config *models.MockLLMConfig) {
+	if config == nil {
+		return
+	}
+
+	if len(config.Responses) > 100 {
+		v.add("mock_llm.responses", "must list at most 100 responses")
+	}
+	for i, response := range config.Responses {
+		field := fmt.Sprintf("mock_llm.responses[%d]", i)
+		if _, err := regexp.Compile(response.Pattern); err != nil {
+			v.add(field+".pattern", "invalid regular expression: %v", err)
+		}
+		if len(response.Schema) > 100 {
+			v.add(field+".schema", "must be at most 100 characters")
+		}
+		if response.Response == "" {
+			v.add(field+".response", "is required")
+		}
+	}
+}
+
+// validateAddressing checks the aliases, the follow-up window and the check model
+func (v *validator) validateAddressing(config *models.AddressingConfig) {
+	if config == nil {
+		return
+	}
+
+	if len(config.Aliases) > 10 {
+		v.add("addressing.aliases", "must list at most 10 aliases")
+	}
+	for i, alias := range config.Aliases {
+		if strings.TrimSpace(alias) == "" || len(alias) > 50 {
+			v.add(fmt.Sprintf("addressing.aliases[%d]", i), "must be 1-50 characters")
+		}
+	}
+
+	if config.FollowUpSeconds < -1 || config.FollowUpSeconds > 600 {
+		v.add("addressing.follow_up_seconds", "must be between -1 and 600")
+	}
+
+	if len(config.LLMCheckModel) > 100 {
+		v.add("addressing.llm_check_model", "must be at most 100 characters")
+	}
+}
+
+// validateInterjection checks the rules, cooldown and rate limit of the interjection policy
+func (v *validator) validateInterjection(config *models.InterjectionConfig) {
+	if config == nil {
+		return
+	}
+
+	seen := make(map[models.InterjectionRule]bool)
+	for i, rule := range config.Rules {
+		if !interjection.ValidRule(rule) {
+			v.add(fmt.Sprintf("interjection.rules[%d]", i), "must be one of %q, %q or %q",
+				models.InterjectionDirectQuestion, models.InterjectionFactualCorrection, models.InterjectionActionItem)
+		} else if seen[rule] {
+			v.add(fmt.Sprintf("interjection.rules[%d]", i), "duplicate rule %q", rule)
+		}
+		seen[rule] = true
+	}
+
+	if config.CooldownSeconds < 0 || config.CooldownSeconds > 3600 {
+		v.add("interjection.cooldown_seconds", "must be between 0 and 3600")
+	}
+	if config.MaxPer10Minutes < 0 || config.MaxPer10Minutes > 60 {
+		v.add("interjection.max_per_10_minutes", "must be between 0 and 60")
+	}
+	if len(config.CheckModel) > 100 {
+		v.add("interjection.check_model", "must be at most 100 characters")
+	}
+}
+
+// validateRedaction checks the detectors and custom terms of the redaction policy
+func (v *validator) validateRedaction(config *models.RedactionConfig) {
+	if config == nil {
+		return
+	}
+
+	seen := make(map[models.PIIDetector]bool)
+	for i, detector := range config.Detectors {
+		if !redact.ValidDetector(detector) {
+			v.add(fmt.Sprintf("redaction.detectors[%d]", i), "must be one of %q, %q, %q, %q, %q or %q",
+				models.PIIEmail, models.PIIPhone, models.PIICard, models.PIIIBAN, models.PIIIP, models.PIINames)
+		} else if seen[detector] {
+			v.add(fmt.Sprintf("redaction.detectors[%d]", i), "duplicate detector %q", detector)
+		}
+		seen[detector] = true
+	}
+
+	if len(config.Terms) > 200 {
+		v.add("redaction.terms", "must have at most 200 entries")
+	}
+	for i, term := range config.Terms {
+		if strings.TrimSpace(term) == "" {
+			v.add(fmt.Sprintf("redaction.terms[%d]", i), "must not be empty")
+		} else if len(term) > 100 {
+			v.add(fmt.Sprintf("redaction.terms[%d]", i), "must be at most 100 characters")
+		}
+	}
+}
+
+// validateConsent checks the disclosure and the objection settings of the consent workflow
+func (v *validator) validateConsent(config *models.ConsentConfig) {
+	if config == nil {
+		return
+	}
+
+	if len(config.Message) > 1000 {
+		v.add("consent.message", "must be at most 1000 characters")
+	}
+	switch config.Delivery {
+	case "", models.ConsentDeliverySpeak, models.ConsentDeliveryChat, models.ConsentDeliveryBoth:
+	default:
+		v.add("consent.delivery", "must be one of %q, %q or %q",
+			models.ConsentDeliverySpeak, models.ConsentDeliveryChat, models.ConsentDeliveryBoth)
+	}
+	if config.ObjectionWindowSeconds < 0 || config.ObjectionWindowSeconds > 600 {
+		v.add("consent.objection_window_seconds", "must be between 0 and 600")
+	}
+
+	if len(config.ObjectionPhrases) > 20 {
+		v.add("consent.objection_phrases", "must have at most 20 entries")
+	}
+	for i, phrase := range config.ObjectionPhrases {
+		if strings.TrimSpace(phrase) == "" {
+			v.add(fmt.Sprintf("consent.objection_phrases[%d]", i), "must not be empty")
+		} else if len(phrase) > 100 {
+			v.add(fmt.Sprintf("consent.objection_phrases[%d]", i), "must be at most 100 characters")
+		}
+	}
+}
+
+// validateBudget checks the tenant and the optional LLM budget
+func (v *validator) validateBudget(config models.AgentConfig) {
+	if len(config.Tenant) > 100 {
+		v.add("tenant", "must be at most 100 characters")
+	}
+
+	budget := config.Budget
+	if budget == nil {
+		return
+	}
+
+	if budget.MaxCostUSD <= 0 {
+		v.add("budget.max_cost_usd", "must be greater than 0")
+	}
+
+	switch budget.Action {
+	case models.BudgetActionSilence:
+	case models.BudgetActionDowngrade:
+		provider := config.LLMProvider
+		if budget.FallbackProvider != "" {
+			provider = budget.FallbackProvider
+		}
+
+		if !isKnownLLMProvider(provider) {
+			v.add("budget.fallback_provider", "unsupported provider %q", provider)
+		} else if strings.TrimSpace(budget.FallbackModel) == "" {
+			v.add("budget.fallback_model", "is required for the %q action", models.BudgetActionDowngrade)
+		} else if keys, ok := llmAPIKeys[provider]; ok && !hasAnyEnv(keys) {
+			v.add("budget.fallback_provider", "missing API key for provider %q (set %s)", provider, strings.Join(keys, " or "))
+		}
+	default:
+		v.add("budget.action", "must be one of %q or %q", models.BudgetActionDowngrade, models.BudgetActionSilence)
+	}
+}
+
+// isKnownLLMProvider reports whether the provider is implemented by the llm package
+func isKnownLLMProvider(provider models.LLMProvider) bool {
+	switch provider {
+	case models.LLMProviderOpenAI, models.LLMProviderAnthropic, models.LLMProviderGoogle, models.LLMProviderOllama,
+		models.LLMProviderOpenAICompatible, models.LLMProviderMock:
+		return true
+	}
+	return false
+}
+
+// hasAnyEnv reports whether any of the keys is set in the process environment, which is where
+// the providers read them from; an agent's env_vars are not consulted
+func hasAnyEnv(keys []string) bool {
+	for _, key := range keys {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+package validation
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"joinly-manager/internal/models"
+)
+
+func TestMain(m *testing.M) {
+	// Providers read their API keys from the process environment
+	os.Setenv("OPENAI_API_KEY", "sk-test")
+	os.Exit(m.Run())
+}
+
+// validConfig returns a configuration that passes validation
+func validConfig() models.AgentConfig {
+	return models.AgentConfig{
+		Name:             "Meeting Assistant",
+		MeetingURL:       "https://meet.google.com/abc-defg-hij",
+		LLMProvider:      models.LLMProviderOpenAI,
+		LLMModel:         "gpt-4o",
+		TTSProvider:      models.TTSProviderKokoro,
+		STTProvider:      models.STTProviderWhisper,
+		Language:         "en",
+		ConversationMode: models.ConversationModeConversational,
+	}
+}
+
+// fieldsOf returns the set of fields that have errors
+func fieldsOf(errors []models.FieldError) map[string]bool {
+	fields := make(map[string]bool)
+	for _, err := range errors {
+		fields[err.Field] = true
+	}
+	return fields
+}
+
+func TestValidateAgentConfig_Valid(t *testing.T) {
+	if errors := ValidateAgentConfig(validConfig()); len(errors) != 0 {
+		t.Fatalf("Expected no errors, got %+v", errors)
+	}
+}
+
+func TestValidateAgentConfig_MeetingURL(t *testing.T) {
+	cases := map[string]bool{
+		"https://meet.google.com/abc-defg-hij":          true,
+		"https://us02web.zoom.us/j/123456789":           true,
+		"https://teams.microsoft.com/l/meetup-join/abc": true,
+		"":                                   false,
+		"meet.google.com/abc-defg-hij":       false,
+		"https://example.com/meeting":        false,
+		"https://meet.google.com.evil.io/ab": false,
+	}
+
+	for meetingURL, valid := range cases {
+		config := validConfig()
+		config.MeetingURL = meetingURL
+
+		hasError := fieldsOf(ValidateAgentConfig(config))["meeting_url"]
+		if hasError == valid {
+			t.Errorf("meeting_url %q: expected valid=%v", meetingURL, valid)
+		}
+	}
+}
+
+func TestValidateAgentConfig_ProviderModelAndKeys(t *testing.T) {
+	config := validConfig()
+	config.LLMProvider = "mistral"
+	if !fieldsOf(ValidateAgentConfig(config))["llm_provider"] {
+		t.Error("Expected unknown provider to be rejected")
+	}
+
+	config = validConfig()
+	config.LLMModel = " "
+	if !fieldsOf(ValidateAgentConfig(config))["llm_model"] {
+		t.Error("Expected a blank model to be rejected")
+	}
+	config.LLMModel = "codex-mini-latest"
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected a model name outside the known families to be accepted, got %+v", errors)
+	}
+
+	config = validConfig()
+	config.LLMProvider = models.LLMProviderAnthropic
+	config.LLMModel = "claude-3-5-sonnet-latest"
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if !fieldsOf(ValidateAgentConfig(config))["llm_provider"] {
+		t.Error("Expected missing Anthropic key to be reported")
+	}
+	config.EnvVars = map[string]string{"ANTHROPIC_API_KEY": "sk-ant-test"}
+	if !fieldsOf(ValidateAgentConfig(config))["llm_provider"] {
+		t.Error("Expected a key in env_vars not to count, the provider never reads it")
+	}
+
+	config = validConfig()
+	config.LLMProvider = models.LLMProviderOllama
+	config.LLMModel = "llama3.1:8b"
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected Ollama to accept any model without keys, got %+v", errors)
+	}
+}
+
+func TestValidateAgentConfig_LanguageAndRanges(t *testing.T) {
+	config := validConfig()
+	config.STTProvider = models.STTProviderDeepgram
+	config.Language = "haw"
+	if !fieldsOf(ValidateAgentConfig(config))["language"] {
+		t.Error("Expected Hawaiian to be rejected for Deepgram")
+	}
+
+	config = validConfig()
+	tail := 0.0
+	tasks := 0
+	queue := 5000
+	config.UtteranceTailSeconds = &tail
+	config.MaxSTTTasks = &tasks
+	config.WindowQueueSize = &queue
+
+	fields := fieldsOf(ValidateAgentConfig(config))
+	for _, field := range []string{"utterance_tail_seconds", "max_stt_tasks", "window_queue_size"} {
+		if !fields[field] {
+			t.Errorf("Expected %s to be out of range", field)
+		}
+	}
+}
+
+func TestValidateAgentConfig_ReportsAllErrors(t *testing.T) {
+	errors := ValidateAgentConfig(models.AgentConfig{})
+
+	fields := fieldsOf(errors)
+	for _, field := range []string{"name", "meeting_url", "llm_provider", "llm_model"} {
+		if !fields[field] {
+			t.Errorf("Expected error for %s, got %+v", field, errors)
+		}
+	}
+}
+
+func TestValidateAgentConfig_Budget(t *testing.T) {
+	config := validConfig()
+	config.Budget = &models.AgentBudget{MaxCostUSD: 5, Action: models.BudgetActionDowngrade, FallbackModel: "gpt-4o-mini"}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid downgrade budget, got %+v", errors)
+	}
+
+	config.Budget = &models.AgentBudget{MaxCostUSD: 0, Action: models.BudgetActionDowngrade}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	if !fields["budget.max_cost_usd"] || !fields["budget.fallback_model"] {
+		t.Errorf("Expected max cost and fallback model errors, got %v", fields)
+	}
+
+	config.Budget = &models.AgentBudget{MaxCostUSD: 1, Action: "pause"}
+	if !fieldsOf(ValidateAgentConfig(config))["budget.action"] {
+		t.Error("Expected unknown budget action to be rejected")
+	}
+}
+
+func TestValidateAgentConfig_LLMFallbacks(t *testing.T) {
+	config := validConfig()
+	config.LLMFallbacks = []models.LLMTarget{{Provider: models.LLMProviderOllama, Model: "llama3"}}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid fallback chain, got %+v", errors)
+	}
+
+	config.LLMFallbacks = []models.LLMTarget{
+		{Provider: "mistral", Model: "large"},
+		{Provider: models.LLMProviderAnthropic, Model: ""},
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	fields := fieldsOf(ValidateAgentConfig(config))
+	for _, field := range []string{"llm_fallbacks[0].provider", "llm_fallbacks[1].model", "llm_fallbacks[1].provider"} {
+		if !fields[field] {
+			t.Errorf("Expected error for %s, got %v", field, fields)
+		}
+	}
+}
+
+func TestValidateAgentConfig_OpenAICompatible(t *testing.T) {
+	config := validConfig()
+	config.LLMProvider = models.LLMProviderOpenAICompatible
+	config.LLMModel = "meta-llama/Llama-3.1-8B-Instruct"
+	config.OpenAICompatible = &models.OpenAICompatibleConfig{
+		BaseURL: "http://vllm.internal:8000/v1",
+		Headers: map[string]string{"X-Team": "voice"},
+	}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid OpenAI-compatible config, got %+v", errors)
+	}
+
+	config.OpenAICompatible = &models.OpenAICompatibleConfig{
+		BaseURL:   "vllm:8000",
+		APIKeyEnv: "VLLM_TEST_KEY_UNSET",
+		Headers:   map[string]string{"Bad Header": "x"},
+	}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	for _, field := range []string{"openai_compatible.base_url", "openai_compatible.api_key_env", "openai_compatible.headers"} {
+		if !fields[field] {
+			t.Errorf("Expected error for %s, got %v", field, fields)
+		}
+	}
+
+	t.Setenv("OPENAI_COMPATIBLE_BASE_URL", "")
+	config.OpenAICompatible = nil
+	if !fieldsOf(ValidateAgentConfig(config))["openai_compatible"] {
+		t.Error("Expected a missing endpoint to be reported")
+	}
+}
+
+func TestValidateAgentConfig_TurnTaking(t *testing.T) {
+	config := validConfig()
+	config.TurnTaking = &models.TurnTakingConfig{DebounceMs: 1500, QuestionDebounceMs: 600, BargeInMinWords: 3}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid turn taking, got %+v", errors)
+	}
+
+	config.TurnTaking = &models.TurnTakingConfig{DebounceMs: 50, IncompleteDebounceMs: 60000, BargeInMinWords: -1}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	for _, field := range []string{"turn_taking.debounce_ms", "turn_taking.incomplete_debounce_ms", "turn_taking.barge_in_min_words"} {
+		if !fields[field] {
+			t.Errorf("Expected error for %s, got %v", field, fields)
+		}
+	}
+}
+
+func TestValidateAgentConfig_Addressing(t *testing.T) {
+	config := validConfig()
+	config.Addressing = &models.AddressingConfig{Aliases: []string{"Meeting Bot"}, FollowUpSeconds: -1, LLMCheck: true}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid addressing, got %+v", errors)
+	}
+
+	config.Addressing = &models.AddressingConfig{Aliases: []string{" "}, FollowUpSeconds: 3600}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	if !fields["addressing.aliases[0]"] || !fields["addressing.follow_up_seconds"] {
+		t.Errorf("Expected alias and follow-up errors, got %v", fields)
+	}
+}
+
+func TestValidateAgentConfig_Interjection(t *testing.T) {
+	config := validConfig()
+	config.Interjection = &models.InterjectionConfig{
+		Rules:           []models.InterjectionRule{models.InterjectionDirectQuestion, models.InterjectionActionItem},
+		CooldownSeconds: 60,
+		MaxPer10Minutes: 3,
+	}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid interjection policy, got %+v", errors)
+	}
+
+	config.Interjection = &models.InterjectionConfig{
+		Rules:           []models.InterjectionRule{"small_talk", models.InterjectionDirectQuestion, models.InterjectionDirectQuestion},
+		MaxPer10Minutes: 100,
+	}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	for _, field := range []string{"interjection.rules[0]", "interjection.rules[2]", "interjection.max_per_10_minutes"} {
+		if !fields[field] {
+			t.Errorf("Expected error for %s, got %v", field, fields)
+		}
+	}
+}
+
+func TestValidateAgentConfig_Redaction(t *testing.T) {
+	config := validConfig()
+	config.Redaction = &models.RedactionConfig{
+		Detectors: []models.PIIDetector{models.PIIEmail, models.PIINames},
+		Terms:     []string{"Project Falcon"},
+	}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid redaction policy, got %+v", errors)
+	}
+
+	config.Redaction = &models.RedactionConfig{
+		Detectors: []models.PIIDetector{"ssn", models.PIIEmail, models.PIIEmail},
+		Terms:     []string{" ", strings.Repeat("x", 101)},
+	}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	for _, field := range []string{"redaction.detectors[0]", "redaction.detectors[2]", "redaction.terms[0]", "redaction.terms[1]"} {
+		if !fields[field] {
+			t.Errorf("Expected error for %s, got %v", field, fields)
+		}
+	}
+}
+
+func TestValidateAgentConfig_Consent(t *testing.T) {
+	config := validConfig()
+	config.Consent = &models.ConsentConfig{
+		Message:                "{name} is transcribing this meeting. Type 'no AI' to object.",
+		Delivery:               models.ConsentDeliveryChat,
+		ObjectionWindowSeconds: 30,
+		ObjectionPhrases:       []string{"no AI"},
+	}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid consent settings, got %+v", errors)
+	}
+
+	config.Consent = &models.ConsentConfig{
+		Delivery:               "email",
+		ObjectionWindowSeconds: -5,
+		ObjectionPhrases:       []string{"stop", ""},
+	}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	for _, field := range []string{"consent.delivery", "consent.objection_window_seconds", "consent.objection_phrases[1]"} {
+		if !fields[field] {
+			t.Errorf("Expected error for %s, got %v", field, fields)
+		}
+	}
+}
+
+func TestValidateAgentConfig_MockLLM(t *testing.T) {
+	config := validConfig()
+	config.LLMProvider = models.LLMProviderMock
+	config.LLMModel = "scripted"
+	config.MockLLM = &models.MockLLMConfig{Responses: []models.MockResponse{
+		{Pattern: `(?i)release`, Response: `{"assistant_reply": "Friday."}`},
+		{Schema: "summary", Response: `{"summary": ""}`},
+	}}
+	if errors := ValidateAgentConfig(config); len(errors) != 0 {
+		t.Errorf("Expected valid mock config without API keys, got %+v", errors)
+	}
+
+	config.MockLLM = &models.MockLLMConfig{Responses: []models.MockResponse{{Pattern: `(`}}}
+	fields := fieldsOf(ValidateAgentConfig(config))
+	if !fields["mock_llm.responses[0].pattern"] || !fields["mock_llm.responses[0].response"] {
+		t.Errorf("Expected pattern and response errors, got %v", fields)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 
+	"joinly-manager/internal/metrics"
 	"joinly-manager/internal/models"
 )
 
@@ -142,6 +143,7 @@ func (h *Hub) run() {
 					select {
 					case client.send <- message:
 					default:
+						metrics.IncWebSocketDropped("client_buffer_full")
 						close(client.send)
 						delete(h.clients, client)
 						delete(agentClients, client)
@@ -153,6 +155,7 @@ func (h *Hub) run() {
 				select {
 				case client.send <- message:
 				default:
+					metrics.IncWebSocketDropped("client_buffer_full")
 					close(client.send)
 					delete(h.clients, client)
 					delete(h.sessionClients, client)
@@ -172,6 +175,7 @@ func (h *Hub) BroadcastToAgent(agentID string, message models.WebSocketMessage)
 	select {
 	case h.broadcast <- message:
 	default:
+		metrics.IncWebSocketDropped("broadcast_full")
 		logrus.Warn("WebSocket broadcast channel full, dropping message")
 	}
 }
@@ -185,6 +189,7 @@ func (h *Hub) Broadcast(message models.WebSocketMessage) {
 		select {
 		case client.send <- message:
 		default:
+			metrics.IncWebSocketDropped("client_buffer_full")
 			close(client.send)
 			delete(h.clients, client)
 		}